@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/client"
+	"github.com/docker/sbom-cli-plugin/internal/compare"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/oci"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// Values for --use-attached.
+const (
+	useAttachedNever  = "never"
+	useAttachedPrefer = "prefer"
+	useAttachedOnly   = "only"
+)
+
+var useAttachedOptions = []string{useAttachedNever, useAttachedPrefer, useAttachedOnly}
+
+func validateUseAttached(useAttached string, compare bool) error {
+	switch useAttached {
+	case useAttachedNever:
+		if compare {
+			return fmt.Errorf("--compare requires an attached SBOM (--use-attached=%s or %s)", useAttachedPrefer, useAttachedOnly)
+		}
+		return nil
+	case useAttachedPrefer, useAttachedOnly:
+		return nil
+	}
+	return fmt.Errorf("bad --use-attached value %q (options=%v)", useAttached, useAttachedOptions)
+}
+
+// imageSBOM returns the SBOM for the image: either an SBOM attached to the image in its registry or a generated SBOM
+// (see --use-attached). When comparing, the attached SBOM is also compared with a generated SBOM.
+func imageSBOM(ctx context.Context, imageName string, dockerCli command.Cli, platform *image.Platform) (*sbom.SBOM, *compare.Result, error) {
+	if appConfig.UseAttached == useAttachedNever {
+		s, err := catalogImage(ctx, imageName, imageName, dockerCli, platform)
+		return s, nil, err
+	}
+
+	attached, found, err := attachedSBOM(ctx, imageName, dockerCli, platform)
+	switch {
+	case err != nil && appConfig.UseAttached == useAttachedOnly:
+		return nil, nil, err
+	case err != nil:
+		log.Warnf("unable to use an attached SBOM, generating the SBOM instead: %+v", err)
+	case attached == nil && appConfig.UseAttached == useAttachedOnly:
+		return nil, nil, fmt.Errorf("no SBOM is attached to the image %q", imageName)
+	case attached == nil:
+		log.Infof("no SBOM is attached to the image %q, generating the SBOM instead", imageName)
+	}
+
+	if attached == nil {
+		s, err := catalogImage(ctx, imageName, imageName, dockerCli, platform)
+		return s, nil, err
+	}
+
+	if !appConfig.Compare {
+		return attached, nil, nil
+	}
+
+	generated, err := catalogImage(ctx, imageName, imageName, dockerCli, platform)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := compare.Packages(fmt.Sprintf("%s from %s", found.Format, found.Manifest), *attached, *generated)
+	return attached, &result, nil
+}
+
+// attachedSBOM returns the first SBOM attached to the image in its registry that can be decoded (nil if there is none).
+// Attestations are used without verifying their signature (see the verify command).
+func attachedSBOM(ctx context.Context, imageName string, dockerCli command.Cli, platform *image.Platform) (*sbom.SBOM, *oci.Attached, error) {
+	digest, p, err := attachedImage(ctx, imageName, dockerCli, platform)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	auth := registryAuthenticator(dockerCli, digest.Context().Registry)
+	attached, err := oci.Discover(ctx, digest, p, auth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, a := range attached {
+		s, _, err := syft.Decode(bytes.NewReader(a.Document))
+		if err != nil {
+			log.Warnf("unable to decode the SBOM attached to %q (%s from %s): %+v", imageName, a.MediaType, a.Manifest, err)
+			continue
+		}
+		log.Infof("using the %s SBOM attached to %q (found with %s in %s)", a.Format, imageName, a.Source, a.Manifest)
+		return s, &attached[i], nil
+	}
+	return nil, nil, nil
+}
+
+// attachedImage returns the digest of the image in its registry and the platform of the image to find attached SBOMs
+// for. Images that are not available locally are resolved in the registry.
+func attachedImage(ctx context.Context, imageName string, dockerCli command.Cli, platform *image.Platform) (name.Digest, v1.Platform, error) {
+	p := v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+	if platform != nil {
+		p = v1.Platform{OS: platform.OS, Architecture: platform.Architecture, Variant: platform.Variant}
+	}
+
+	img, _, err := dockerCli.Client().ImageInspectWithRaw(ctx, imageName)
+	switch {
+	case err == nil:
+		if platform == nil {
+			p = v1.Platform{OS: img.Os, Architecture: img.Architecture, Variant: img.Variant}
+		}
+		digest, err := registryDigest(imageName, img.RepoDigests)
+		return digest, p, err
+	case !client.IsErrNotFound(err):
+		return name.Digest{}, p, daemonError(fmt.Errorf("failed to fetch the image %q: %w", imageName, err))
+	}
+
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return name.Digest{}, p, withKind(InvalidReference, fmt.Errorf("%q is not an image reference: %w", imageName, err))
+	}
+	desc, err := remote.Head(ref, remote.WithAuth(registryAuthenticator(dockerCli, ref.Context().Registry)), remote.WithContext(ctx))
+	if err != nil {
+		return name.Digest{}, p, fmt.Errorf("unable to find the image %q locally or in the registry: %w", imageName, err)
+	}
+	return ref.Context().Digest(desc.Digest.String()), p, nil
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateUseAttached(t *testing.T) {
+	assert.NoError(t, validateUseAttached(useAttachedNever, false))
+	assert.NoError(t, validateUseAttached(useAttachedPrefer, true))
+	assert.NoError(t, validateUseAttached(useAttachedOnly, false))
+	assert.Error(t, validateUseAttached(useAttachedNever, true))
+	assert.Error(t, validateUseAttached("always", false))
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/sbom-cli-plugin/internal/attest"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"golang.org/x/term"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// cosignPasswordEnv is the environment variable that provides the password for encrypted keys (as with cosign).
+const cosignPasswordEnv = "COSIGN_PASSWORD"
+
+// makeAttestationWriter creates a sbom.Writer that writes the SBOM as a signed in-toto attestation (a DSSE envelope)
+// rather than as a plain document. As with makeWriter, sbom.Writer.Close() should be called when there is no error.
+func makeAttestationWriter(formatName, output, keyPath string, extenders ...formats.Extender) (sbom.Writer, error) {
+	format, err := makeAttestationFormat(formatName, keyPath, extenders...)
+	if err != nil {
+		return nil, err
+	}
+	return sbom.NewWriter(sbom.NewWriterOption(format, output))
+}
+
+// makeAttestationFormat creates a format that encodes the SBOM with the given format and wraps the document in an
+// in-toto statement signed with the given key. The format keeps the ID of the format of the wrapped document.
+func makeAttestationFormat(formatName, keyPath string, extenders ...formats.Extender) (sbom.Format, error) {
+	if keyPath == "" {
+		return nil, fmt.Errorf("a private key (--key) is required to sign attestations")
+	}
+
+	format := syft.FormatByName(formatName)
+	if format == nil {
+		return nil, fmt.Errorf("bad output format: '%s'", formatName)
+	}
+	if _, err := attest.PredicateType(format.ID()); err != nil {
+		return nil, err
+	}
+
+	key, err := attest.LoadPrivateKey(keyPath, keyPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	format = formats.Extend(format, extenders...)
+	encoder := func(output io.Writer, s sbom.SBOM) error {
+		document := &bytes.Buffer{}
+		if err := format.Encode(document, s); err != nil {
+			return err
+		}
+
+		statement, err := attest.NewStatement(s.Source, format.ID(), document.Bytes())
+		if err != nil {
+			return fmt.Errorf("unable to create attestation: %w", err)
+		}
+
+		envelope, err := attest.Sign(*statement, key)
+		if err != nil {
+			return err
+		}
+		return envelope.Write(output)
+	}
+
+	return sbom.NewFormat(format.ID(), encoder, nil, nil), nil
+}
+
+// keyPassword returns the password for an encrypted private key from the environment, or prompts for it when there
+// is a terminal.
+func keyPassword() ([]byte, error) {
+	if pw, ok := os.LookupEnv(cosignPasswordEnv); ok {
+		return []byte(pw), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("the private key is encrypted: provide the password with %s", cosignPasswordEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Enter password for private key: ")
+	pw, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read password: %w", err)
+	}
+	return pw, nil
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/sbom-cli-plugin/internal/baseimage"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// the --base value to detect the base image among the images in the docker daemon
+const autoBaseImage = "auto"
+
+// originProperty is the property attached to each package telling if it was inherited from the base image ("base") or
+// added by the application ("application").
+const originProperty = "docker:sbom:origin"
+
+// the ID of the base image component in SBOM documents
+const baseImageComponentID = "BaseImage"
+
+// baseImageSplit tells the packages that were inherited from the base image apart from the packages that were added
+// by the application, for the SBOM of an image.
+type baseImageSplit struct {
+	reference string // the base image, or "auto" to detect it among the images in the docker daemon
+	onlyApp   bool   // leave the packages of the base image out of the SBOM
+	base      *baseimage.Image
+	origins   map[artifact.ID]baseimage.Origin
+}
+
+// makeBaseImageSplit returns the split configured by the application config (nil when there is none).
+func makeBaseImageSplit() *baseImageSplit {
+	reference := appConfig.Base
+	if reference == "" && appConfig.OnlyApp {
+		reference = autoBaseImage
+	}
+	if reference == "" {
+		return nil
+	}
+	return &baseImageSplit{reference: reference, onlyApp: appConfig.OnlyApp}
+}
+
+// Apply finds the base image of the image of the SBOM and the origin of every package, returning the SBOM to write
+// (which is without the packages of the base image with --only-app-packages). The base image is cataloged as well,
+// since OS packages are told apart by the packages of the base image (see baseimage.Origins). When no base image is
+// detected all packages are application packages, but a --base image that the image is not based on is an error.
+func (b *baseImageSplit) Apply(ctx context.Context, s sbom.SBOM, dockerCli command.Cli, platform *image.Platform) (sbom.SBOM, error) {
+	img := s.Source.ImageMetadata
+	layers := make([]string, 0, len(img.Layers))
+	for _, l := range img.Layers {
+		layers = append(layers, l.Digest)
+	}
+
+	candidates, err := b.candidates(ctx, img.ID, dockerCli)
+	if err != nil {
+		return s, err
+	}
+
+	b.base = baseimage.Detect(img.ID, layers, candidates)
+	if b.base == nil {
+		if b.reference != autoBaseImage {
+			return s, withKind(InvalidConfig, fmt.Errorf("the image %q is not based on %q", img.UserInput, b.reference))
+		}
+		log.Warnf("unable to find the base image of %q among the images in the docker daemon (all packages are application packages)", img.UserInput)
+		b.origins = baseimage.Origins(s.Artifacts.PackageCatalog, baseimage.Image{}, nil)
+		return s, nil
+	}
+
+	log.Infof("found base image %q of %q", b.base.Name, img.UserInput)
+	baseSBOM, err := catalogImage(ctx, b.base.ID, b.base.Name, dockerCli, platform)
+	if err != nil {
+		return s, fmt.Errorf("unable to catalog the base image %q: %w", b.base.Name, err)
+	}
+	b.origins = baseimage.Origins(s.Artifacts.PackageCatalog, *b.base, baseSBOM.Artifacts.PackageCatalog)
+
+	if cfg, ok := s.Descriptor.Configuration.(descriptorConfiguration); ok {
+		cfg.Provenance.BaseImage = b.base
+		s.Descriptor.Configuration = cfg
+	}
+
+	if b.onlyApp {
+		s = baseimage.ApplicationPackages(s, b.origins)
+	}
+	return s, nil
+}
+
+// candidates returns the --base image, or all images in the docker daemon (other than the image itself).
+func (b *baseImageSplit) candidates(ctx context.Context, imageID string, dockerCli command.Cli) ([]baseimage.Image, error) {
+	if b.reference != autoBaseImage {
+		inspect, _, err := dockerCli.Client().ImageInspectWithRaw(ctx, b.reference)
+		if err != nil {
+			return nil, daemonError(fmt.Errorf("unable to inspect the base image %q: %w", b.reference, err))
+		}
+		return []baseimage.Image{{Name: b.reference, ID: inspect.ID, Layers: inspect.RootFS.Layers}}, nil
+	}
+
+	summaries, err := dockerCli.Client().ImageList(ctx, types.ImageListOptions{})
+	if err != nil {
+		return nil, daemonError(fmt.Errorf("unable to list the images in the docker daemon: %w", err))
+	}
+
+	var candidates []baseimage.Image
+	for _, summary := range summaries {
+		if summary.ID == imageID {
+			continue
+		}
+		inspect, _, err := dockerCli.Client().ImageInspectWithRaw(ctx, summary.ID)
+		if err != nil {
+			log.Warnf("unable to inspect the image %q (it is not considered as a base image): %+v", summary.ID, err)
+			continue
+		}
+		candidates = append(candidates, baseimage.Image{Name: imageName(summary), ID: summary.ID, Layers: inspect.RootFS.Layers})
+	}
+	return candidates, nil
+}
+
+// imageName returns the first tag of the image, or the image ID when the image has no tags.
+func imageName(summary types.ImageSummary) string {
+	var tags []string
+	for _, tag := range summary.RepoTags {
+		if tag != "<none>:<none>" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return summary.ID
+	}
+	sort.Strings(tags)
+	return tags[0]
+}
+
+// Extension adds the origin of every package to the SBOM document, as a package property and a column of the table
+// of packages, and adds the base image as a component containing its packages.
+func (b *baseImageSplit) Extension(s sbom.SBOM) (*formats.Extension, error) {
+	if b.origins == nil || s.Artifacts.PackageCatalog == nil {
+		return nil, nil
+	}
+
+	ext := &formats.Extension{PackageProperties: make(map[artifact.ID][]formats.Property)}
+	column := formats.Column{Header: "Origin", Values: make(map[artifact.ID]string)}
+	var basePackages []artifact.ID
+	for id, origin := range b.origins {
+		// packages of the base image are not in the SBOM with --only-app-packages
+		if s.Artifacts.PackageCatalog.Package(id) == nil {
+			continue
+		}
+		column.Values[id] = string(origin)
+		ext.PackageProperties[id] = []formats.Property{{Name: originProperty, Value: string(origin)}}
+		if origin == baseimage.BaseOrigin {
+			basePackages = append(basePackages, id)
+		}
+	}
+
+	// all packages are application packages with --only-app-packages
+	if !b.onlyApp {
+		ext.PackageColumns = []formats.Column{column}
+	}
+
+	if b.base != nil {
+		sort.Slice(basePackages, func(i, j int) bool { return basePackages[i] < basePackages[j] })
+		ext.Components = []formats.Component{
+			{
+				ID:       baseImageComponentID,
+				Type:     "container",
+				Name:     b.base.Name,
+				Version:  b.base.ID,
+				Contains: basePackages,
+			},
+		}
+	}
+	return ext, nil
+}
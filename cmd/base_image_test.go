@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/sbom-cli-plugin/internal/baseimage"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+func Test_imageName(t *testing.T) {
+	assert.Equal(t, "alpine:3.16", imageName(types.ImageSummary{ID: "sha256:e66264b9", RepoTags: []string{"alpine:latest", "alpine:3.16"}}))
+	assert.Equal(t, "sha256:e66264b9", imageName(types.ImageSummary{ID: "sha256:e66264b9", RepoTags: []string{"<none>:<none>"}}))
+}
+
+func Test_baseImageSplit_Extension(t *testing.T) {
+	musl := pkg.Package{Name: "musl", Version: "1.2.3", Type: pkg.ApkPkg}
+	musl.SetID()
+	app := pkg.Package{Name: "app", Version: "1.0.0", Type: pkg.NpmPkg}
+	app.SetID()
+	s := sbom.SBOM{Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(musl, app)}}
+
+	split := &baseImageSplit{
+		reference: autoBaseImage,
+		base:      &baseimage.Image{Name: "alpine:3.16", ID: "sha256:e66264b9"},
+		origins: map[artifact.ID]baseimage.Origin{
+			musl.ID(): baseimage.BaseOrigin,
+			app.ID():  baseimage.ApplicationOrigin,
+		},
+	}
+
+	ext, err := split.Extension(s)
+	require.NoError(t, err)
+	assert.Equal(t, &formats.Extension{
+		PackageProperties: map[artifact.ID][]formats.Property{
+			musl.ID(): {{Name: originProperty, Value: "base"}},
+			app.ID():  {{Name: originProperty, Value: "application"}},
+		},
+		PackageColumns: []formats.Column{
+			{Header: "Origin", Values: map[artifact.ID]string{musl.ID(): "base", app.ID(): "application"}},
+		},
+		Components: []formats.Component{
+			{ID: "BaseImage", Type: "container", Name: "alpine:3.16", Version: "sha256:e66264b9", Contains: []artifact.ID{musl.ID()}},
+		},
+	}, ext)
+
+	// the packages of the base image are left out with --only-app-packages
+	split.onlyApp = true
+	ext, err = split.Extension(baseimage.ApplicationPackages(s, split.origins))
+	require.NoError(t, err)
+	assert.Empty(t, ext.PackageColumns)
+	assert.Equal(t, map[artifact.ID][]formats.Property{app.ID(): {{Name: originProperty, Value: "application"}}}, ext.PackageProperties)
+	assert.Empty(t, ext.Components[0].Contains)
+
+	// nothing is added before the base image has been looked for
+	ext, err = (&baseImageSplit{reference: autoBaseImage}).Extension(s)
+	require.NoError(t, err)
+	assert.Nil(t, ext)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/sbom-cli-plugin/internal/browse"
+	"golang.org/x/term"
+
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// validateInteractive checks that the browser can be shown: it takes over the terminal, so it needs a terminal on
+// stdin and stdout and cannot be combined with options that write the SBOM elsewhere.
+func validateInteractive() error {
+	if !appConfig.Interactive {
+		return nil
+	}
+	if appConfig.Push || appConfig.Attest {
+		return fmt.Errorf("--interactive cannot be used with --push or --attest")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("--interactive requires a terminal on stdin and stdout")
+	}
+	return nil
+}
+
+// browseSBOM shows the packages of the SBOM in the full-screen browser until the user quits.
+func browseSBOM(s sbom.SBOM, title string) error {
+	return browse.RunTerminal(os.Stdin, os.Stdout, browse.New(s, title, exportSBOM))
+}
+
+// exportSBOM writes the (filtered) SBOM from the browser to a file in any of the supported formats.
+func exportSBOM(s sbom.SBOM, format, path string) (err error) {
+	writer, err := makeWriter([]string{format}, path, provenanceExtension, imageConfigExtension)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := writer.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("unable to close %s: %w", path, closeErr)
+		}
+	}()
+	return writer.Write(s)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/anchore/syft/syft/source"
+)
+
+// runContext returns the context for a run of the command, which is cancelled by the event loop on a signal and
+// after the timeout (when there is one).
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// cancellationErr describes why the run was cancelled (nil when it was not cancelled), where the stage is taken from
+// the error of the cancelled worker.
+func cancellationErr(ctx context.Context, timeout time.Duration, workerErr error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return failure(Cancelled, stageOf(workerErr), fmt.Errorf("timed out after %s (see --timeout)", timeout))
+	case errors.Is(ctx.Err(), context.Canceled):
+		return failure(Cancelled, stageOf(workerErr), errors.New("cancelled by the user"))
+	}
+	return nil
+}
+
+// contextResolver stops the catalogers from reading the image once the context is cancelled (catalogers do not take
+// a context, but all of their file access goes through the resolver).
+type contextResolver struct {
+	source.FileResolver
+	ctx context.Context
+}
+
+func (r contextResolver) FileContentsByLocation(location source.Location) (io.ReadCloser, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.FileResolver.FileContentsByLocation(location)
+}
+
+func (r contextResolver) FilesByPath(paths ...string) ([]source.Location, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.FileResolver.FilesByPath(paths...)
+}
+
+func (r contextResolver) FilesByGlob(patterns ...string) ([]source.Location, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.FileResolver.FilesByGlob(patterns...)
+}
+
+func (r contextResolver) FilesByMIMEType(types ...string) ([]source.Location, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.FileResolver.FilesByMIMEType(types...)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anchore/syft/syft/source"
+)
+
+func Test_cancellationErr(t *testing.T) {
+	ctx, cancel := runContext(0)
+	assert.NoError(t, cancellationErr(ctx, 0, nil))
+	cancel()
+	assert.EqualError(t, cancellationErr(ctx, 0, nil), "cancelled by the user")
+
+	ctx, cancel = runContext(time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	err := cancellationErr(ctx, time.Nanosecond, withKind(CatalogFailure, context.DeadlineExceeded))
+	assert.EqualError(t, err, "timed out after 1ns (see --timeout)")
+	assert.Equal(t, Cancelled, errorKind(err))
+	assert.Equal(t, CatalogStage, stageOf(err))
+}
+
+func Test_contextResolver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	resolver := contextResolver{ctx: ctx}
+
+	cancel()
+	_, err := resolver.FilesByPath("/etc/os-release")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = resolver.FilesByGlob("**/*.jar")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = resolver.FilesByMIMEType("application/x-executable")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = resolver.FileContentsByLocation(source.NewLocation("/etc/os-release"))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/docker/sbom-cli-plugin/internal/bus"
+	"github.com/docker/sbom-cli-plugin/internal/event"
+	"github.com/wagoodman/go-partybus"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/pkg/cataloger"
+	"github.com/anchore/syft/syft/source"
+)
+
+// monitoredCataloger publishes the progress of a single cataloger on the bus (syft only publishes the progress of
+// all catalogers together).
+type monitoredCataloger struct {
+	cataloger.Cataloger
+}
+
+func monitorCatalogers(catalogers []cataloger.Cataloger) []cataloger.Cataloger {
+	monitored := make([]cataloger.Cataloger, 0, len(catalogers))
+	for _, c := range catalogers {
+		monitored = append(monitored, monitoredCataloger{Cataloger: c})
+	}
+	return monitored
+}
+
+func (c monitoredCataloger) Catalog(resolver source.FileResolver) ([]pkg.Package, []artifact.Relationship, error) {
+	files, packages := &event.Counter{}, &event.Counter{}
+	defer files.SetCompleted()
+	defer packages.SetCompleted()
+
+	bus.Publish(partybus.Event{
+		Type:   event.CatalogerStarted,
+		Source: c.Name(),
+		Value:  event.CatalogerMonitor{Name: c.Name(), Files: files, Packages: packages},
+	})
+
+	discovered, relationships, err := c.Cataloger.Catalog(countingResolver{FileResolver: resolver, files: files})
+	packages.Set(int64(len(discovered)))
+	return discovered, relationships, err
+}
+
+// countingResolver counts the files read through the resolver.
+type countingResolver struct {
+	source.FileResolver
+	files *event.Counter
+}
+
+func (r countingResolver) FileContentsByLocation(location source.Location) (io.ReadCloser, error) {
+	r.files.Add(1)
+	return r.FileResolver.FileContentsByLocation(location)
+}
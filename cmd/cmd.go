@@ -13,6 +13,7 @@ import (
 	"github.com/docker/sbom-cli-plugin/internal/config"
 	"github.com/docker/sbom-cli-plugin/internal/log"
 	"github.com/docker/sbom-cli-plugin/internal/logger"
+	"github.com/docker/sbom-cli-plugin/internal/ui"
 	"github.com/docker/sbom-cli-plugin/internal/version"
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -56,7 +57,7 @@ func initAppConfig() {
 	cfg, err := config.LoadApplicationConfig(viper.GetViper())
 	if err != nil {
 		fmt.Printf("failed to load application config: \n\t%+v\n", err)
-		os.Exit(1)
+		os.Exit(invalidConfigExitCode)
 	}
 
 	appConfig = cfg
@@ -67,15 +68,18 @@ func initLogging() {
 		EnableConsole: (appConfig.Log.FileLocation == "" || appConfig.Debug) && !appConfig.Quiet,
 		EnableFile:    appConfig.Log.FileLocation != "",
 		Level:         appConfig.Log.LevelOpt,
-		Structured:    appConfig.Log.Structured,
+		// logs are written along with the progress records, which should all be JSON
+		Structured:    appConfig.Log.Structured || appConfig.Progress == ui.JSONProgress,
 		FileLocation:  appConfig.Log.FileLocation,
+		AppendFile:    appConfig.Log.FileMode == config.AppendLogFile,
+		MaxFileSize:   appConfig.Log.MaxSizeOpt,
+		MaxBackups:    appConfig.Log.MaxBackups,
+		LibraryLevels: appConfig.Log.LibraryLevelOpts,
 	}
 
 	logWrapper := logger.NewLogrusLogger(cfg)
-	syft.SetLogger(logWrapper)
-	stereoscope.SetLogger(&logger.LogrusNestedLogger{
-		Logger: logWrapper.Logger.WithField("from-lib", "stereoscope"),
-	})
+	syft.SetLogger(logWrapper.Library("syft"))
+	stereoscope.SetLogger(logWrapper.Library("stereoscope"))
 	log.Log = logWrapper
 }
 
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/sbom-cli-plugin/internal/bus"
+	"github.com/docker/sbom-cli-plugin/internal/dockerfile"
+	"github.com/docker/sbom-cli-plugin/internal/inventory"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/ui"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"github.com/wagoodman/go-partybus"
+
+	"github.com/anchore/stereoscope"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/event"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+const dockerfileHelpExample = `
+  docker sbom dockerfile ./Dockerfile                                  show the packages of the base image of every stage
+  docker sbom dockerfile ./Dockerfile --build-arg GO_VERSION=1.18      resolve build args as docker build would
+  docker sbom dockerfile ./Dockerfile --format spdx-json --output ./sboms   write an SBOM for every stage to the directory
+`
+
+type dockerfileOptions struct {
+	buildArgs []string
+	format    string
+	output    string
+	platform  string
+}
+
+// stageResult is the outcome of cataloging the base image of a build stage.
+type stageResult struct {
+	stage dockerfile.Stage
+	sbom  *sbom.SBOM // nil when the stage was skipped or failed
+	err   error
+}
+
+func dockerfileCmd(dockerCli command.Cli) *cobra.Command {
+	opts := dockerfileOptions{}
+
+	c := &cobra.Command{
+		Use:   "dockerfile [flags] DOCKERFILE",
+		Short: "Generate an SBOM for the base image of every stage of a Dockerfile",
+		Long: "Generate an SBOM for the base image of every stage of a Dockerfile before building it. The base image of every stage " +
+			"(FROM) is resolved with the build args, where stages that are based on earlier stages (or scratch) are skipped.",
+		Example:       dockerfileHelpExample,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: withExitCode(-1, func(_ *cobra.Command, args []string) error {
+			return runDockerfile(dockerCli, opts, args[0])
+		}),
+	}
+
+	flags := c.Flags()
+
+	flags.StringArrayVarP(
+		&opts.buildArgs, "build-arg", "", nil,
+		"set a build arg (KEY=VALUE, or KEY to take the value from the environment), repeatable",
+	)
+
+	flags.StringVarP(
+		&opts.format, "format", "", formatAliases(syft.TableFormatID)[0],
+		fmt.Sprintf("report output format, options=%v", formatAliases(syft.TableFormatID, syft.TextFormatID, syft.JSONFormatID, syft.SPDXTagValueFormatID, syft.SPDXJSONFormatID, syft.CycloneDxXMLFormatID, syft.CycloneDxJSONFormatID, syft.GitHubID)),
+	)
+
+	flags.StringVarP(
+		&opts.output, "output", "o", "",
+		"write an SBOM for every stage to the given directory (instead of stdout)",
+	)
+
+	flags.StringVarP(
+		&opts.platform, "platform", "", "",
+		"the platform of base images without a FROM --platform (e.g. 'linux/arm64', 'linux/arm64/v8', 'arm64', 'linux')",
+	)
+
+	return c
+}
+
+func runDockerfile(dockerCli command.Cli, opts dockerfileOptions, path string) error {
+	format := syft.FormatByName(opts.format)
+	if format == nil {
+		return withKind(InvalidConfig, fmt.Errorf("bad output format: '%s'", opts.format))
+	}
+
+	var platform *image.Platform
+	var err error
+	if opts.platform != "" {
+		platform, err = image.NewPlatform(opts.platform)
+		if err != nil {
+			return withKind(InvalidConfig, fmt.Errorf("invalid platform provided: %w", err))
+		}
+	}
+
+	buildArgs, err := parseBuildArgs(opts.buildArgs)
+	if err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	stages, err := readDockerfile(path, buildArgs)
+	if err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	if opts.output == "" {
+		if err := validateStageOutput(stages, format.ID(), opts.format); err != nil {
+			return withKind(InvalidConfig, err)
+		}
+	}
+
+	if opts.output != "" {
+		if err := os.MkdirAll(opts.output, 0755); err != nil {
+			return withKind(WriteFailure, fmt.Errorf("unable to create output directory: %w", err))
+		}
+	}
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+
+	err = eventLoop(
+		dockerfileExecWorker(ctx, stages, format, opts.output, dockerCli, platform),
+		setupSignals(),
+		cancel,
+		eventSubscription,
+		stereoscope.Cleanup,
+		ui.Select(appConfig.Progress, isVerbose(), appConfig.Quiet)...,
+	)
+	if cancelErr := cancellationErr(ctx, 0, err); cancelErr != nil {
+		return cancelErr
+	}
+	return err
+}
+
+// parseBuildArgs reads --build-arg values as docker build does: "KEY=VALUE" sets the value, while "KEY" takes the
+// value from the environment (and leaves the build arg unset when the environment does not have it).
+func parseBuildArgs(values []string) (map[string]string, error) {
+	args := make(map[string]string)
+	for _, value := range values {
+		key, v, ok := strings.Cut(value, "=")
+		if key == "" {
+			return nil, fmt.Errorf("bad build arg %q: expected KEY=VALUE", value)
+		}
+		if !ok {
+			if v, ok = os.LookupEnv(key); !ok {
+				continue
+			}
+		}
+		args[key] = v
+	}
+	return args, nil
+}
+
+func readDockerfile(path string, buildArgs map[string]string) ([]dockerfile.Stage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open Dockerfile: %w", err)
+	}
+	defer f.Close()
+
+	stages, err := dockerfile.Parse(f, buildArgs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse Dockerfile %q: %w", path, err)
+	}
+	return stages, nil
+}
+
+// validateStageOutput checks that the SBOMs of the stages can be written to stdout, where only the table and text
+// formats can be read with several SBOMs on stdout (other formats need a file per stage).
+func validateStageOutput(stages []dockerfile.Stage, format sbom.FormatID, formatName string) error {
+	if format == syft.TableFormatID || format == syft.TextFormatID {
+		return nil
+	}
+	var external int
+	for _, stage := range stages {
+		if stage.External() {
+			external++
+		}
+	}
+	if external > 1 {
+		return fmt.Errorf("%d stages are based on an image: --output DIR is required to write their SBOMs in the %s format", external, formatName)
+	}
+	return nil
+}
+
+func dockerfileExecWorker(ctx context.Context, stages []dockerfile.Stage, format sbom.Format, dir string, dockerCli command.Cli, platform *image.Platform) <-chan error {
+	errs := make(chan error)
+	go func() {
+		defer close(errs)
+
+		results, err := catalogStages(ctx, stages, dockerCli, platform)
+		if err := ctx.Err(); err != nil {
+			errs <- err
+			return
+		}
+
+		bus.Publish(partybus.Event{
+			Type: event.Exit,
+			Value: func() error {
+				if dir != "" {
+					return writeStageSBOMs(os.Stdout, dir, format, results)
+				}
+				return printStageSBOMs(stageLabelWriter(format.ID()), format, results)
+			},
+		})
+
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return errs
+}
+
+// catalogStages catalogs the base image of every stage that is based on an image. A failure to catalog one base image
+// does not prevent cataloging the remaining base images, where every base image is cataloged once (even if it is the
+// base of several stages).
+func catalogStages(ctx context.Context, stages []dockerfile.Stage, dockerCli command.Cli, defaultPlatform *image.Platform) ([]stageResult, error) {
+	cataloged := make(map[string]stageResult)
+	results := make([]stageResult, 0, len(stages))
+	var errs error
+	for _, stage := range stages {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := stageResult{stage: stage}
+		if !stage.External() {
+			log.Debugf("skipping %s: not based on an image (%s)", stage, stage.From)
+			results = append(results, result)
+			continue
+		}
+
+		// the same image may be cataloged for several platforms
+		key := stage.Platform + " " + stage.From
+		if previous, ok := cataloged[key]; ok {
+			result.sbom, result.err = previous.sbom, previous.err
+			results = append(results, result)
+			continue
+		}
+
+		log.Infof("cataloging the base image %q of %s", stage.From, stage)
+		result.sbom, result.err = catalogStage(ctx, stage, dockerCli, defaultPlatform)
+		if result.err != nil {
+			log.Errorf("unable to catalog the base image %q of %s: %+v", stage.From, stage, result.err)
+			errs = multierror.Append(errs, fmt.Errorf("unable to catalog the base image %q of %s: %w", stage.From, stage, result.err))
+		}
+		cataloged[key] = result
+		results = append(results, result)
+	}
+	return results, errs
+}
+
+// catalogStage catalogs the base image of the stage, for the platform of the FROM instruction (when it has one).
+func catalogStage(ctx context.Context, stage dockerfile.Stage, dockerCli command.Cli, platform *image.Platform) (*sbom.SBOM, error) {
+	if stage.Platform != "" {
+		p, err := image.NewPlatform(stage.Platform)
+		if err != nil {
+			return nil, withKind(InvalidConfig, fmt.Errorf("invalid platform %q: %w", stage.Platform, err))
+		}
+		platform = p
+	}
+
+	imageName, err := cleanImageReference(stage.From)
+	if err != nil {
+		return nil, withKind(InvalidReference, err)
+	}
+
+	return catalogImage(ctx, imageName, stage.From, dockerCli, platform)
+}
+
+// describeStage returns a line describing the stage and its base (e.g. "stage 1 (build): golang:1.18").
+func describeStage(r stageResult) string {
+	s := r.stage
+	switch {
+	case s.Scratch():
+		return fmt.Sprintf("%s: %s (skipped: no base image)", s, s.From)
+	case !s.External():
+		return fmt.Sprintf("%s: %s (skipped: based on stage %d)", s, s.From, s.BaseStage)
+	case r.err != nil:
+		return fmt.Sprintf("%s: %s (failed: %v)", s, s.From, r.err)
+	}
+	return fmt.Sprintf("%s: %s", s, s.From)
+}
+
+// stageLabelWriter returns where the stages are described when the SBOMs are written to stdout: between the SBOMs for
+// the table and text formats (to tell the SBOMs apart), otherwise on stderr (to keep the single document readable).
+func stageLabelWriter(format sbom.FormatID) io.Writer {
+	if format == syft.TableFormatID || format == syft.TextFormatID {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// printStageSBOMs writes the SBOM of every stage to stdout, where each stage is described on the given writer before
+// its SBOM.
+func printStageSBOMs(w io.Writer, format sbom.Format, results []stageResult) error {
+	writer, err := makeWriter([]string{string(format.ID())}, "", provenanceExtension, imageConfigExtension)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := writer.Close(); err != nil {
+			log.Warnf("unable to write to report destination: %+v", err)
+		}
+	}()
+
+	for _, r := range results {
+		if _, err := fmt.Fprintf(w, "# %s\n", describeStage(r)); err != nil {
+			return withKind(WriteFailure, err)
+		}
+		if r.sbom == nil {
+			continue
+		}
+		if err := writer.Write(*r.sbom); err != nil {
+			return withKind(WriteFailure, err)
+		}
+	}
+	return nil
+}
+
+// writeStageSBOMs writes the SBOM of every stage to a file in the directory (named by the stage), listing the files
+// written by stage.
+func writeStageSBOMs(w io.Writer, dir string, format sbom.Format, results []stageResult) error {
+	for _, r := range results {
+		line := describeStage(r)
+		if r.sbom != nil {
+			path := filepath.Join(dir, stageFileName(r.stage, format.ID()))
+			writer, err := makeWriter([]string{string(format.ID())}, path, provenanceExtension, imageConfigExtension)
+			if err != nil {
+				return err
+			}
+			if err := writer.Write(*r.sbom); err != nil {
+				_ = writer.Close()
+				return withKind(WriteFailure, err)
+			}
+			if err := writer.Close(); err != nil {
+				return withKind(WriteFailure, err)
+			}
+			line += " -> " + path
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return withKind(WriteFailure, err)
+		}
+	}
+	return nil
+}
+
+// stageFileName names the SBOM file of the stage by its index and name (e.g. "stage-1-build.json").
+func stageFileName(stage dockerfile.Stage, format sbom.FormatID) string {
+	name := fmt.Sprintf("stage-%d", stage.Index)
+	if stage.Name != "" {
+		name += "-" + stage.Name
+	}
+	return inventory.FileName(name, format)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/docker/sbom-cli-plugin/internal/dockerfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+)
+
+func Test_parseBuildArgs(t *testing.T) {
+	t.Setenv("FROM_ENV", "env-value")
+
+	args, err := parseBuildArgs([]string{"VERSION=1.0", "EMPTY=", "FROM_ENV", "NOT_IN_ENV_b7f3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"VERSION": "1.0", "EMPTY": "", "FROM_ENV": "env-value"}, args)
+
+	_, err = parseBuildArgs([]string{"=value"})
+	assert.Error(t, err)
+}
+
+func Test_describeStage(t *testing.T) {
+	tests := []struct {
+		name   string
+		result stageResult
+		want   string
+	}{
+		{
+			name:   "image",
+			result: stageResult{stage: dockerfile.Stage{Index: 0, Name: "build", From: "golang:1.18", BaseStage: -1}},
+			want:   "stage 0 (build): golang:1.18",
+		},
+		{
+			name:   "earlier stage",
+			result: stageResult{stage: dockerfile.Stage{Index: 1, From: "build", BaseStage: 0}},
+			want:   "stage 1: build (skipped: based on stage 0)",
+		},
+		{
+			name:   "scratch",
+			result: stageResult{stage: dockerfile.Stage{Index: 2, From: "scratch", BaseStage: -1}},
+			want:   "stage 2: scratch (skipped: no base image)",
+		},
+		{
+			name:   "failed",
+			result: stageResult{stage: dockerfile.Stage{Index: 3, From: "missing:1.0", BaseStage: -1}, err: errors.New("not found")},
+			want:   "stage 3: missing:1.0 (failed: not found)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, describeStage(test.result))
+		})
+	}
+}
+
+func Test_stageFileName(t *testing.T) {
+	assert.Equal(t, "stage-0-build.json", stageFileName(dockerfile.Stage{Index: 0, Name: "build"}, syft.SPDXJSONFormatID))
+	assert.Equal(t, "stage-1.txt", stageFileName(dockerfile.Stage{Index: 1}, syft.TableFormatID))
+}
+
+func Test_validateStageOutput(t *testing.T) {
+	stages := []dockerfile.Stage{
+		{Index: 0, Name: "build", From: "golang:1.18", BaseStage: -1},
+		{Index: 1, From: "build", BaseStage: 0},
+		{Index: 2, From: "alpine:3.16", BaseStage: -1},
+	}
+
+	assert.NoError(t, validateStageOutput(stages, syft.TableFormatID, "table"))
+	assert.NoError(t, validateStageOutput(stages, syft.TextFormatID, "text"))
+	assert.EqualError(t, validateStageOutput(stages, syft.SPDXJSONFormatID, "spdx-json"), "2 stages are based on an image: --output DIR is required to write their SBOMs in the spdx-json format")
+	// a single SBOM is a readable document on stdout
+	assert.NoError(t, validateStageOutput(stages[:2], syft.CycloneDxJSONFormatID, "cyclonedx-json"))
+}
+
+func Test_printStageSBOMs(t *testing.T) {
+	var stderr bytes.Buffer
+	results := []stageResult{
+		{stage: dockerfile.Stage{Index: 0, From: "build", BaseStage: 0}},
+		{stage: dockerfile.Stage{Index: 1, From: "scratch", BaseStage: -1}},
+	}
+	require.NoError(t, printStageSBOMs(&stderr, syft.FormatByID(syft.JSONFormatID), results))
+	assert.Equal(t, "# stage 0: build (skipped: based on stage 0)\n# stage 1: scratch (skipped: no base image)\n", stderr.String())
+}
+
+func Test_stageLabelWriter(t *testing.T) {
+	assert.Equal(t, os.Stdout, stageLabelWriter(syft.TableFormatID))
+	assert.Equal(t, os.Stdout, stageLabelWriter(syft.TextFormatID))
+	assert.Equal(t, os.Stderr, stageLabelWriter(syft.SPDXJSONFormatID))
+}
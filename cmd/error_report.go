@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/hashicorp/go-multierror"
+)
+
+// Values for --error-format.
+const (
+	textErrorFormat = "text"
+	jsonErrorFormat = "json"
+)
+
+var errorFormatOptions = []string{textErrorFormat, jsonErrorFormat}
+
+// the code of failures without a kind
+const unknownErrorCode = "unknown"
+
+func validateErrorFormat(format string) error {
+	for _, option := range errorFormatOptions {
+		if format == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("bad --error-format value %q (options=%v)", format, errorFormatOptions)
+}
+
+// errorReport describes a failure of the command for automation (see --error-format).
+type errorReport struct {
+	Code     string   `json:"code"`             // the kind of failure (see ErrorKind)
+	ExitCode int      `json:"exitCode"`         // the exit code of the command
+	Message  string   `json:"message"`          // the error as it would be shown without --error-format=json
+	Causes   []string `json:"causes,omitempty"` // the messages of the wrapped errors, from the outermost to the root causes
+	Image    string   `json:"image,omitempty"`  // the image reference given by the user
+	Stage    Stage    `json:"stage,omitempty"`  // the step of the command that failed
+}
+
+func newErrorReport(err error, image string) errorReport {
+	code := string(errorKind(err))
+	if code == "" {
+		code = unknownErrorCode
+	}
+
+	// worker errors are collected in a multierror, which is reported as its individual errors
+	errs := []error{err}
+	if multi, ok := err.(*multierror.Error); ok {
+		errs = multi.Errors
+	}
+
+	var messages, causes []string
+	for _, e := range errs {
+		messages = append(messages, message(e))
+		causes = append(causes, causeChain(e)...)
+	}
+
+	return errorReport{
+		Code:     code,
+		ExitCode: exitCode(err),
+		Message:  strings.Join(messages, "; "),
+		Causes:   causes,
+		Image:    image,
+		Stage:    stageOf(err),
+	}
+}
+
+// message returns the message of the error, using the status message of a status error (e.g. of a policy gate).
+func message(err error) string {
+	var statusErr cli.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status
+	}
+	return err.Error()
+}
+
+// causeChain returns the messages of the errors wrapped by the error, skipping wrappers with the same message as the
+// error they wrap (e.g. a failure kind). The errors of a wrapped multierror are all included.
+func causeChain(err error) []string {
+	var chain []string
+	last := message(err)
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		if multi, ok := cause.(*multierror.Error); ok {
+			for _, e := range multi.Errors {
+				chain = append(chain, message(e))
+				chain = append(chain, causeChain(e)...)
+			}
+			return chain
+		}
+		if _, ok := cause.(cli.StatusError); ok {
+			continue
+		}
+		if msg := cause.Error(); msg != last {
+			chain = append(chain, msg)
+			last = msg
+		}
+	}
+	return chain
+}
+
+// writeErrorReport writes the report as a single line of JSON.
+func writeErrorReport(w io.Writer, report errorReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(report)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newErrorReport(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		image string
+		want  errorReport
+	}{
+		{
+			name:  "worker error",
+			image: "alpine:latest",
+			err: multierror.Append(nil, failure(CatalogFailure, FetchStage, daemonError(
+				fmt.Errorf("failed to fetch the image %q: %w", "alpine:latest",
+					fmt.Errorf("unable to inspect image: %w", client.ErrorConnectionFailed("unix:///var/run/docker.sock")))),
+			)),
+			want: errorReport{
+				Code:     string(DaemonUnreachable),
+				ExitCode: daemonUnreachableExitCode,
+				Message:  `failed to fetch the image "alpine:latest": unable to inspect image: Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?`,
+				Causes: []string{
+					"unable to inspect image: Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+					"Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+				},
+				Image: "alpine:latest",
+				Stage: FetchStage,
+			},
+		},
+		{
+			name: "wrapped multierror",
+			err:  withKind(InvalidConfig, multierror.Append(nil, errors.New("bad output format: 'a'"), errors.New("bad output format: 'b'"))),
+			want: errorReport{
+				Code:     string(InvalidConfig),
+				ExitCode: invalidConfigExitCode,
+				Message:  "2 errors occurred:\n\t* bad output format: 'a'\n\t* bad output format: 'b'\n\n",
+				Causes:   []string{"bad output format: 'a'", "bad output format: 'b'"},
+				Stage:    ValidateStage,
+			},
+		},
+		{
+			name:  "policy violation",
+			image: "alpine:latest",
+			err:   policyViolation("2 packages violate the license policy", licensePolicyExitCode),
+			want: errorReport{
+				Code:     string(PolicyViolation),
+				ExitCode: licensePolicyExitCode,
+				Message:  "2 packages violate the license policy",
+				Image:    "alpine:latest",
+				Stage:    EvaluateStage,
+			},
+		},
+		{
+			name: "unknown",
+			err:  errors.New("something failed"),
+			want: errorReport{
+				Code:     unknownErrorCode,
+				ExitCode: generalExitCode,
+				Message:  "something failed",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, newErrorReport(test.err, test.image))
+		})
+	}
+}
+
+func Test_writeErrorReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeErrorReport(&buf, newErrorReport(withKind(InvalidReference, errors.New("unable to parse image reference")), "Not/A/Reference")))
+
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")), "the report should be a single line")
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	assert.Equal(t, map[string]interface{}{
+		"code":     "invalid-reference",
+		"exitCode": float64(invalidReferenceExitCode),
+		"message":  "unable to parse image reference",
+		"image":    "Not/A/Reference",
+		"stage":    "validate",
+	}, fields)
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/spf13/cobra"
+)
+
+// ErrorKind is the category of a failure of the command, which determines the exit code (see the README).
+type ErrorKind string
+
+const (
+	InvalidConfig       ErrorKind = "invalid-config"       // a bad flag value, config file, policy, output format, key, or attestation file
+	InvalidReference    ErrorKind = "invalid-reference"    // the image argument is not an image reference
+	ImageNotFound       ErrorKind = "image-not-found"      // the image is not in the daemon (and cannot be pulled)
+	DaemonUnreachable   ErrorKind = "daemon-unreachable"   // the docker daemon cannot be connected to
+	CatalogFailure      ErrorKind = "catalog-failure"      // the image could not be fetched, read, or cataloged
+	WriteFailure        ErrorKind = "write-failure"        // the SBOM or a report could not be written
+	PolicyViolation     ErrorKind = "policy-violation"     // the SBOM did not pass a policy or vulnerability gate
+	VerificationFailure ErrorKind = "verification-failure" // the attestation signature or subject did not verify
+	PackageNotFound     ErrorKind = "package-not-found"    // no package in the SBOM matches the package asked for
+	Cancelled           ErrorKind = "cancelled"            // the run was cancelled by a signal or timed out
+)
+
+// Stage is the step of the command that failed.
+type Stage string
+
+const (
+	ValidateStage Stage = "validate" // checking the arguments, options, and policies (before the image is touched)
+	FetchStage    Stage = "fetch"    // fetching the image (or its attached SBOM) from the daemon or registry
+	ReadStage     Stage = "read"     // reading the layers of the image
+	CatalogStage  Stage = "catalog"  // cataloging the packages within the image
+	EvaluateStage Stage = "evaluate" // checking the SBOM (against policies, vulnerability data, or an attestation) or its packages
+	WriteStage    Stage = "write"    // writing the SBOM and reports
+)
+
+// the stage of a failure when none is given (catalog failures can be in any stage from fetching the image onwards)
+var defaultStages = map[ErrorKind]Stage{
+	InvalidConfig:       ValidateStage,
+	InvalidReference:    ValidateStage,
+	ImageNotFound:       FetchStage,
+	DaemonUnreachable:   FetchStage,
+	CatalogFailure:      CatalogStage,
+	WriteFailure:        WriteStage,
+	PolicyViolation:     EvaluateStage,
+	VerificationFailure: EvaluateStage,
+	PackageNotFound:     EvaluateStage,
+}
+
+// exit codes of the command, where any failure without a kind exits with the general exit code
+const (
+	generalExitCode           = 1
+	invalidConfigExitCode     = 2
+	licensePolicyExitCode     = 3
+	packagePolicyExitCode     = 4
+	vulnerabilityExitCode     = 5
+	invalidReferenceExitCode  = 6
+	imageNotFoundExitCode     = 7
+	daemonUnreachableExitCode = 8
+	catalogFailureExitCode    = 9
+	writeFailureExitCode      = 10
+	verificationExitCode      = 11
+	packageNotFoundExitCode   = 12
+	cancelledExitCode         = 130
+)
+
+var exitCodes = map[ErrorKind]int{
+	InvalidConfig:       invalidConfigExitCode,
+	InvalidReference:    invalidReferenceExitCode,
+	ImageNotFound:       imageNotFoundExitCode,
+	DaemonUnreachable:   daemonUnreachableExitCode,
+	CatalogFailure:      catalogFailureExitCode,
+	WriteFailure:        writeFailureExitCode,
+	VerificationFailure: verificationExitCode,
+	PackageNotFound:     packageNotFoundExitCode,
+	Cancelled:           cancelledExitCode,
+}
+
+// ExitCode returns the exit code of the command for a failure of this kind. Policy violations exit with the code of
+// the gate that was not passed (see policyViolation).
+func (k ErrorKind) ExitCode() int {
+	if code, ok := exitCodes[k]; ok {
+		return code
+	}
+	return generalExitCode
+}
+
+// Error is a failure of the command of a known kind.
+type Error struct {
+	Kind  ErrorKind
+	Stage Stage // empty for the default stage of the kind
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// stage returns the step of the command that failed (empty when unknown).
+func (e *Error) stage() Stage {
+	if e.Stage != "" {
+		return e.Stage
+	}
+	return defaultStages[e.Kind]
+}
+
+// withKind returns the error as a failure of the given kind, unless the error already has a kind (the innermost kind
+// is the most specific, e.g. a write failure while creating the writer for an output format).
+func withKind(kind ErrorKind, err error) error {
+	return failure(kind, "", err)
+}
+
+// failure returns the error as a failure of the given kind in the given stage, unless the error already has a kind
+// (see withKind).
+func failure(kind ErrorKind, stage Stage, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Kind: kind, Stage: stage, Err: err}
+}
+
+// stageOf returns the stage of the first failure with a kind (empty when there is none).
+func stageOf(err error) Stage {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.stage()
+	}
+	return ""
+}
+
+// daemonError returns an error from the docker daemon as a failure of a known kind when the daemon cannot be reached or
+// the image is not found (other errors are returned as they are).
+func daemonError(err error) error {
+	// errdefs.IsNotFound does not look at errors wrapped with %w
+	var notFound errdefs.ErrNotFound
+	switch {
+	case err == nil:
+		return nil
+	case client.IsErrConnectionFailed(err):
+		return withKind(DaemonUnreachable, err)
+	case errors.As(err, &notFound) || client.IsErrNotFound(err):
+		return withKind(ImageNotFound, err)
+	}
+	return err
+}
+
+// policyViolation returns the failure for an SBOM that did not pass a gate, which exits with the given code.
+func policyViolation(status string, code int) error {
+	return &Error{
+		Kind: PolicyViolation,
+		Err:  cli.StatusError{Status: status, StatusCode: code},
+	}
+}
+
+// errorKind returns the kind of the first failure with a kind (an empty kind when there is none).
+func errorKind(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return ""
+}
+
+// exitCode returns the exit code of the command for the error.
+func exitCode(err error) int {
+	var statusErr cli.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return errorKind(err).ExitCode()
+}
+
+// exitError returns the error as a status error, which the plugin framework exits with (the framework only looks at
+// the error itself, not at wrapped errors). With --error-format=json the error is reported on stderr as JSON instead
+// of the plain status message.
+func exitError(err error, image string) error {
+	if err == nil {
+		return nil
+	}
+	if appConfig != nil && appConfig.ErrorFormat == jsonErrorFormat {
+		if reportErr := writeErrorReport(os.Stderr, newErrorReport(err, image)); reportErr == nil {
+			return cli.StatusError{StatusCode: exitCode(err)}
+		}
+	}
+	var statusErr cli.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr
+	}
+	return cli.StatusError{Status: err.Error(), StatusCode: exitCode(err)}
+}
+
+// withExitCode converts the errors of a cobra run (or argument validation) function to status errors, where imageArg
+// is the position of the image argument of the command (-1 when there is none).
+func withExitCode(imageArg int, run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		var image string
+		if imageArg >= 0 && imageArg < len(args) {
+			image = args[imageArg]
+		}
+		if err := validateErrorFormat(appConfig.ErrorFormat); err != nil {
+			return exitError(withKind(InvalidConfig, err), image)
+		}
+		return exitError(run(c, args), image)
+	}
+}
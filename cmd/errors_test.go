@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/sbom-cli-plugin/internal/attest"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "untyped",
+			err:  errors.New("something failed"),
+			want: generalExitCode,
+		},
+		{
+			name: "typed",
+			err:  withKind(InvalidReference, errors.New("unable to parse image reference")),
+			want: invalidReferenceExitCode,
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("unable to write: %w", withKind(WriteFailure, errors.New("disk full"))),
+			want: writeFailureExitCode,
+		},
+		{
+			name: "worker error",
+			err:  multierror.Append(nil, withKind(CatalogFailure, errors.New("unable to catalog packages"))),
+			want: catalogFailureExitCode,
+		},
+		{
+			name: "innermost kind",
+			err:  withKind(CatalogFailure, fmt.Errorf("failed to fetch: %w", withKind(ImageNotFound, errors.New("no such image")))),
+			want: imageNotFoundExitCode,
+		},
+		{
+			name: "daemon unreachable",
+			err:  daemonError(fmt.Errorf("failed to fetch the image: %w", client.ErrorConnectionFailed("unix:///var/run/docker.sock"))),
+			want: daemonUnreachableExitCode,
+		},
+		{
+			name: "image not found",
+			err:  daemonError(fmt.Errorf("failed to fetch the image: %w", errdefs.NotFound(errors.New("no such image")))),
+			want: imageNotFoundExitCode,
+		},
+		{
+			name: "policy violation",
+			err:  policyViolation("1 packages violate the package policy", packagePolicyExitCode),
+			want: packagePolicyExitCode,
+		},
+		{
+			name: "bad signature",
+			err:  verificationError(fmt.Errorf("unable to verify attestation: %w", attest.ErrSignature)),
+			want: verificationExitCode,
+		},
+		{
+			name: "subject mismatch",
+			err:  verificationError(fmt.Errorf("unable to verify attestation: %w", attest.ErrSubjectMismatch)),
+			want: verificationExitCode,
+		},
+		{
+			name: "bad attestation",
+			err:  verificationError(errors.New("unable to verify attestation: unable to decode statement")),
+			want: invalidConfigExitCode,
+		},
+		{
+			name: "package not found",
+			err:  withKind(PackageNotFound, errors.New("no packages matching \"log4j\" found")),
+			want: packageNotFoundExitCode,
+		},
+		{
+			name: "cancelled",
+			err:  multierror.Append(nil, withKind(Cancelled, context.Canceled)),
+			want: cancelledExitCode,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, exitCode(test.err))
+		})
+	}
+}
+
+func Test_exitError(t *testing.T) {
+	assert.NoError(t, exitError(nil, ""))
+
+	err := exitError(withKind(InvalidConfig, errors.New("bad --progress value")), "")
+	var statusErr cli.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, cli.StatusError{Status: "bad --progress value", StatusCode: invalidConfigExitCode}, statusErr)
+
+	// gates already have a status error with their own message
+	err = exitError(policyViolation("1 packages violate the license policy", licensePolicyExitCode), "")
+	assert.Equal(t, cli.StatusError{Status: "1 packages violate the license policy", StatusCode: licensePolicyExitCode}, err)
+	assert.Equal(t, PolicyViolation, errorKind(policyViolation("", packagePolicyExitCode)))
+}
+
+func Test_withKind(t *testing.T) {
+	assert.NoError(t, withKind(CatalogFailure, nil))
+	assert.NoError(t, daemonError(nil))
+
+	err := withKind(CatalogFailure, withKind(WriteFailure, errors.New("disk full")))
+	assert.Equal(t, WriteFailure, errorKind(err))
+	assert.EqualError(t, err, "disk full")
+
+	// other daemon errors do not have a kind yet
+	assert.Equal(t, ErrorKind(""), errorKind(daemonError(errors.New("bad platform"))))
+}
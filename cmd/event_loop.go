@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -13,9 +14,10 @@ import (
 
 // eventLoop listens to worker errors (from execution path), worker events (from a partybus subscription), and
 // signal interrupts. Is responsible for handling each event relative to a given UI an to coordinate eventing until
-// an eventual graceful exit.
+// an eventual graceful exit. On a signal the worker is cancelled and the loop waits for the worker to return (so that
+// it can clean up), unless there is a second signal.
 // nolint:funlen
-func eventLoop(workerErrs <-chan error, signals <-chan os.Signal, subscription *partybus.Subscription, cleanupFn func(), uxs ...ui.UI) error {
+func eventLoop(workerErrs <-chan error, signals <-chan os.Signal, cancel context.CancelFunc, subscription *partybus.Subscription, cleanupFn func(), uxs ...ui.UI) error {
 	defer cleanupFn()
 	events := subscription.Events()
 	var err error
@@ -27,6 +29,7 @@ func eventLoop(workerErrs <-chan error, signals <-chan os.Signal, subscription *
 
 	var retErr error
 	var forceTeardown bool
+	var cancelled bool
 
 	for {
 		if workerErrs == nil && events == nil {
@@ -61,16 +64,19 @@ func eventLoop(workerErrs <-chan error, signals <-chan os.Signal, subscription *
 				}
 			}
 		case <-signals:
-			// ignore further results from any event source and exit ASAP, but ensure that all cache is cleaned up.
-			// we ignore further errors since cleaning up the tmp directories will affect running catalogers that are
-			// reading/writing from/to their nested temp dirs. This is acceptable since we are bailing without result.
-
-			// TODO: potential future improvement would be to pass context into workers with a cancel function that is
-			// to the event loop. In this way we can have a more controlled shutdown even at the most nested levels
-			// of processing.
+			// ignore further events and stop the UI, but wait for the cancelled worker to return so that it does not
+			// keep reading from temp dirs while they are being cleaned up. The worker error is expected to be a
+			// cancellation error, which the caller should describe with cancellationErr().
 			events = nil
-			workerErrs = nil
 			forceTeardown = true
+			if cancelled {
+				// a second signal: exit ASAP without waiting for the worker, the cleanup still happens
+				workerErrs = nil
+				signals = nil
+				continue
+			}
+			cancelled = true
+			cancel()
 		}
 	}
 
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"syscall"
@@ -96,6 +97,7 @@ func Test_eventLoop_gracefulExit(t *testing.T) {
 			eventLoop(
 				worker(),
 				signaler(),
+				func() {},
 				subscription,
 				cleanupFn,
 				ux,
@@ -159,6 +161,7 @@ func Test_eventLoop_workerError(t *testing.T) {
 			eventLoop(
 				worker(),
 				signaler(),
+				func() {},
 				subscription,
 				cleanupFn,
 				ux,
@@ -230,6 +233,7 @@ func Test_eventLoop_unsubscribeError(t *testing.T) {
 			eventLoop(
 				worker(),
 				signaler(),
+				func() {},
 				subscription,
 				cleanupFn,
 				ux,
@@ -300,6 +304,7 @@ func Test_eventLoop_handlerError(t *testing.T) {
 			eventLoop(
 				worker(),
 				signaler(),
+				func() {},
 				subscription,
 				cleanupFn,
 				ux,
@@ -323,9 +328,18 @@ func Test_eventLoop_signalsStopExecution(t *testing.T) {
 		subscription := testBus.Subscribe()
 		t.Cleanup(testBus.Close)
 
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
 		worker := func() <-chan error {
-			// the worker will never return work and the event loop will always be waiting...
-			return make(chan error)
+			// the worker only returns once it is cancelled
+			ret := make(chan error)
+			go func() {
+				<-ctx.Done()
+				ret <- ctx.Err()
+				close(ret)
+			}()
+			return ret
 		}
 
 		signaler := func() <-chan os.Signal {
@@ -351,16 +365,76 @@ func Test_eventLoop_signalsStopExecution(t *testing.T) {
 			cleanupCalled = true
 		}
 
+		assert.ErrorIs(t,
+			eventLoop(
+				worker(),
+				signaler(),
+				cancel,
+				subscription,
+				cleanupFn,
+				ux,
+			),
+			context.Canceled,
+			"should have seen the cancellation of the worker, but did not",
+		)
+
+		assert.True(t, cleanupCalled, "cleanup function not called")
+		ux.AssertExpectations(t)
+	}
+
+	// if there is a bug, then there is a risk of the event loop never returning
+	testWithTimeout(t, 5*time.Second, test)
+}
+
+func Test_eventLoop_secondSignalStopsWaiting(t *testing.T) {
+	test := func(t *testing.T) {
+
+		testBus := partybus.NewBus()
+		subscription := testBus.Subscribe()
+		t.Cleanup(testBus.Close)
+
+		worker := func() <-chan error {
+			// the worker ignores the cancellation and the event loop would always be waiting...
+			return make(chan error)
+		}
+
+		signaler := func() <-chan os.Signal {
+			ret := make(chan os.Signal)
+			go func() {
+				ret <- syscall.SIGINT
+				ret <- syscall.SIGINT
+			}()
+			return ret
+		}
+
+		ux := &uiMock{
+			t: t,
+		}
+
+		ux.On("Setup", mock.AnythingOfType("func() error")).Return(nil)
+		ux.On("Teardown").Return(nil)
+
+		var cancelCalled, cleanupCalled bool
+		cancel := func() {
+			cancelCalled = true
+		}
+		cleanupFn := func() {
+			t.Log("cleanup called")
+			cleanupCalled = true
+		}
+
 		assert.NoError(t,
 			eventLoop(
 				worker(),
 				signaler(),
+				cancel,
 				subscription,
 				cleanupFn,
 				ux,
 			),
 		)
 
+		assert.True(t, cancelCalled, "cancel function not called")
 		assert.True(t, cleanupCalled, "cleanup function not called")
 		ux.AssertExpectations(t)
 	}
@@ -425,6 +499,7 @@ func Test_eventLoop_uiTeardownError(t *testing.T) {
 			eventLoop(
 				worker(),
 				signaler(),
+				func() {},
 				subscription,
 				cleanupFn,
 				ux,
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/sbom-cli-plugin/internal/bus"
+	"github.com/docker/sbom-cli-plugin/internal/explain"
+	"github.com/docker/sbom-cli-plugin/internal/query"
+	"github.com/docker/sbom-cli-plugin/internal/ui"
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+	"github.com/spf13/cobra"
+	"github.com/wagoodman/go-partybus"
+
+	"github.com/anchore/stereoscope"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft/event"
+)
+
+const explainHelpExample = `
+  docker sbom explain alpine:latest busybox                          show why busybox was found in the image
+  docker sbom explain myapp:1.0 log4j-core --version '<2.17.0'       only explain matching versions
+  docker sbom explain myapp:1.0 pkg:maven/org.apache.logging.log4j   select packages by package URL prefix
+`
+
+type explainOptions struct {
+	version  string
+	platform string
+}
+
+func explainCmd(dockerCli command.Cli) *cobra.Command {
+	opts := explainOptions{}
+
+	c := &cobra.Command{
+		Use:   "explain [flags] IMAGE PACKAGE",
+		Short: "Show the evidence for why a package was found in an image",
+		Long: "Show the evidence for why a package was found in an image: every location (with layer) the package was found by, " +
+			"the cataloger that found it, its metadata, how its CPEs and package URL were derived, and its relationships.",
+		Example:       explainHelpExample,
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: withExitCode(0, func(_ *cobra.Command, args []string) error {
+			return runExplain(dockerCli, opts, args[0], args[1])
+		}),
+	}
+
+	flags := c.Flags()
+
+	flags.StringVarP(
+		&opts.version, "version", "", "",
+		"only explain package versions within the given constraint (e.g. '<2.17.0')",
+	)
+
+	flags.StringVarP(
+		&opts.platform, "platform", "", "",
+		"an optional platform specifier for container image sources (e.g. 'linux/arm64', 'linux/arm64/v8', 'arm64', 'linux')",
+	)
+
+	return c
+}
+
+func runExplain(dockerCli command.Cli, opts explainOptions, userInput, pkgName string) error {
+	constraint, err := versions.ParseConstraint(opts.version)
+	if err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	var platform *image.Platform
+	if opts.platform != "" {
+		platform, err = image.NewPlatform(opts.platform)
+		if err != nil {
+			return withKind(InvalidConfig, fmt.Errorf("invalid platform provided: %w", err))
+		}
+	}
+
+	imageName, err := cleanImageReference(userInput)
+	if err != nil {
+		return withKind(InvalidReference, err)
+	}
+
+	ctx, cancel := runContext(0)
+	defer cancel()
+
+	err = eventLoop(
+		explainExecWorker(ctx, imageName, query.Matcher{Package: pkgName, Constraint: constraint}, dockerCli, platform),
+		setupSignals(),
+		cancel,
+		eventSubscription,
+		stereoscope.Cleanup,
+		ui.Select(appConfig.Progress, isVerbose(), appConfig.Quiet)...,
+	)
+	if cancelErr := cancellationErr(ctx, 0, err); cancelErr != nil {
+		return cancelErr
+	}
+	return err
+}
+
+func explainExecWorker(ctx context.Context, imageName string, m query.Matcher, dockerCli command.Cli, platform *image.Platform) <-chan error {
+	errs := make(chan error)
+	go func() {
+		defer close(errs)
+
+		s, err := catalogImage(ctx, imageName, imageName, dockerCli, platform)
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		explanations := explain.Packages(*s, m)
+		if len(explanations) == 0 {
+			errs <- withKind(PackageNotFound, fmt.Errorf("no packages matching %q found in %q", m.Package, imageName))
+			return
+		}
+
+		bus.Publish(partybus.Event{
+			Type:  event.Exit,
+			Value: func() error { return explain.Write(os.Stdout, explanations) },
+		})
+	}()
+	return errs
+}
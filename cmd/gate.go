@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/docker/sbom-cli-plugin/internal/policy"
+	"github.com/docker/sbom-cli-plugin/internal/sarif"
+	"github.com/docker/sbom-cli-plugin/internal/version"
+	"github.com/docker/sbom-cli-plugin/internal/vex"
+	"github.com/docker/sbom-cli-plugin/internal/vulnerability"
+
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// gate checks a generated SBOM (e.g. against a policy). The SBOM is always written, after which the gate reports the
+// outcome and the command fails when the SBOM did not pass the gate.
+type gate interface {
+	// Evaluate checks the given SBOM, returning an error only when the SBOM could not be checked.
+	Evaluate(sbom.SBOM) error
+	// Report writes the outcome of the evaluation (shown after the SBOM has been written).
+	Report(io.Writer) error
+	// Err returns an error when the evaluated SBOM did not pass the gate.
+	Err() error
+}
+
+// makeGates returns all gates configured by the application config.
+func makeGates() ([]gate, error) {
+	var gates []gate
+	if appConfig.LicensePolicy != "" {
+		p, err := policy.ReadLicensePolicy(appConfig.LicensePolicy)
+		if err != nil {
+			return nil, err
+		}
+		gates = append(gates, &licensePolicyGate{policy: *p})
+	}
+
+	if appConfig.PackagePolicy != "" {
+		p, err := policy.ReadPackagePolicy(appConfig.PackagePolicy)
+		if err != nil {
+			return nil, err
+		}
+		gates = append(gates, &packagePolicyGate{policy: *p, sarifOutput: appConfig.SARIFOutput})
+	} else if appConfig.SARIFOutput != "" {
+		return nil, fmt.Errorf("a SARIF report can only be written with a package policy (--package-policy)")
+	}
+
+	if appConfig.VulnDB != "" {
+		db, err := vulnerability.Open(appConfig.VulnDB)
+		if err != nil {
+			return nil, err
+		}
+		g := &vulnerabilityGate{db: *db, vexOutput: appConfig.VEXOutput}
+		if appConfig.FailOn != "" {
+			if g.failOn, err = vulnerability.ParseSeverity(appConfig.FailOn); err != nil {
+				return nil, fmt.Errorf("invalid --fail-on-severity: %w", err)
+			}
+		}
+		for _, path := range appConfig.VEX {
+			statements, err := vex.Read(path)
+			if err != nil {
+				return nil, err
+			}
+			g.statements = append(g.statements, statements...)
+		}
+		gates = append(gates, g)
+	} else {
+		switch {
+		case appConfig.FailOn != "":
+			return nil, fmt.Errorf("a vulnerability database (--vuln-db) is required to fail on vulnerabilities")
+		case len(appConfig.VEX) > 0, appConfig.VEXOutput != "":
+			return nil, fmt.Errorf("a vulnerability database (--vuln-db) is required to apply or write VEX documents")
+		}
+	}
+
+	return gates, nil
+}
+
+// extendingGate is a gate that adds the outcome of the evaluation to the SBOM documents written.
+type extendingGate interface {
+	gate
+	Extension(sbom.SBOM) (*formats.Extension, error)
+}
+
+// gateExtenders returns the extenders for all gates that add to the SBOM documents written.
+func gateExtenders(gates []gate) (extenders []formats.Extender) {
+	for _, g := range gates {
+		if e, ok := g.(extendingGate); ok {
+			extenders = append(extenders, e.Extension)
+		}
+	}
+	return extenders
+}
+
+// gatesErr returns the error for the first gate that was not passed.
+func gatesErr(gates []gate) error {
+	for _, g := range gates {
+		if err := g.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type licensePolicyGate struct {
+	policy     policy.LicensePolicy
+	violations []policy.LicenseViolation
+}
+
+func (g *licensePolicyGate) Evaluate(s sbom.SBOM) error {
+	g.violations = g.policy.Evaluate(s)
+	return nil
+}
+
+func (g *licensePolicyGate) Report(w io.Writer) error {
+	if len(g.violations) == 0 {
+		return nil
+	}
+	return policy.WriteLicenseViolations(w, g.violations)
+}
+
+func (g *licensePolicyGate) Err() error {
+	if len(g.violations) == 0 {
+		return nil
+	}
+	return policyViolation(fmt.Sprintf("%d packages violate the license policy", len(g.violations)), licensePolicyExitCode)
+}
+
+type packagePolicyGate struct {
+	policy      policy.PackagePolicy
+	sarifOutput string
+	sbom        sbom.SBOM
+	violations  []policy.PackageViolation
+}
+
+func (g *packagePolicyGate) Evaluate(s sbom.SBOM) error {
+	g.sbom = s
+	g.violations = g.policy.Evaluate(s)
+	return nil
+}
+
+func (g *packagePolicyGate) Report(w io.Writer) error {
+	if g.sarifOutput != "" {
+		if err := g.writeSARIF(); err != nil {
+			return err
+		}
+	}
+
+	if len(g.violations) == 0 {
+		return nil
+	}
+	return policy.WritePackageViolations(w, g.violations)
+}
+
+func (g *packagePolicyGate) writeSARIF() error {
+	report := g.policy.SARIF(g.violations, g.sbom, sarif.ToolComponent{
+		Name:           internal.BinaryName,
+		Version:        version.FromBuild().Version,
+		InformationURI: "https://github.com/docker/sbom-cli-plugin",
+	})
+
+	f, err := os.Create(g.sarifOutput)
+	if err != nil {
+		return fmt.Errorf("unable to create SARIF report: %w", err)
+	}
+
+	if err := report.Write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write SARIF report: %w", err)
+	}
+	return f.Close()
+}
+
+func (g *packagePolicyGate) Err() error {
+	var failures int
+	for _, v := range g.violations {
+		if v.Fails() {
+			failures++
+		}
+	}
+	if failures == 0 {
+		return nil
+	}
+	return policyViolation(fmt.Sprintf("%d packages violate the package policy", failures), packagePolicyExitCode)
+}
+
+type vulnerabilityGate struct {
+	db         vulnerability.Database
+	failOn     vulnerability.Severity // the lowest severity that fails the gate (the gate never fails when empty)
+	statements []vex.Statement        // triage decisions to apply to the matches
+	vexOutput  string                 // the file to write a VEX document for the findings to (optional)
+	sbom       sbom.SBOM
+	findings   []vex.Finding
+}
+
+func (g *vulnerabilityGate) Evaluate(s sbom.SBOM) error {
+	matches, err := g.db.Match(s)
+	if err != nil {
+		return err
+	}
+	g.sbom = s
+	g.findings = vex.Apply(g.statements, s.Source, matches)
+	return nil
+}
+
+// Extension adds the findings to the SBOM document (for formats that have a place for them), where findings for the
+// same vulnerability are combined unless they have been triaged differently.
+func (g *vulnerabilityGate) Extension(sbom.SBOM) (*formats.Extension, error) {
+	if len(g.findings) == 0 {
+		return nil, nil
+	}
+
+	type key struct {
+		id        string
+		statement *vex.Statement
+	}
+
+	ext := &formats.Extension{}
+	index := make(map[key]int)
+	for _, f := range g.findings {
+		k := key{id: f.Vulnerability.ID, statement: f.Statement}
+		i, ok := index[k]
+		if !ok {
+			v := f.Vulnerability
+			i = len(ext.Vulnerabilities)
+			index[k] = i
+			ext.Vulnerabilities = append(ext.Vulnerabilities, formats.Vulnerability{
+				ID:         v.ID,
+				Source:     vulnerability.SourceName,
+				URL:        v.URL(),
+				Aliases:    v.Aliases,
+				Summary:    v.Summary,
+				Severity:   string(v.Severity),
+				Score:      v.Score,
+				Vector:     v.Vector,
+				References: v.References,
+				Analysis:   toAnalysis(f.Statement),
+			})
+		}
+		ext.Vulnerabilities[i].Affects = append(ext.Vulnerabilities[i].Affects, formats.AffectedPackage{
+			Package: f.Package.ID(),
+			FixedIn: f.FixedIn,
+		})
+	}
+	return ext, nil
+}
+
+func toAnalysis(s *vex.Statement) *formats.Analysis {
+	if s == nil {
+		return nil
+	}
+	return &formats.Analysis{
+		Status:          string(s.Status),
+		Justification:   s.Justification,
+		ImpactStatement: s.ImpactStatement,
+		ActionStatement: s.ActionStatement,
+	}
+}
+
+// failures returns the unresolved matches with a severity at or above the configured threshold.
+func (g *vulnerabilityGate) failures() (result []vulnerability.Match) {
+	if g.failOn == "" {
+		return nil
+	}
+	for _, f := range g.findings {
+		if !f.Suppressed() && f.Vulnerability.Severity.AtLeast(g.failOn) {
+			result = append(result, f.Match)
+		}
+	}
+	return result
+}
+
+func (g *vulnerabilityGate) Report(w io.Writer) error {
+	if g.vexOutput != "" {
+		if err := g.writeVEX(); err != nil {
+			return err
+		}
+	}
+
+	failures := g.failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return vulnerability.WriteMatches(w, fmt.Sprintf("Vulnerabilities with %s severity or higher", g.failOn), failures)
+}
+
+func (g *vulnerabilityGate) writeVEX() error {
+	f, err := os.Create(g.vexOutput)
+	if err != nil {
+		return fmt.Errorf("unable to create VEX document: %w", err)
+	}
+
+	tooling := fmt.Sprintf("%s %s", internal.BinaryName, version.FromBuild().Version)
+	if err := vex.WriteOpenVEX(f, g.findings, g.sbom.Source, tooling, time.Now()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write VEX document: %w", err)
+	}
+	return f.Close()
+}
+
+func (g *vulnerabilityGate) Err() error {
+	failures := g.failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return policyViolation(fmt.Sprintf("%d vulnerabilities with %s severity or higher found", len(failures), g.failOn), vulnerabilityExitCode)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/sbom-cli-plugin/internal/policy"
+	"github.com/docker/sbom-cli-plugin/internal/vex"
+	"github.com/docker/sbom-cli-plugin/internal/vulnerability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+func TestLicensePolicyGate(t *testing.T) {
+	g := &licensePolicyGate{}
+	assert.NoError(t, g.Err())
+
+	var buf bytes.Buffer
+	require.NoError(t, g.Report(&buf))
+	assert.Empty(t, buf.String(), "there should be no report without violations")
+
+	g.violations = []policy.LicenseViolation{{Package: pkg.Package{Name: "agpl-lib"}, License: "AGPL-3.0-only"}}
+
+	var statusErr cli.StatusError
+	require.True(t, errors.As(g.Err(), &statusErr))
+	assert.Equal(t, licensePolicyExitCode, statusErr.StatusCode)
+
+	require.NoError(t, g.Report(&buf))
+	assert.Contains(t, buf.String(), "agpl-lib")
+
+	assert.Equal(t, g.Err(), gatesErr([]gate{&licensePolicyGate{}, g}))
+}
+
+func TestPackagePolicyGate(t *testing.T) {
+	warning := policy.PackageViolation{Rule: policy.PackageRule{ID: "a", Level: "warning"}, Package: pkg.Package{Name: "left-pad"}}
+	failure := policy.PackageViolation{Rule: policy.PackageRule{ID: "b", Level: "error"}, Package: pkg.Package{Name: "telnetd"}}
+
+	g := &packagePolicyGate{violations: []policy.PackageViolation{warning}}
+	assert.NoError(t, g.Err(), "warnings should not fail the gate")
+
+	g.violations = append(g.violations, failure)
+
+	var statusErr cli.StatusError
+	require.True(t, errors.As(g.Err(), &statusErr))
+	assert.Equal(t, packagePolicyExitCode, statusErr.StatusCode)
+	assert.Equal(t, "1 packages violate the package policy", statusErr.Status)
+}
+
+func TestVulnerabilityGate(t *testing.T) {
+	zlib := pkg.Package{Name: "zlib", Version: "1.2.11", Type: pkg.DebPkg}
+	zlib.SetID()
+	low := vulnerability.Match{Vulnerability: vulnerability.Vulnerability{ID: "OSV-1", Severity: vulnerability.LowSeverity}, Package: zlib}
+	critical := vulnerability.Match{Vulnerability: vulnerability.Vulnerability{ID: "OSV-2", Severity: vulnerability.CriticalSeverity}, Package: zlib, FixedIn: []string{"1.2.12"}}
+
+	g := &vulnerabilityGate{findings: []vex.Finding{{Match: low}, {Match: critical}}}
+	assert.NoError(t, g.Err(), "the gate should not fail without a severity threshold")
+
+	g.failOn = vulnerability.HighSeverity
+
+	var statusErr cli.StatusError
+	require.True(t, errors.As(g.Err(), &statusErr))
+	assert.Equal(t, vulnerabilityExitCode, statusErr.StatusCode)
+	assert.Equal(t, "1 vulnerabilities with high severity or higher found", statusErr.Status)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.Report(&buf))
+	assert.Contains(t, buf.String(), "OSV-2")
+	assert.NotContains(t, buf.String(), "OSV-1")
+
+	ext, err := g.Extension(sbom.SBOM{})
+	require.NoError(t, err)
+	require.Len(t, ext.Vulnerabilities, 2)
+	assert.Equal(t, "critical", ext.Vulnerabilities[1].Severity)
+	require.Len(t, ext.Vulnerabilities[1].Affects, 1)
+	assert.Equal(t, zlib.ID(), ext.Vulnerabilities[1].Affects[0].Package)
+	assert.Equal(t, []string{"1.2.12"}, ext.Vulnerabilities[1].Affects[0].FixedIn)
+}
+
+func TestVulnerabilityGate_VEX(t *testing.T) {
+	zlib := pkg.Package{Name: "zlib", Version: "1.2.11", Type: pkg.DebPkg}
+	zlib.SetID()
+	critical := vulnerability.Match{Vulnerability: vulnerability.Vulnerability{ID: "OSV-2", Severity: vulnerability.CriticalSeverity}, Package: zlib}
+	statement := &vex.Statement{Vulnerability: "OSV-2", Status: vex.NotAffected, Justification: "vulnerable_code_not_in_execute_path"}
+
+	g := &vulnerabilityGate{failOn: vulnerability.HighSeverity, findings: []vex.Finding{{Match: critical, Statement: statement}}}
+	assert.NoError(t, g.Err(), "vulnerabilities that are not affected should not fail the gate")
+
+	ext, err := g.Extension(sbom.SBOM{})
+	require.NoError(t, err)
+	require.Len(t, ext.Vulnerabilities, 1)
+	require.NotNil(t, ext.Vulnerabilities[0].Analysis)
+	assert.Equal(t, "not_affected", ext.Vulnerabilities[0].Analysis.Status)
+	assert.Equal(t, "vulnerable_code_not_in_execute_path", ext.Vulnerabilities[0].Analysis.Justification)
+
+	g.findings[0].Statement = &vex.Statement{Vulnerability: "OSV-2", Status: vex.Affected}
+	assert.Error(t, g.Err(), "affected vulnerabilities should fail the gate")
+}
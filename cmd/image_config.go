@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/docker/sbom-cli-plugin/internal/imageconfig"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// imageConfigExtension describes the image of the SBOM by its labels, annotations, and runtime configuration (as
+// properties of the image component). SBOMs that are not of an image are not extended.
+func imageConfigExtension(s sbom.SBOM) (*formats.Extension, error) {
+	component, err := imageconfig.Describe(s.Source, appConfig.EnvDenylist)
+	if err != nil {
+		// the SBOM is still complete without the image configuration
+		log.Warnf("unable to describe the image configuration: %+v", err)
+		return nil, nil
+	}
+	if component == nil {
+		return nil, nil
+	}
+	return &formats.Extension{Describes: component}, nil
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/sbom-cli-plugin/internal/bus"
+	"github.com/docker/sbom-cli-plugin/internal/imageconfig"
+	"github.com/docker/sbom-cli-plugin/internal/inventory"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/ui"
+	"github.com/hashicorp/go-multierror"
+	"github.com/wagoodman/go-partybus"
+
+	"github.com/anchore/stereoscope"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/event"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+const defaultInventoryDir = "sbom-inventory"
+
+type inventorySummary struct {
+	dir         string
+	inventoried int
+	skipped     int
+	failed      int
+}
+
+// validateAllLocal checks that none of the options that only apply to a single image are given with --all-local, which
+// would otherwise be ignored (e.g. a policy gate expected to fail a CI job).
+func validateAllLocal() error {
+	options := []struct {
+		flag string
+		set  bool
+	}{
+		{flag: "--attest", set: appConfig.Attest},
+		{flag: "--key", set: appConfig.Key != ""},
+		{flag: "--push", set: appConfig.Push},
+		{flag: "--use-attached", set: appConfig.UseAttached != useAttachedNever},
+		{flag: "--compare", set: appConfig.Compare},
+		{flag: "--license-policy", set: appConfig.LicensePolicy != ""},
+		{flag: "--package-policy", set: appConfig.PackagePolicy != ""},
+		{flag: "--sarif-output", set: appConfig.SARIFOutput != ""},
+		{flag: "--vuln-db", set: appConfig.VulnDB != ""},
+		{flag: "--vex", set: len(appConfig.VEX) > 0},
+		{flag: "--vex-output", set: appConfig.VEXOutput != ""},
+		{flag: "--fail-on-severity", set: appConfig.FailOn != ""},
+		{flag: "--interactive", set: appConfig.Interactive},
+		{flag: "--base", set: appConfig.Base != ""},
+		{flag: "--only-app-packages", set: appConfig.OnlyApp},
+	}
+
+	var unsupported []string
+	for _, o := range options {
+		if o.set {
+			unsupported = append(unsupported, o.flag)
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%s cannot be used with --all-local", strings.Join(unsupported, ", "))
+	}
+
+	if err := imageconfig.ValidateEnvDenylist(appConfig.EnvDenylist); err != nil {
+		return fmt.Errorf("bad --env-denylist value: %w", err)
+	}
+	return nil
+}
+
+func (r runner) runInventory(ctx context.Context, cancel context.CancelFunc, platform *image.Platform) error {
+	format := syft.FormatByName(appConfig.Format)
+	if format == nil {
+		return withKind(InvalidConfig, fmt.Errorf("bad output format: '%s'", appConfig.Format))
+	}
+
+	dir := appConfig.Output
+	if dir == "" {
+		dir = defaultInventoryDir
+	}
+
+	err := eventLoop(
+		inventoryExecWorker(ctx, dir, format, r.client, platform),
+		setupSignals(),
+		cancel,
+		eventSubscription,
+		stereoscope.Cleanup,
+		ui.Select(appConfig.Progress, isVerbose(), appConfig.Quiet)...,
+	)
+	if cancelErr := cancellationErr(ctx, appConfig.Timeout, err); cancelErr != nil {
+		return cancelErr
+	}
+	return err
+}
+
+func inventoryExecWorker(ctx context.Context, dir string, format sbom.Format, dockerCli command.Cli, platform *image.Platform) <-chan error {
+	errs := make(chan error)
+	go func() {
+		defer close(errs)
+
+		summary, err := inventoryLocalImages(ctx, dir, format, dockerCli, platform)
+		if summary != nil {
+			bus.Publish(partybus.Event{
+				Type: event.Exit,
+				Value: func() error {
+					_, err := fmt.Fprintf(os.Stdout, "Inventoried %d images (%d already inventoried, %d failed) in %s\n", summary.inventoried, summary.skipped, summary.failed, filepath.Join(summary.dir, inventory.IndexFileName))
+					return err
+				},
+			})
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return errs
+}
+
+// inventoryLocalImages writes an SBOM for every image in the local daemon that has not already been inventoried
+// (by image ID) to the given directory, keeping the inventory index up to date after each image. A failure to catalog
+// one image does not prevent cataloging the remaining images.
+func inventoryLocalImages(ctx context.Context, dir string, format sbom.Format, dockerCli command.Cli, platform *image.Platform) (*inventorySummary, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, withKind(WriteFailure, fmt.Errorf("unable to create inventory directory: %w", err))
+	}
+
+	idx, err := inventory.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := dockerCli.Client().ImageList(ctx, types.ImageListOptions{})
+	if err != nil {
+		return nil, withKind(CatalogFailure, daemonError(fmt.Errorf("unable to list local images: %w", err)))
+	}
+
+	summary := &inventorySummary{dir: dir}
+	var errs error
+	for _, img := range images {
+		if err := ctx.Err(); err != nil {
+			// the images inventoried so far are kept in the index
+			return summary, err
+		}
+
+		repoTags := filterReferences(img.RepoTags, "<none>:<none>")
+		repoDigests := filterReferences(img.RepoDigests, "<none>@<none>")
+
+		if idx.Inventoried(dir, img.ID, format.ID()) {
+			log.Debugf("skipping image %q: already inventoried", img.ID)
+			// the image may have been tagged (or pushed) since it was inventoried
+			existing := idx.Find(img.ID)
+			existing.RepoTags = repoTags
+			existing.RepoDigests = repoDigests
+			summary.skipped++
+			continue
+		}
+
+		userInput := img.ID
+		switch {
+		case len(repoTags) > 0:
+			userInput = repoTags[0]
+		case len(repoDigests) > 0:
+			userInput = repoDigests[0]
+		}
+
+		log.Infof("inventorying image %q (%s)", userInput, img.ID)
+
+		// catalog by image ID so that the SBOM describes the listed image even if a tag is moved in the meantime
+		s, err := catalogImage(ctx, img.ID, userInput, dockerCli, platform)
+		if err == nil {
+			err = writeInventorySBOM(filepath.Join(dir, inventory.FileName(img.ID, format.ID())), format, *s)
+		}
+		if err != nil {
+			log.Errorf("unable to inventory image %q: %+v", userInput, err)
+			errs = multierror.Append(errs, fmt.Errorf("unable to inventory image %q: %w", userInput, err))
+			summary.failed++
+			continue
+		}
+
+		idx.Add(inventory.Image{
+			ID:          img.ID,
+			RepoTags:    repoTags,
+			RepoDigests: repoDigests,
+			Format:      format.ID(),
+			SBOM:        inventory.FileName(img.ID, format.ID()),
+			Created:     time.Now().UTC(),
+		})
+
+		// save after each image so that an interrupted run does not need to catalog the same images again
+		if err := idx.Save(dir); err != nil {
+			return summary, err
+		}
+		summary.inventoried++
+	}
+
+	if err := idx.Save(dir); err != nil {
+		return summary, err
+	}
+
+	return summary, errs
+}
+
+// writeInventorySBOM writes the SBOM of an inventoried image, with the same extensions as any other SBOM of an image
+// (e.g. provenance and image configuration).
+func writeInventorySBOM(path string, format sbom.Format, s sbom.SBOM) error {
+	writer, err := makeWriter([]string{string(format.ID())}, path, provenanceExtension, imageConfigExtension)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Write(s); err != nil {
+		_ = writer.Close()
+		return withKind(WriteFailure, err)
+	}
+	return withKind(WriteFailure, writer.Close())
+}
+
+func filterReferences(refs []string, ignore string) (result []string) {
+	for _, ref := range refs {
+		if ref == ignore {
+			continue
+		}
+		result = append(result, ref)
+	}
+	return result
+}
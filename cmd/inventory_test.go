@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/sbom-cli-plugin/internal/config"
+	"github.com/docker/sbom-cli-plugin/internal/imageconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+)
+
+func withAppConfig(t *testing.T, cfg *config.Application) {
+	t.Helper()
+	previous := appConfig
+	appConfig = cfg
+	t.Cleanup(func() { appConfig = previous })
+}
+
+func Test_validateAllLocal(t *testing.T) {
+	withAppConfig(t, &config.Application{UseAttached: useAttachedNever, EnvDenylist: imageconfig.DefaultEnvDenylist})
+	assert.NoError(t, validateAllLocal())
+
+	appConfig.PackagePolicy = "policy.yaml"
+	appConfig.FailOn = "high"
+	assert.EqualError(t, validateAllLocal(), "--package-policy, --fail-on-severity cannot be used with --all-local")
+
+	withAppConfig(t, &config.Application{UseAttached: useAttachedPrefer, OnlyApp: true})
+	assert.EqualError(t, validateAllLocal(), "--use-attached, --only-app-packages cannot be used with --all-local")
+}
+
+func Test_writeInventorySBOM(t *testing.T) {
+	withAppConfig(t, &config.Application{EnvDenylist: imageconfig.DefaultEnvDenylist})
+
+	path := filepath.Join(t.TempDir(), "sbom.cdx.json")
+	require.NoError(t, writeInventorySBOM(path, syft.FormatByID(syft.CycloneDxJSONFormatID), testProvenanceSBOM()))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	// inventoried SBOMs have the same provenance as SBOMs of a single image
+	assert.Contains(t, string(contents), `"name": "docker-sbom"`)
+	assert.Contains(t, string(contents), imageIDProperty)
+}
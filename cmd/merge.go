@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	"github.com/docker/sbom-cli-plugin/internal"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/merge"
+	"github.com/docker/sbom-cli-plugin/internal/version"
+	"github.com/spf13/cobra"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+const mergeHelpExample = `
+  docker sbom merge app.json db.json cli.json                        a summary of packages across all documents
+  docker sbom merge app.json db.json --format spdx-json -o sbom.json write a single merged SPDX document to a file
+  docker sbom merge *.json --name my-product --format cyclonedx-json name the product the merged document describes
+`
+
+type mergeOptions struct {
+	format string
+	output string
+	name   string
+}
+
+func mergeCmd() *cobra.Command {
+	opts := mergeOptions{}
+
+	c := &cobra.Command{
+		Use:           "merge [flags] SBOM SBOM...",
+		Short:         "Merge multiple SBOM documents into a single SBOM",
+		Long:          "Merge multiple SBOM documents (syft-json, SPDX, or CycloneDX) into a single SBOM. Packages are deduplicated by package URL, and the documents each package was found in are recorded in the merged SBOM.",
+		Example:       mergeHelpExample,
+		Args:          cobra.MinimumNArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: withExitCode(-1, func(_ *cobra.Command, args []string) error {
+			return runMerge(opts, args)
+		}),
+	}
+
+	flags := c.Flags()
+
+	flags.StringVarP(
+		&opts.format, "format", "", formatAliases(syft.TableFormatID)[0],
+		fmt.Sprintf("report output format, options=%v", formatAliases(syft.FormatIDs()...)),
+	)
+
+	flags.StringVarP(
+		&opts.output, "output", "o", "",
+		"file to write the default report output to (default is STDOUT)",
+	)
+
+	flags.StringVarP(
+		&opts.name, "name", "", "merged-sbom",
+		"the name of the product that the merged SBOM describes",
+	)
+
+	return c
+}
+
+func runMerge(opts mergeOptions, paths []string) error {
+	inputs := make([]merge.Input, 0, len(paths))
+	for _, path := range paths {
+		input, err := readMergeInput(path)
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, *input)
+	}
+
+	result := merge.SBOMs(opts.name, inputs...)
+
+	s := result.SBOM
+	s.Descriptor = sbom.Descriptor{
+		Name:    internal.SyftName,
+		Version: version.FromBuild().SyftVersion,
+		Configuration: struct {
+			Merged []merge.Source `json:"merged" yaml:"merged"`
+		}{
+			Merged: result.Sources,
+		},
+	}
+
+	log.Infof("merged %d documents into %d packages", len(inputs), s.Artifacts.PackageCatalog.PackageCount())
+
+	writer, err := makeWriter([]string{opts.format}, opts.output, result.Extension)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := writer.Close(); err != nil {
+			log.Warnf("unable to write to report destination: %+v", err)
+		}
+	}()
+
+	return writer.Write(s)
+}
+
+func readMergeInput(path string) (*merge.Input, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, withKind(InvalidConfig, fmt.Errorf("unable to read SBOM %q: %w", path, err))
+	}
+
+	s, format, err := syft.Decode(bytes.NewReader(by))
+	if err != nil {
+		return nil, withKind(InvalidConfig, fmt.Errorf("unable to decode SBOM %q: %w", path, err))
+	}
+
+	return &merge.Input{
+		Path:   path,
+		Digest: fmt.Sprintf("sha256:%x", sha256.Sum256(by)),
+		Format: format.ID(),
+		SBOM:   *s,
+	}, nil
+}
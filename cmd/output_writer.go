@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/docker/sbom-cli-plugin/internal/formats"
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/anchore/syft/syft"
@@ -11,23 +12,25 @@ import (
 )
 
 // makeWriter creates a sbom.Writer for output or returns an error. this will either return a valid writer
-// or an error but neither both and if there is no error, sbom.Writer.Close() should be called
-func makeWriter(outputs []string, defaultFile string) (sbom.Writer, error) {
-	outputOptions, err := parseOptions(outputs, defaultFile)
+// or an error but neither both and if there is no error, sbom.Writer.Close() should be called. Any extenders given
+// are applied to every document written (for formats that support extension).
+func makeWriter(outputs []string, defaultFile string, extenders ...formats.Extender) (sbom.Writer, error) {
+	outputOptions, err := parseOptions(outputs, defaultFile, extenders...)
 	if err != nil {
-		return nil, err
+		return nil, withKind(InvalidConfig, err)
 	}
 
+	// the writer creates the output files
 	writer, err := sbom.NewWriter(outputOptions...)
 	if err != nil {
-		return nil, err
+		return nil, withKind(WriteFailure, err)
 	}
 
 	return writer, nil
 }
 
 // parseOptions utility to parse command-line option strings and retain the existing behavior of default format and file
-func parseOptions(outputs []string, defaultFile string) (out []sbom.WriterOption, errs error) {
+func parseOptions(outputs []string, defaultFile string, extenders ...formats.Extender) (out []sbom.WriterOption, errs error) {
 	// always should have one option -- we generally get the default of "table", but just make sure
 	if len(outputs) == 0 {
 		outputs = append(outputs, string(syft.TableFormatID))
@@ -56,7 +59,7 @@ func parseOptions(outputs []string, defaultFile string) (out []sbom.WriterOption
 			continue
 		}
 
-		out = append(out, sbom.NewWriterOption(format, file))
+		out = append(out, sbom.NewWriterOption(formats.Extend(format, extenders...), file))
 	}
 	return out, errs
 }
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/sbom-cli-plugin/internal"
+	"github.com/docker/sbom-cli-plugin/internal/baseimage"
+	"github.com/docker/sbom-cli-plugin/internal/config"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/version"
+
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+// the vendor of the plugin and the docker engine (as listed in the tools of SBOM documents)
+const dockerVendor = "Docker"
+
+// the name of the docker engine (as listed in the tools of SBOM documents)
+const dockerEngineName = "docker-engine"
+
+// Properties of SBOM documents that describe how the document was produced.
+const (
+	imageIDProperty          = "docker:sbom:provenance:image-id"
+	repoDigestProperty       = "docker:sbom:provenance:repo-digest"
+	engineAPIVersionProperty = "docker:sbom:provenance:engine-api-version"
+	timestampProperty        = "docker:sbom:provenance:timestamp"
+)
+
+// descriptorConfiguration is the configuration recorded in the descriptor of SBOMs generated from an image: the
+// application configuration along with the provenance of the SBOM.
+type descriptorConfiguration struct {
+	*config.Application
+	Provenance provenance `json:"provenance" yaml:"provenance"`
+}
+
+// provenance records how an SBOM was generated by the plugin.
+type provenance struct {
+	Plugin      provenanceTool   `json:"plugin" yaml:"plugin"`                               // the plugin that generated the SBOM
+	Engine      provenanceEngine `json:"engine" yaml:"engine"`                               // the docker engine the image was read from
+	ImageID     string           `json:"imageID" yaml:"imageID"`                             // the ID of the cataloged image (the digest of its config)
+	RepoDigests []string         `json:"repoDigests,omitempty" yaml:"repoDigests,omitempty"` // the repository digests of the image known to the engine
+	Timestamp   time.Time        `json:"timestamp" yaml:"timestamp"`                         // when cataloging the image started
+	BaseImage   *baseimage.Image `json:"baseImage,omitempty" yaml:"baseImage,omitempty"`     // the base image of the image (see --base)
+}
+
+type provenanceTool struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type provenanceEngine struct {
+	Version    string `json:"version,omitempty" yaml:"version,omitempty"`       // empty when the engine did not report its version
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"` // empty when the engine did not report its version
+}
+
+// newProvenance describes an SBOM generated from the image of the given source, starting at the given time.
+func newProvenance(src source.Metadata, engine provenanceEngine, started time.Time) provenance {
+	return provenance{
+		Plugin: provenanceTool{
+			Name:    internal.BinaryName,
+			Version: version.FromBuild().Version,
+		},
+		Engine:      engine,
+		ImageID:     src.ImageMetadata.ID,
+		RepoDigests: src.ImageMetadata.RepoDigests,
+		Timestamp:   started.UTC(),
+	}
+}
+
+// engineVersion returns the version of the docker engine, which is left empty (and not an error) when the engine
+// cannot be asked for its version.
+func engineVersion(ctx context.Context, dockerCli command.Cli) provenanceEngine {
+	v, err := dockerCli.Client().ServerVersion(ctx)
+	if err != nil {
+		log.Warnf("unable to get the docker engine version: %+v", err)
+		return provenanceEngine{}
+	}
+	return provenanceEngine{Version: v.Version, APIVersion: v.APIVersion}
+}
+
+// provenanceExtension adds the provenance of the SBOM to the document tools and properties. SBOMs that were not
+// generated by the plugin (e.g. attached SBOMs) are not extended.
+func provenanceExtension(s sbom.SBOM) (*formats.Extension, error) {
+	cfg, ok := s.Descriptor.Configuration.(descriptorConfiguration)
+	if !ok {
+		return nil, nil
+	}
+	p := cfg.Provenance
+
+	ext := &formats.Extension{
+		Tools: []formats.Tool{
+			{Vendor: dockerVendor, Name: p.Plugin.Name, Version: p.Plugin.Version},
+		},
+		Properties: []formats.Property{
+			{Name: imageIDProperty, Value: p.ImageID},
+		},
+	}
+	if p.Engine.Version != "" {
+		ext.Tools = append(ext.Tools, formats.Tool{Vendor: dockerVendor, Name: dockerEngineName, Version: p.Engine.Version})
+		ext.Properties = append(ext.Properties, formats.Property{Name: engineAPIVersionProperty, Value: p.Engine.APIVersion})
+	}
+	for _, digest := range p.RepoDigests {
+		ext.Properties = append(ext.Properties, formats.Property{Name: repoDigestProperty, Value: digest})
+	}
+	ext.Properties = append(ext.Properties, formats.Property{Name: timestampProperty, Value: p.Timestamp.Format(time.RFC3339)})
+
+	return ext, nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal/config"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+func testProvenanceSBOM() sbom.SBOM {
+	src := source.Metadata{
+		Scheme: source.ImageScheme,
+		ImageMetadata: source.ImageMetadata{
+			UserInput:   "alpine:latest",
+			ID:          "sha256:e66264b98777e12192600bf9b4d663655c98a090072e1bab49e233d7531d1294",
+			RepoDigests: []string{"alpine@sha256:686d8c9dfa6f3ccfc8230bc3178d23f84eeaf7e457f36f271ab1acc53015037c"},
+		},
+	}
+	p := newProvenance(src, provenanceEngine{Version: "20.10.12", APIVersion: "1.41"}, time.Date(2022, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60)))
+	p.Plugin.Version = "v0.6.0"
+
+	return sbom.SBOM{
+		Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog()},
+		Source:    src,
+		Descriptor: sbom.Descriptor{
+			Name:    "syft",
+			Version: "v0.46.3",
+			Configuration: descriptorConfiguration{
+				Application: &config.Application{Format: "syft-json"},
+				Provenance:  p,
+			},
+		},
+	}
+}
+
+func Test_provenanceExtension(t *testing.T) {
+	ext, err := provenanceExtension(testProvenanceSBOM())
+	require.NoError(t, err)
+	assert.Equal(t, &formats.Extension{
+		Tools: []formats.Tool{
+			{Vendor: "Docker", Name: "docker-sbom", Version: "v0.6.0"},
+			{Vendor: "Docker", Name: "docker-engine", Version: "20.10.12"},
+		},
+		Properties: []formats.Property{
+			{Name: imageIDProperty, Value: "sha256:e66264b98777e12192600bf9b4d663655c98a090072e1bab49e233d7531d1294"},
+			{Name: engineAPIVersionProperty, Value: "1.41"},
+			{Name: repoDigestProperty, Value: "alpine@sha256:686d8c9dfa6f3ccfc8230bc3178d23f84eeaf7e457f36f271ab1acc53015037c"},
+			{Name: timestampProperty, Value: "2022-06-01T10:00:00Z"},
+		},
+	}, ext)
+
+	// SBOMs that were not generated by the plugin have no provenance
+	ext, err = provenanceExtension(sbom.SBOM{Descriptor: sbom.Descriptor{Configuration: map[string]interface{}{}}})
+	require.NoError(t, err)
+	assert.Nil(t, ext)
+}
+
+func Test_descriptorConfiguration(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, syft.FormatByID(syft.JSONFormatID).Encode(buf, testProvenanceSBOM()))
+
+	var doc struct {
+		Descriptor struct {
+			Configuration map[string]interface{} `json:"configuration"`
+		} `json:"descriptor"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	// the application configuration is kept as is, next to the provenance
+	assert.Equal(t, "syft-json", doc.Descriptor.Configuration["format"])
+	assert.Equal(t, map[string]interface{}{
+		"plugin":      map[string]interface{}{"name": "docker-sbom", "version": "v0.6.0"},
+		"engine":      map[string]interface{}{"version": "20.10.12", "apiVersion": "1.41"},
+		"imageID":     "sha256:e66264b98777e12192600bf9b4d663655c98a090072e1bab49e233d7531d1294",
+		"repoDigests": []interface{}{"alpine@sha256:686d8c9dfa6f3ccfc8230bc3178d23f84eeaf7e457f36f271ab1acc53015037c"},
+		"timestamp":   "2022-06-01T10:00:00Z",
+	}, doc.Descriptor.Configuration["provenance"])
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/registry"
+	"github.com/docker/sbom-cli-plugin/internal/attest"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/oci"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// pushWriter writes the encoded SBOM document (or its attestation) to the output and pushes the same document to the
+// registry of the image, attached to the image digest.
+type pushWriter struct {
+	ctx       context.Context
+	dockerCli command.Cli
+	format    sbom.Format
+	attest    bool
+	out       io.Writer
+	closer    func() error
+}
+
+// makePushWriter creates a sbom.Writer that also pushes the document to the registry of the image, where the document
+// is signed as an attestation when a key is given. As with makeWriter, sbom.Writer.Close() should be called when there
+// is no error.
+func makePushWriter(ctx context.Context, dockerCli command.Cli, formatName, output, keyPath string, extenders ...formats.Extender) (sbom.Writer, error) {
+	w := &pushWriter{
+		ctx:       ctx,
+		dockerCli: dockerCli,
+		attest:    keyPath != "",
+		out:       os.Stdout,
+		closer:    func() error { return nil },
+	}
+
+	if w.attest {
+		format, err := makeAttestationFormat(formatName, keyPath, extenders...)
+		if err != nil {
+			return nil, err
+		}
+		w.format = format
+	} else {
+		format := syft.FormatByName(formatName)
+		if format == nil {
+			return nil, fmt.Errorf("bad output format: '%s'", formatName)
+		}
+		// check that the format can be pushed before cataloging
+		if _, err := oci.SBOMArtifact(format.ID(), nil); err != nil {
+			return nil, err
+		}
+		w.format = formats.Extend(format, extenders...)
+	}
+
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create report file: %w", err)
+		}
+		w.out = f
+		w.closer = f.Close
+	}
+	return w, nil
+}
+
+func (w *pushWriter) Write(s sbom.SBOM) error {
+	document := &bytes.Buffer{}
+	if err := w.format.Encode(document, s); err != nil {
+		return err
+	}
+
+	// the document is written before it is pushed so that it is not lost when the push fails
+	if _, err := w.out.Write(document.Bytes()); err != nil {
+		return err
+	}
+
+	artifact, err := w.artifact(document.Bytes())
+	if err != nil {
+		return err
+	}
+
+	image, err := registryDigest(s.Source.ImageMetadata.UserInput, s.Source.ImageMetadata.RepoDigests)
+	if err != nil {
+		return fmt.Errorf("unable to push the SBOM: %w", err)
+	}
+
+	log.Infof("pushing %s for %q", artifact.ArtifactType, image)
+	pushed, err := oci.Push(w.ctx, image, *artifact, registryAuthenticator(w.dockerCli, image.Context().Registry), time.Now())
+	if err != nil {
+		return err
+	}
+
+	kind := "SBOM"
+	if w.attest {
+		kind = "SBOM attestation"
+	}
+	_, err = fmt.Fprintf(os.Stderr, "Pushed %s for %s to %s\n", kind, image, pushed.Reference())
+	return err
+}
+
+func (w *pushWriter) Close() error {
+	return w.closer()
+}
+
+func (w *pushWriter) artifact(document []byte) (*oci.Artifact, error) {
+	if !w.attest {
+		return oci.SBOMArtifact(w.format.ID(), document)
+	}
+	predicateType, err := attest.PredicateType(w.format.ID())
+	if err != nil {
+		return nil, err
+	}
+	return oci.AttestationArtifact(predicateType, document), nil
+}
+
+// registryDigest returns the digest of the image in the registry of the image reference, given the repository digests
+// of the local image.
+func registryDigest(userInput string, repoDigests []string) (name.Digest, error) {
+	ref, err := name.ParseReference(userInput)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("%q is not an image reference: %w", userInput, err)
+	}
+	if d, ok := ref.(name.Digest); ok {
+		return d, nil
+	}
+
+	for _, repoDigest := range repoDigests {
+		d, err := name.NewDigest(repoDigest)
+		if err != nil {
+			log.Debugf("ignoring repository digest %q: %+v", repoDigest, err)
+			continue
+		}
+		if d.Context().Name() == ref.Context().Name() {
+			return d, nil
+		}
+	}
+	return name.Digest{}, fmt.Errorf("the image %q has no digest in %q (the image has not been pushed)", userInput, ref.Context().Name())
+}
+
+// registryAuthenticator returns the credentials for the registry from the docker CLI configuration (including
+// credential helpers), falling back to anonymous access.
+func registryAuthenticator(dockerCli command.Cli, reg name.Registry) authn.Authenticator {
+	key := reg.RegistryStr()
+	if key == name.DefaultRegistry {
+		key = registry.IndexServer
+	}
+
+	cfg, err := dockerCli.ConfigFile().GetAuthConfig(key)
+	if err != nil {
+		log.Warnf("unable to get credentials for %q: %+v", key, err)
+		return authn.Anonymous
+	}
+
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		Auth:          cfg.Auth,
+		IdentityToken: cfg.IdentityToken,
+		RegistryToken: cfg.RegistryToken,
+	})
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_registryDigest(t *testing.T) {
+	const digest = "sha256:1df7f8f6e3fd1be3bc0ba7f6e8cf7b7df0bb0c7e6b35ef42c2d2cf3e1a9e6c8f"
+
+	tests := []struct {
+		name        string
+		userInput   string
+		repoDigests []string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "docker hub image",
+			userInput:   "alpine:latest",
+			repoDigests: []string{"alpine@" + digest},
+			want:        "alpine@" + digest, // the short form of index.docker.io/library/alpine
+		},
+		{
+			name:        "digest of the repository that was cataloged",
+			userInput:   "localhost:5000/app:1.0",
+			repoDigests: []string{"example.com/app@sha256:0000000000000000000000000000000000000000000000000000000000000000", "localhost:5000/app@" + digest},
+			want:        "localhost:5000/app@" + digest,
+		},
+		{
+			name:      "digest reference",
+			userInput: "localhost:5000/app@" + digest,
+			want:      "localhost:5000/app@" + digest,
+		},
+		{
+			name:        "image not pushed",
+			userInput:   "localhost:5000/app:1.0",
+			repoDigests: []string{"example.com/app@" + digest},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registryDigest(tt.userInput, tt.repoDigests)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
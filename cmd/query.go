@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/query"
+	"github.com/docker/sbom-cli-plugin/internal/table"
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+	"github.com/spf13/cobra"
+)
+
+const (
+	queryTableFormat = "table"
+	queryJSONFormat  = "json"
+)
+
+const queryHelpExample = `
+  docker sbom query --package log4j-core --version '<2.17.0' ./sboms       find images with a vulnerable log4j-core
+  docker sbom query --package openssl --version '>=3.0.0, <3.0.7' a.json    check specific SBOM documents
+  docker sbom query --package pkg:maven/org.apache.logging.log4j ./sboms    match packages by package URL prefix
+  docker sbom query --package curl ./sboms --format json                    report matches as JSON
+`
+
+type queryOptions struct {
+	pkg     string
+	version string
+	format  string
+}
+
+func queryCmd() *cobra.Command {
+	opts := queryOptions{}
+
+	c := &cobra.Command{
+		Use:   "query [flags] --package NAME PATH...",
+		Short: "Search saved SBOM documents for a package",
+		Long: "Search saved SBOM documents (syft-json, SPDX, or CycloneDX) for a package, optionally within a version range. " +
+			"Directories are searched recursively. Versions are compared using the ordering rules for the package type (dpkg, rpm, apk, or semantic versioning).",
+		Example:       queryHelpExample,
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: withExitCode(-1, func(_ *cobra.Command, args []string) error {
+			return runQuery(opts, args)
+		}),
+	}
+
+	flags := c.Flags()
+
+	flags.StringVarP(
+		&opts.pkg, "package", "", "",
+		"the name (or package URL prefix) of the package to search for, where \"*\" matches any characters",
+	)
+
+	flags.StringVarP(
+		&opts.version, "version", "", "",
+		"only match package versions within the given constraint (e.g. '<2.17.0' or '>=2.0.0, <2.17.0 || =1.2.17')",
+	)
+
+	flags.StringVarP(
+		&opts.format, "format", "", queryTableFormat,
+		fmt.Sprintf("report output format, options=%v", []string{queryTableFormat, queryJSONFormat}),
+	)
+
+	_ = c.MarkFlagRequired("package")
+
+	return c
+}
+
+func runQuery(opts queryOptions, paths []string) error {
+	if opts.format != queryTableFormat && opts.format != queryJSONFormat {
+		return withKind(InvalidConfig, fmt.Errorf("unsupported query output format %q (options=%v)", opts.format, []string{queryTableFormat, queryJSONFormat}))
+	}
+
+	constraint, err := versions.ParseConstraint(opts.version)
+	if err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	matches, err := query.Search(paths, query.Matcher{
+		Package:    opts.pkg,
+		Constraint: constraint,
+	})
+	if err != nil {
+		// the documents to search could not be read or decoded
+		return withKind(InvalidConfig, err)
+	}
+
+	log.Infof("found %d matching packages", len(matches))
+
+	if opts.format == queryJSONFormat {
+		return writeQueryJSON(os.Stdout, matches)
+	}
+	return writeQueryTable(os.Stdout, matches)
+}
+
+func writeQueryJSON(w io.Writer, matches []query.Match) error {
+	if matches == nil {
+		matches = []query.Match{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(matches)
+}
+
+func writeQueryTable(w io.Writer, matches []query.Match) error {
+	if len(matches) == 0 {
+		_, err := fmt.Fprintln(w, "No matching packages found")
+		return err
+	}
+
+	rows := make([][]string, 0, len(matches))
+	for _, m := range matches {
+		rows = append(rows, []string{m.Image, m.Package, m.Version, string(m.Type), strings.Join(m.Locations, ", ")})
+	}
+
+	return table.Write(w, []string{"Image", "Package", "Version", "Type", "Location"}, rows)
+}
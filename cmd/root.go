@@ -5,15 +5,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/sbom-cli-plugin/internal"
 	"github.com/docker/sbom-cli-plugin/internal/bus"
+	"github.com/docker/sbom-cli-plugin/internal/config"
+	sbomEvent "github.com/docker/sbom-cli-plugin/internal/event"
+	"github.com/docker/sbom-cli-plugin/internal/imageconfig"
 	"github.com/docker/sbom-cli-plugin/internal/log"
 	"github.com/docker/sbom-cli-plugin/internal/ui"
 	"github.com/docker/sbom-cli-plugin/internal/version"
+	"github.com/docker/sbom-cli-plugin/internal/vulnerability"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -25,6 +31,7 @@ import (
 	stereoscopeDocker "github.com/anchore/stereoscope/pkg/image/docker"
 	"github.com/anchore/syft/syft"
 	"github.com/anchore/syft/syft/event"
+	"github.com/anchore/syft/syft/linux"
 	"github.com/anchore/syft/syft/pkg/cataloger"
 	"github.com/anchore/syft/syft/sbom"
 	"github.com/anchore/syft/syft/source"
@@ -36,6 +43,14 @@ const (
   docker sbom alpine:latest --format syft-json                       show all possible cataloging details
   docker sbom alpine:latest --output sbom.txt                        write report output to a file
   docker sbom alpine:latest --exclude /lib  --exclude '**/*.db'      ignore one or more paths/globs in the image
+  docker sbom alpine:latest --interactive                            browse the packages in a full-screen terminal UI
+  docker sbom --all-local --format spdx-json --output ./inventory    write an SBOM for every local image (plus an index)
+  docker sbom alpine:latest --license-policy policy.yaml             fail when package licenses do not meet a policy
+  docker sbom alpine:latest --package-policy deny.yaml               fail when denied packages are found
+  docker sbom alpine:latest --vuln-db ./osv --fail-on-severity high  fail on known vulnerabilities (from a local OSV dump)
+  docker sbom alpine:latest --format spdx-json --attest --key k.pem  write a signed in-toto attestation of the SBOM
+  docker sbom myregistry:5000/app:1.0 --format spdx-json --push      attach the SBOM to the image in the registry
+  docker sbom myapp:1.0 --use-attached prefer --compare              compare the attached SBOM with a generated SBOM
 `
 	shortDescription = "View the packaged-based Software Bill Of Materials (SBOM) for an image"
 )
@@ -46,22 +61,33 @@ func cmd(dockerCli command.Cli) *cobra.Command {
 		Short:         shortDescription,
 		Long:          shortDescription + ".\n\nEXPERIMENTAL: The flags and outputs of this command may change. Leave feedback on https://github.com/docker/sbom-cli-plugin.",
 		Example:       helpExample,
-		Args:          validateInputArgs,
+		Args:          withExitCode(0, validateInputArgs),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Version:       version.FromBuild().Version,
-		RunE:          newRunner(dockerCli).run,
+		RunE:          withExitCode(0, newRunner(dockerCli).run),
 	}
 
 	c.SetVersionTemplate(fmt.Sprintf("%s {{.Version}}, build %s\n", internal.ApplicationName, version.FromBuild().GitCommit))
 
 	setPackageFlags(c.Flags())
+	// errors and logs of all subcommands are handled the same way
+	setPersistentFlags(c.PersistentFlags())
 
 	if err := bindConfigOptions(c.Flags()); err != nil {
 		panic(fmt.Errorf("unable to bind config options: %w", err))
 	}
 
-	c.AddCommand(versionCmd())
+	if err := bindPersistentConfigOptions(c.PersistentFlags()); err != nil {
+		panic(fmt.Errorf("unable to bind config options: %w", err))
+	}
+
+	c.AddCommand(versionCmd(dockerCli))
+	c.AddCommand(mergeCmd())
+	c.AddCommand(queryCmd())
+	c.AddCommand(explainCmd(dockerCli))
+	c.AddCommand(verifyCmd(dockerCli))
+	c.AddCommand(dockerfileCmd(dockerCli))
 
 	return c
 }
@@ -126,10 +152,105 @@ func setPackageFlags(flags *pflag.FlagSet) {
 		"an optional platform specifier for container image sources (e.g. 'linux/arm64', 'linux/arm64/v8', 'arm64', 'linux')",
 	)
 
+	flags.StringP(
+		"base", "", "",
+		fmt.Sprintf("the base image of the image, to tell the packages inherited from it apart from the application packages (%q to detect it among the images in the docker daemon)", autoBaseImage),
+	)
+
+	flags.BoolP(
+		"only-app-packages", "", false,
+		"leave the packages inherited from the base image out of the SBOM (detects the base image unless --base is given)",
+	)
+
+	flags.StringArrayP(
+		"env-denylist", "", imageconfig.DefaultEnvDenylist,
+		"environment variables of the image left out of the SBOM (matched by name using a case-insensitive glob expression), since they could contain secrets",
+	)
+
 	flags.BoolP(
 		"debug", "D", false,
 		"show debug logging",
 	)
+
+	flags.StringP(
+		"license-policy", "", "",
+		fmt.Sprintf("a YAML file with allowed/denied licenses and package exceptions, violations are reported after the SBOM and exit with code %d", licensePolicyExitCode),
+	)
+
+	flags.StringP(
+		"package-policy", "", "",
+		fmt.Sprintf("a YAML file with denied packages (name or package URL patterns with version constraints), violations are reported after the SBOM and exit with code %d", packagePolicyExitCode),
+	)
+
+	flags.StringP(
+		"sarif-output", "", "",
+		"file to write package policy violations to as a SARIF 2.1.0 report",
+	)
+
+	flags.StringP(
+		"vuln-db", "", "",
+		"a directory of OSV vulnerability records (JSON files or the zip archives of the OSV data dumps) to match packages against, matches are included in the table, syft-json, and cyclonedx outputs",
+	)
+
+	flags.StringP(
+		"fail-on-severity", "", "",
+		fmt.Sprintf("exit with code %d when a vulnerability of the given severity or higher is found (requires --vuln-db), options=%v", vulnerabilityExitCode, vulnerability.AllSeverities),
+	)
+
+	flags.StringArrayP(
+		"vex", "", nil,
+		"an OpenVEX or CycloneDX VEX document with triage decisions for vulnerabilities (requires --vuln-db), vulnerabilities that are not affected or fixed do not fail --fail-on-severity",
+	)
+
+	flags.StringP(
+		"vex-output", "", "",
+		"file to write an OpenVEX document with a statement for every vulnerability found to, as a starting point for triage (requires --vuln-db)",
+	)
+
+	flags.BoolP(
+		"attest", "", false,
+		"write the SBOM as an in-toto attestation for the image signed with --key (DSSE envelope), the format must be spdx-json, cyclonedx-json, or syft-json",
+	)
+
+	flags.StringP(
+		"key", "", "",
+		fmt.Sprintf("the private key to sign attestations with: a cosign key (the password is read from %s or prompted for) or an ECDSA/ed25519 PEM key", cosignPasswordEnv),
+	)
+
+	flags.BoolP(
+		"push", "", false,
+		"attach the SBOM (or its attestation with --attest) to the image in its registry, using the docker credentials (the image must have been pushed)",
+	)
+
+	flags.StringP(
+		"use-attached", "", useAttachedNever,
+		fmt.Sprintf("use an SBOM attached to the image in its registry (as a referrer or BuildKit attestation) instead of generating one, options=%v", useAttachedOptions),
+	)
+
+	flags.BoolP(
+		"compare", "", false,
+		"report the discrepancies between the attached SBOM and a generated SBOM (requires --use-attached)",
+	)
+
+	flags.StringP(
+		"progress", "", ui.AutoProgress,
+		fmt.Sprintf("how to show progress on stderr: auto (a terminal UI when stderr is a terminal, plain otherwise), plain (periodic single-line updates), or json (newline-delimited JSON records, with JSON logs), options=%v", ui.ProgressOptions),
+	)
+
+	flags.DurationP(
+		"timeout", "", 0,
+		"stop fetching and cataloging the image after the given duration (e.g. 5m), the default is no timeout",
+	)
+
+	flags.BoolP(
+		"interactive", "i", false,
+		"browse the packages in a full-screen terminal UI after cataloging (with a filter, a layer sidebar, and export of the filtered view), the report is only written with --output",
+	)
+
+	flags.BoolP(
+		"all-local", "", false,
+		fmt.Sprintf("write an SBOM for every image in the local daemon to the --output directory (default %q), skipping images already inventoried", defaultInventoryDir),
+	)
 }
 
 func bindConfigOptions(flags *pflag.FlagSet) error {
@@ -153,6 +274,18 @@ func bindConfigOptions(flags *pflag.FlagSet) error {
 		return err
 	}
 
+	if err := viper.BindPFlag("base", flags.Lookup("base")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("only-app-packages", flags.Lookup("only-app-packages")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("env-denylist", flags.Lookup("env-denylist")); err != nil {
+		return err
+	}
+
 	if err := viper.BindPFlag("platform", flags.Lookup("platform")); err != nil {
 		return err
 	}
@@ -161,19 +294,159 @@ func bindConfigOptions(flags *pflag.FlagSet) error {
 		return err
 	}
 
+	if err := viper.BindPFlag("all-local", flags.Lookup("all-local")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("license-policy", flags.Lookup("license-policy")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("package-policy", flags.Lookup("package-policy")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("sarif-output", flags.Lookup("sarif-output")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("vuln-db", flags.Lookup("vuln-db")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("fail-on-severity", flags.Lookup("fail-on-severity")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("vex", flags.Lookup("vex")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("vex-output", flags.Lookup("vex-output")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("attest", flags.Lookup("attest")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("key", flags.Lookup("key")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("push", flags.Lookup("push")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("use-attached", flags.Lookup("use-attached")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("compare", flags.Lookup("compare")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("progress", flags.Lookup("progress")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("timeout", flags.Lookup("timeout")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("interactive", flags.Lookup("interactive")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func setPersistentFlags(flags *pflag.FlagSet) {
+	flags.StringP(
+		"error-format", "", textErrorFormat,
+		fmt.Sprintf("how to report a failure on stderr: text, or json (a single JSON object with the error code, message, causes, image, and failed stage), options=%v", errorFormatOptions),
+	)
+
+	flags.StringP(
+		"log-file", "", "",
+		"file to write logs to instead of stderr",
+	)
+
+	flags.StringP(
+		"log-level", "", "",
+		"the log level: error, warn, info, debug, or trace (default is warn)",
+	)
+
+	flags.StringP(
+		"log-file-mode", "", config.TruncateLogFile,
+		fmt.Sprintf("how to open an existing log file, options=%v", config.LogFileModes),
+	)
+
+	flags.StringP(
+		"log-max-size", "", "",
+		"rotate the log file when it would exceed this size (e.g. 10MB), keeping --log-max-backups rotated files as <log-file>.1, <log-file>.2, ...",
+	)
+
+	flags.IntP(
+		"log-max-backups", "", 3,
+		"the number of rotated log files to keep (see --log-max-size)",
+	)
+
+	flags.StringArrayP(
+		"log-library-level", "", nil,
+		fmt.Sprintf("the log level of a library as LIBRARY=LEVEL (e.g. stereoscope=error), can be repeated, libraries=%v", config.LogLibraries),
+	)
+}
+
+func bindPersistentConfigOptions(flags *pflag.FlagSet) error {
+	if err := viper.BindPFlag("error-format", flags.Lookup("error-format")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("log.file", flags.Lookup("log-file")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("log.level", flags.Lookup("log-level")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("log.file-mode", flags.Lookup("log-file-mode")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("log.max-size", flags.Lookup("log-max-size")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("log.max-backups", flags.Lookup("log-max-backups")); err != nil {
+		return err
+	}
+
+	if err := viper.BindPFlag("log.library-levels", flags.Lookup("log-library-level")); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func validateInputArgs(cmd *cobra.Command, args []string) error {
+	if allLocal, _ := cmd.Flags().GetBool("all-local"); allLocal {
+		if len(args) > 0 {
+			return withKind(InvalidConfig, fmt.Errorf("an image argument cannot be used with --all-local"))
+		}
+		return nil
+	}
+
 	if len(args) == 0 {
 		// in the case that no arguments are given we want to show the help text and return with a non-0 return code.
 		if err := cmd.Help(); err != nil {
 			return fmt.Errorf("unable to display help: %w", err)
 		}
-		return fmt.Errorf("an image argument is required")
+		return withKind(InvalidConfig, fmt.Errorf("an image argument is required"))
 	}
 
-	return cobra.ExactArgs(1)(cmd, args)
+	return withKind(InvalidConfig, cobra.ExactArgs(1)(cmd, args))
 }
 
 type runner struct {
@@ -187,37 +460,112 @@ func newRunner(client command.Cli) runner {
 }
 
 func (r runner) run(_ *cobra.Command, args []string) error {
-	writer, err := makeWriter([]string{appConfig.Format}, appConfig.Output)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err := writer.Close(); err != nil {
-			log.Warnf("unable to write to report destination: %+v", err)
-		}
-	}()
-
 	var platform *image.Platform
+	var err error
 	if appConfig.Platform != "" {
 		platform, err = image.NewPlatform(appConfig.Platform)
 		if err != nil {
-			return fmt.Errorf("invalid platform provided: %w", err)
+			return withKind(InvalidConfig, fmt.Errorf("invalid platform provided: %w", err))
+		}
+	}
+
+	if err := validateProgress(appConfig.Progress); err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	ctx, cancel := runContext(appConfig.Timeout)
+	defer cancel()
+
+	if appConfig.AllLocal {
+		if err := validateAllLocal(); err != nil {
+			return withKind(InvalidConfig, err)
 		}
+		return r.runInventory(ctx, cancel, platform)
+	}
+
+	if err := validateOptions(); err != nil {
+		return withKind(InvalidConfig, err)
 	}
 
 	cleanImageName, err := cleanImageReference(args[0])
 	if err != nil {
-		return nil
+		return withKind(InvalidReference, err)
+	}
+
+	// policies are read before the writer is created to avoid leaving an empty report file behind on a bad policy
+	gates, err := makeGates()
+	if err != nil {
+		return withKind(InvalidConfig, err)
 	}
 
-	return eventLoop(
-		sbomExecWorker(cleanImageName, r.client, platform, writer),
+	extenders := append(gateExtenders(gates), provenanceExtension, imageConfigExtension)
+	split := makeBaseImageSplit()
+	if split != nil {
+		extenders = append(extenders, split.Extension)
+	}
+
+	var writer sbom.Writer
+	switch {
+	case appConfig.Push:
+		// the key is only set for attestations
+		writer, err = makePushWriter(ctx, r.client, appConfig.Format, appConfig.Output, appConfig.Key, extenders...)
+	case appConfig.Attest:
+		writer, err = makeAttestationWriter(appConfig.Format, appConfig.Output, appConfig.Key, extenders...)
+	default:
+		writer, err = makeWriter([]string{appConfig.Format}, appConfig.Output, extenders...)
+	}
+	if err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	defer func() {
+		if err := writer.Close(); err != nil {
+			log.Warnf("unable to write to report destination: %+v", err)
+		}
+	}()
+
+	err = eventLoop(
+		sbomExecWorker(ctx, cleanImageName, r.client, platform, split, writer, gates...),
 		setupSignals(),
+		cancel,
 		eventSubscription,
 		stereoscope.Cleanup,
-		ui.Select(isVerbose(), appConfig.Quiet)...,
+		ui.Select(appConfig.Progress, isVerbose(), appConfig.Quiet)...,
 	)
+	if cancelErr := cancellationErr(ctx, appConfig.Timeout, err); cancelErr != nil {
+		return cancelErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return gatesErr(gates)
+}
+
+// validateOptions checks the combinations of options for generating an SBOM.
+func validateOptions() error {
+	if appConfig.Key != "" && !appConfig.Attest {
+		return fmt.Errorf("a private key (--key) can only be used to sign attestations (--attest)")
+	}
+	if appConfig.Attest && appConfig.Key == "" {
+		return fmt.Errorf("a private key (--key) is required to sign attestations")
+	}
+	if err := validateUseAttached(appConfig.UseAttached, appConfig.Compare); err != nil {
+		return err
+	}
+	if err := imageconfig.ValidateEnvDenylist(appConfig.EnvDenylist); err != nil {
+		return fmt.Errorf("bad --env-denylist value: %w", err)
+	}
+	return validateInteractive()
+}
+
+func validateProgress(progress string) error {
+	for _, option := range ui.ProgressOptions {
+		if progress == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("bad --progress value %q (options=%v)", progress, ui.ProgressOptions)
 }
 
 func isVerbose() (result bool) {
@@ -231,17 +579,33 @@ func isVerbose() (result bool) {
 	return appConfig.Debug || isPipedInput
 }
 
-func generateSBOM(src *source.Source) (*sbom.SBOM, error) {
+// generateSBOM catalogs the packages of the source (as syft.CatalogPackages does), stopping when the context is cancelled.
+func generateSBOM(ctx context.Context, src *source.Source, p provenance) (*sbom.SBOM, error) {
 	s := sbom.SBOM{
 		Source: src.Metadata,
 		Descriptor: sbom.Descriptor{
-			Name:          internal.SyftName,
-			Version:       version.FromBuild().SyftVersion,
-			Configuration: appConfig,
+			Name:    internal.SyftName,
+			Version: version.FromBuild().SyftVersion,
+			Configuration: descriptorConfiguration{
+				Application: appConfig,
+				Provenance:  p,
+			},
 		},
 	}
 
-	packageCatalog, relationships, theDistro, err := syft.CatalogPackages(src, appConfig.Package.ToConfig())
+	cfg := appConfig.Package.ToConfig()
+	resolver, err := src.FileResolver(cfg.Search.Scope)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine resolver while cataloging packages: %w", err)
+	}
+	resolver = contextResolver{FileResolver: resolver, ctx: ctx}
+
+	theDistro := linux.IdentifyRelease(resolver)
+	packageCatalog, relationships, err := cataloger.Catalog(resolver, theDistro, monitorCatalogers(cataloger.ImageCatalogers(cfg))...)
+	if err := ctx.Err(); err != nil {
+		// the catalogers fail with the cancellation error of the resolver, there is no need to report each failure
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("unable to catalog packages: %w", err)
 	}
@@ -253,58 +617,119 @@ func generateSBOM(src *source.Source) (*sbom.SBOM, error) {
 	return &s, nil
 }
 
-func sbomExecWorker(imageName string, dockerCli command.Cli, platform *image.Platform, writer sbom.Writer) <-chan error {
+func sbomExecWorker(ctx context.Context, imageName string, dockerCli command.Cli, platform *image.Platform, split *baseImageSplit, writer sbom.Writer, gates ...gate) <-chan error {
 	errs := make(chan error)
 	go func() {
 		defer close(errs)
 
-		tempGen := file.NewTempDirGenerator(internal.ApplicationName)
-
-		provider := stereoscopeDocker.NewProviderFromDaemon(
-			imageName,
-			tempGen,
-			dockerCli.Client(),
-			platform,
-		)
-		img, err := provider.Provide(context.Background())
-		defer func() {
-			if err := tempGen.Cleanup(); err != nil {
-				log.Warnf("failed to clean up image: %+v", err)
-			}
-		}()
-		if err != nil {
-			errs <- fmt.Errorf("failed to fetch the image %q: %w", imageName, err)
-			return
-		}
-
-		err = img.Read()
+		s, comparison, err := imageSBOM(ctx, imageName, dockerCli, platform)
 		if err != nil {
-			errs <- fmt.Errorf("failed to read the image %q: %w", imageName, err)
+			// errors without a kind are from finding the attached SBOM
+			errs <- failure(CatalogFailure, FetchStage, err)
 			return
 		}
 
-		src, err := source.NewFromImage(img, imageName)
-		if err != nil {
-			errs <- fmt.Errorf("failed to construct source from user input %q: %w", imageName, err)
-			return
+		// the gates evaluate the SBOM that is written (e.g. without the packages of the base image)
+		if split != nil {
+			applied, err := split.Apply(ctx, *s, dockerCli, platform)
+			if err != nil {
+				errs <- failure(CatalogFailure, CatalogStage, err)
+				return
+			}
+			s = &applied
 		}
-		src.Exclusions = appConfig.Exclusions
 
-		s, err := generateSBOM(&src)
-		if err != nil {
-			errs <- err
-			return
+		for _, g := range gates {
+			if err := g.Evaluate(*s); err != nil {
+				errs <- err
+				return
+			}
 		}
 
-		if err != nil {
-			errs <- errors.New("could not produce an sbom")
-			return
-		}
+		bus.Publish(partybus.Event{
+			Type:  sbomEvent.SBOMReady,
+			Value: *s,
+		})
 
 		bus.Publish(partybus.Event{
-			Type:  event.Exit,
-			Value: func() error { return writer.Write(*s) },
+			Type: event.Exit,
+			Value: func() error {
+				if appConfig.Interactive {
+					if err := browseSBOM(*s, imageName); err != nil {
+						return err
+					}
+				}
+				// the browser replaces the report on stdout, a report file is still written
+				if !appConfig.Interactive || appConfig.Output != "" {
+					if err := writer.Write(*s); err != nil {
+						return withKind(WriteFailure, err)
+					}
+				}
+				// reports are written to stderr to keep stdout reserved for the SBOM
+				if comparison != nil {
+					if err := comparison.Write(os.Stderr); err != nil {
+						return withKind(WriteFailure, err)
+					}
+				}
+				for _, g := range gates {
+					if err := g.Report(os.Stderr); err != nil {
+						return withKind(WriteFailure, err)
+					}
+				}
+				return nil
+			},
 		})
 	}()
 	return errs
 }
+
+// catalogImage generates an SBOM for the given image from the docker daemon, where the user input describes the
+// image within the SBOM (this may differ from the image name, e.g. when the image is fetched by ID). Temp dirs are
+// cleaned up when the context is cancelled.
+func catalogImage(ctx context.Context, imageName, userInput string, dockerCli command.Cli, platform *image.Platform) (*sbom.SBOM, error) {
+	started := time.Now()
+	tempGen := file.NewTempDirGenerator(internal.ApplicationName)
+	defer func() {
+		if err := tempGen.Cleanup(); err != nil {
+			log.Warnf("failed to clean up image: %+v", err)
+		}
+	}()
+
+	provider := stereoscopeDocker.NewProviderFromDaemon(
+		imageName,
+		tempGen,
+		dockerCli.Client(),
+		platform,
+	)
+	img, err := provider.Provide(ctx)
+	if err != nil {
+		return nil, failure(CatalogFailure, FetchStage, daemonError(fmt.Errorf("failed to fetch the image %q: %w", imageName, err)))
+	}
+
+	// reading the image does not take a context
+	if err := ctx.Err(); err != nil {
+		return nil, failure(CatalogFailure, FetchStage, err)
+	}
+
+	err = img.Read()
+	if err != nil {
+		return nil, failure(CatalogFailure, ReadStage, fmt.Errorf("failed to read the image %q: %w", imageName, err))
+	}
+
+	src, err := source.NewFromImage(img, userInput)
+	if err != nil {
+		return nil, failure(CatalogFailure, ReadStage, fmt.Errorf("failed to construct source from user input %q: %w", userInput, err))
+	}
+	src.Exclusions = appConfig.Exclusions
+
+	s, err := generateSBOM(ctx, &src, newProvenance(src.Metadata, engineVersion(ctx, dockerCli), started))
+	if err != nil {
+		return nil, withKind(CatalogFailure, err)
+	}
+
+	if s == nil {
+		return nil, withKind(CatalogFailure, errors.New("could not produce an sbom"))
+	}
+
+	return s, nil
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/sbom-cli-plugin/internal/attest"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/spf13/cobra"
+
+	"github.com/anchore/syft/syft"
+)
+
+const verifyHelpExample = `
+  docker sbom verify --key cosign.pub att.json                       verify the signature and show the SBOM document
+  docker sbom verify --key cosign.pub att.json alpine:latest         also check that the SBOM describes a local image
+  docker sbom verify --key cosign.pub att.json --format table        show the attested SBOM as a table
+`
+
+type verifyOptions struct {
+	key    string
+	format string
+	output string
+}
+
+func verifyCmd(dockerCli command.Cli) *cobra.Command {
+	opts := verifyOptions{}
+
+	c := &cobra.Command{
+		Use:   "verify [flags] ATTESTATION [IMAGE]",
+		Short: "Verify a signed SBOM attestation and extract the SBOM",
+		Long: "Verify the signature of an SBOM attestation (an in-toto statement in a DSSE envelope, as created with --attest or cosign) " +
+			"and write the attested SBOM document. When an image is given, the attestation subject must match the digest of the local image.",
+		Example:       verifyHelpExample,
+		Args:          cobra.RangeArgs(1, 2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: withExitCode(1, func(_ *cobra.Command, args []string) error {
+			var userInput string
+			if len(args) > 1 {
+				userInput = args[1]
+			}
+			return runVerify(dockerCli, opts, args[0], userInput)
+		}),
+	}
+
+	flags := c.Flags()
+
+	flags.StringVarP(
+		&opts.key, "key", "", "",
+		"the public key to verify the attestation signature with (e.g. cosign.pub)",
+	)
+
+	flags.StringVarP(
+		&opts.format, "format", "", "",
+		fmt.Sprintf("re-encode the attested SBOM with the given format (default is the attested document as-is), options=%v", formatAliases(syft.FormatIDs()...)),
+	)
+
+	flags.StringVarP(
+		&opts.output, "output", "o", "",
+		"file to write the SBOM to (default is STDOUT)",
+	)
+
+	return c
+}
+
+func runVerify(dockerCli command.Cli, opts verifyOptions, path, userInput string) error {
+	if opts.key == "" {
+		return withKind(InvalidConfig, fmt.Errorf("a public key (--key) is required to verify attestations"))
+	}
+
+	if opts.format != "" && syft.FormatByName(opts.format) == nil {
+		return withKind(InvalidConfig, fmt.Errorf("bad output format: '%s'", opts.format))
+	}
+
+	key, err := attest.LoadPublicKey(opts.key)
+	if err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	envelope, err := attest.ReadEnvelope(path)
+	if err != nil {
+		return withKind(InvalidConfig, err)
+	}
+
+	statement, err := attest.Verify(*envelope, key)
+	if err != nil {
+		return verificationError(fmt.Errorf("unable to verify attestation %q: %w", path, err))
+	}
+
+	// a signed attestation about something other than an SBOM is not an attestation this command takes
+	id, err := statement.Format()
+	if err != nil {
+		return withKind(InvalidConfig, fmt.Errorf("unable to verify attestation %q: %w", path, err))
+	}
+
+	if userInput != "" {
+		digests, err := imageDigests(dockerCli, userInput)
+		if err != nil {
+			return err
+		}
+		if err := statement.CheckSubject(digests...); err != nil {
+			return verificationError(fmt.Errorf("unable to verify attestation %q for image %q: %w", path, userInput, err))
+		}
+		fmt.Fprintf(os.Stderr, "Verified attestation %q (%s) for image %q\n", path, statement.PredicateType, userInput)
+	} else {
+		fmt.Fprintf(os.Stderr, "Verified the signature of attestation %q (%s), the subject was not checked (no image given)\n", path, statement.PredicateType)
+	}
+
+	if opts.format == "" {
+		return withKind(WriteFailure, writeDocument(opts.output, statement.Predicate))
+	}
+
+	s, err := syft.FormatByID(id).Decode(bytes.NewReader(statement.Predicate))
+	if err != nil {
+		return withKind(InvalidConfig, fmt.Errorf("unable to decode the attested %q document: %w", id, err))
+	}
+
+	writer, err := makeWriter([]string{opts.format}, opts.output)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := writer.Close(); err != nil {
+			log.Warnf("unable to write to report destination: %+v", err)
+		}
+	}()
+
+	return withKind(WriteFailure, writer.Write(*s))
+}
+
+// verificationError returns the error of verifying an attestation as a verification failure when the signature or
+// subject did not verify, where other errors (e.g. a payload that is not an in-toto statement) are bad input.
+func verificationError(err error) error {
+	if errors.Is(err, attest.ErrSignature) || errors.Is(err, attest.ErrSubjectMismatch) {
+		return withKind(VerificationFailure, err)
+	}
+	return withKind(InvalidConfig, err)
+}
+
+// imageDigests returns the digests that identify the given local image: the image ID and every repository digest.
+func imageDigests(dockerCli command.Cli, userInput string) ([]string, error) {
+	imageName, err := cleanImageReference(userInput)
+	if err != nil {
+		return nil, withKind(InvalidReference, err)
+	}
+
+	img, _, err := dockerCli.Client().ImageInspectWithRaw(context.Background(), imageName)
+	if err != nil {
+		return nil, daemonError(fmt.Errorf("failed to fetch the image %q: %w", imageName, err))
+	}
+
+	digests := []string{img.ID}
+	for _, d := range img.RepoDigests {
+		if _, digest, ok := strings.Cut(d, "@"); ok {
+			digests = append(digests, digest)
+		}
+	}
+	return digests, nil
+}
+
+func writeDocument(output string, document []byte) error {
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("unable to create report file: %w", err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Warnf("unable to write to report destination: %+v", err)
+			}
+		}()
+		w = f
+	}
+
+	if _, err := w.Write(document); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -1,40 +1,121 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
+	"github.com/docker/cli/cli/command"
 	"github.com/docker/sbom-cli-plugin/internal"
 	"github.com/docker/sbom-cli-plugin/internal/version"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/anchore/syft/syft"
+)
+
+const (
+	versionTextFormat = "text"
+	versionJSONFormat = "json"
+	versionYAMLFormat = "yaml"
 )
 
-func versionCmd() *cobra.Command {
+// how long to wait for the docker engine to report its version (the engine API is reported as unavailable after that)
+const engineVersionTimeout = 2 * time.Second
+
+var versionFormatOptions = []string{versionTextFormat, versionJSONFormat, versionYAMLFormat}
+
+// versionReport is everything needed to describe the plugin installation (e.g. in a support ticket).
+type versionReport struct {
+	Name            string `json:"name" yaml:"name"`
+	SyftName        string `json:"syftName" yaml:"syftName"`
+	version.Version `yaml:",inline"`
+	ClientAPI       string   `json:"dockerClientAPIVersion" yaml:"dockerClientAPIVersion"`                     // the docker API version used by the client
+	EngineAPI       string   `json:"dockerEngineAPIVersion,omitempty" yaml:"dockerEngineAPIVersion,omitempty"` // the docker API version of the engine (empty when unreachable)
+	OutputFormats   []string `json:"outputFormats" yaml:"outputFormats"`                                       // the supported values of --format
+}
+
+func versionCmd(dockerCli command.Cli) *cobra.Command {
+	var format string
+
 	c := &cobra.Command{
 		Use:           "version",
 		Short:         "Show Docker sbom version information",
 		Args:          cobra.NoArgs,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(_ *cobra.Command, args []string) error {
-			report := tprintf(`Application:        {{ .Name }} ({{ .Version.Version }})
+		RunE: withExitCode(-1, func(_ *cobra.Command, args []string) error {
+			if err := validateVersionFormat(format); err != nil {
+				return withKind(InvalidConfig, err)
+			}
+			return runVersion(os.Stdout, format, newVersionReport(dockerCli))
+		}),
+	}
+
+	c.Flags().StringVarP(
+		&format, "format", "", versionTextFormat,
+		fmt.Sprintf("version output format, options=%v", versionFormatOptions),
+	)
+
+	return c
+}
+
+func validateVersionFormat(format string) error {
+	for _, option := range versionFormatOptions {
+		if format == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported version output format %q (options=%v)", format, versionFormatOptions)
+}
+
+func newVersionReport(dockerCli command.Cli) versionReport {
+	report := versionReport{
+		Name:          internal.BinaryName,
+		SyftName:      internal.SyftName,
+		Version:       version.FromBuild(),
+		OutputFormats: formatAliases(syft.FormatIDs()...),
+	}
+
+	// the client negotiates its API version with the engine when first connecting
+	ctx, cancel := context.WithTimeout(context.Background(), engineVersionTimeout)
+	defer cancel()
+	report.EngineAPI = engineVersion(ctx, dockerCli).APIVersion
+	report.ClientAPI = dockerCli.Client().ClientVersion()
+
+	return report
+}
+
+func runVersion(w io.Writer, format string, report versionReport) error {
+	switch format {
+	case versionTextFormat:
+		_, err := io.WriteString(w, tprintf(`Application:        {{ .Name }} ({{ .Version.Version }})
 Provider:           {{ .SyftName }} ({{ .SyftVersion }})
+Stereoscope:        {{ .StereoscopeVersion }}
 GitCommit:          {{ .GitCommit }}
 GitDescription:     {{ .GitDescription }}
 Platform:           {{ .Platform }}
-`, struct {
-				Name     string
-				SyftName string
-				version.Version
-			}{
-				Name:     internal.BinaryName,
-				SyftName: internal.SyftName,
-				Version:  version.FromBuild(),
-			})
-
-			fmt.Print(report)
-			return nil
-		},
+Client API:         {{ .ClientAPI }}
+Engine API:         {{ if .EngineAPI }}{{ .EngineAPI }}{{ else }}unavailable{{ end }}
+Output formats:     {{ range $i, $f := .OutputFormats }}{{ if $i }}, {{ end }}{{ $f }}{{ end }}
+`, report))
+		return err
+	case versionJSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(report)
+	case versionYAMLFormat:
+		out, err := yaml.Marshal(&report)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return validateVersionFormat(format)
 	}
-
-	return c
 }
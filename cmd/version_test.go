@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/sbom-cli-plugin/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func testVersionReport() versionReport {
+	return versionReport{
+		Name:     "docker-sbom",
+		SyftName: "syft",
+		Version: version.Version{
+			Version:            "v0.6.0",
+			SyftVersion:        "v0.46.3",
+			StereoscopeVersion: "v0.0.0-20220517203449-d4b5b1bc2b1c",
+			GitCommit:          "abc123",
+			GitDescription:     "v0.6.0",
+			GoVersion:          "go1.18",
+			Compiler:           "gc",
+			Platform:           "linux/amd64",
+		},
+		ClientAPI:     "1.41",
+		OutputFormats: []string{"syft-json", "table"},
+	}
+}
+
+func Test_runVersion(t *testing.T) {
+	report := testVersionReport()
+
+	var buf bytes.Buffer
+	require.NoError(t, runVersion(&buf, versionJSONFormat, report))
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	assert.Equal(t, "v0.0.0-20220517203449-d4b5b1bc2b1c", fields["stereoscopeVersion"])
+	assert.Equal(t, "1.41", fields["dockerClientAPIVersion"])
+	assert.NotContains(t, fields, "dockerEngineAPIVersion", "the engine version should be omitted when unreachable")
+	assert.Equal(t, []interface{}{"syft-json", "table"}, fields["outputFormats"])
+
+	buf.Reset()
+	report.EngineAPI = "1.41"
+	require.NoError(t, runVersion(&buf, versionYAMLFormat, report))
+	var decoded versionReport
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report, decoded)
+	assert.Contains(t, buf.String(), "syftVersion: v0.46.3\n")
+
+	buf.Reset()
+	require.NoError(t, runVersion(&buf, versionTextFormat, report))
+	assert.Contains(t, buf.String(), "Provider:           syft (v0.46.3)\n")
+	assert.Contains(t, buf.String(), "Engine API:         1.41\n")
+	assert.Contains(t, buf.String(), "Output formats:     syft-json, table\n")
+
+	assert.Error(t, runVersion(&buf, "xml", report))
+}
@@ -0,0 +1,124 @@
+/*
+Package attest wraps SBOM documents in in-toto attestations (see https://github.com/in-toto/attestation), where the
+subject is the image that the SBOM describes and the predicate is the SBOM document itself. Attestations are signed as
+DSSE envelopes (see https://github.com/secure-systems-lab/dsse) that are compatible with cosign.
+*/
+package attest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+const (
+	// StatementType is the in-toto statement version of created attestations.
+	StatementType = "https://in-toto.io/Statement/v0.1"
+	// PayloadType is the DSSE payload type for in-toto statements.
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// Predicate types for each format that can be attested (these match the types used by cosign and syft).
+const (
+	SPDXPredicateType      = "https://spdx.dev/Document"
+	CycloneDXPredicateType = "https://cyclonedx.org/bom"
+	SyftPredicateType      = "https://syft.dev/bom"
+)
+
+var predicateTypes = map[sbom.FormatID]string{
+	syft.SPDXJSONFormatID:      SPDXPredicateType,
+	syft.CycloneDxJSONFormatID: CycloneDXPredicateType,
+	syft.JSONFormatID:          SyftPredicateType,
+}
+
+// Statement is an in-toto statement.
+type Statement struct {
+	Type          string          `json:"_type"`
+	PredicateType string          `json:"predicateType"`
+	Subject       []Subject       `json:"subject"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// Subject is an artifact that a statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// PredicateType returns the predicate type for SBOM documents of the given format (only JSON formats can be attested).
+func PredicateType(id sbom.FormatID) (string, error) {
+	predicateType, ok := predicateTypes[id]
+	if !ok {
+		return "", fmt.Errorf("format %q cannot be attested (options=%v)", id, []sbom.FormatID{syft.SPDXJSONFormatID, syft.CycloneDxJSONFormatID, syft.JSONFormatID})
+	}
+	return predicateType, nil
+}
+
+// NewStatement creates a statement for the given SBOM document (encoded with the given format) about the image the
+// SBOM describes.
+func NewStatement(src source.Metadata, id sbom.FormatID, document []byte) (*Statement, error) {
+	predicateType, err := PredicateType(id)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := Subjects(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(document) {
+		return nil, fmt.Errorf("the %q document is not valid JSON", id)
+	}
+
+	return &Statement{
+		Type:          StatementType,
+		PredicateType: predicateType,
+		Subject:       subjects,
+		Predicate:     document,
+	}, nil
+}
+
+// Subjects returns the subjects for the image the SBOM describes: the image repository and manifest digest for every
+// registry the image has been pushed to or pulled from. Images that are only available locally are identified by the
+// image ID (the digest of the image config).
+func Subjects(src source.Metadata) ([]Subject, error) {
+	if src.Scheme != source.ImageScheme {
+		return nil, fmt.Errorf("only images can be attested")
+	}
+	img := src.ImageMetadata
+
+	var subjects []Subject
+	for _, d := range img.RepoDigests {
+		name, digest, ok := strings.Cut(d, "@")
+		if !ok {
+			continue
+		}
+		subject, err := newSubject(name, digest)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if len(subjects) == 0 {
+		subject, err := newSubject(img.UserInput, img.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine the image digest: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, nil
+}
+
+func newSubject(name, digest string) (Subject, error) {
+	algorithm, value, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || value == "" {
+		return Subject{}, fmt.Errorf("bad digest %q", digest)
+	}
+	return Subject{Name: name, Digest: map[string]string{algorithm: value}}, nil
+}
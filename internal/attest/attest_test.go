@@ -0,0 +1,196 @@
+package attest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/source"
+)
+
+func testImage() source.Metadata {
+	return source.Metadata{
+		Scheme: source.ImageScheme,
+		ImageMetadata: source.ImageMetadata{
+			UserInput:   "example.com/app:1.0",
+			ID:          "sha256:fedcba9876543210",
+			RepoDigests: []string{"example.com/app@sha256:0123456789abcdef", "mirror.example.com/app@sha256:0123456789abcdef"},
+		},
+	}
+}
+
+func TestNewStatement(t *testing.T) {
+	statement, err := NewStatement(testImage(), syft.SPDXJSONFormatID, []byte(`{"spdxVersion": "SPDX-2.2"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, StatementType, statement.Type)
+	assert.Equal(t, SPDXPredicateType, statement.PredicateType)
+	assert.Equal(t, []Subject{
+		{Name: "example.com/app", Digest: map[string]string{"sha256": "0123456789abcdef"}},
+		{Name: "mirror.example.com/app", Digest: map[string]string{"sha256": "0123456789abcdef"}},
+	}, statement.Subject)
+	assert.JSONEq(t, `{"spdxVersion": "SPDX-2.2"}`, string(statement.Predicate))
+
+	_, err = NewStatement(testImage(), syft.SPDXTagValueFormatID, []byte("SPDXVersion: SPDX-2.2"))
+	assert.Error(t, err)
+
+	_, err = NewStatement(testImage(), syft.JSONFormatID, []byte("{"))
+	assert.Error(t, err)
+}
+
+func TestSubjects(t *testing.T) {
+	// images that have not been pushed are identified by the image ID
+	local := testImage()
+	local.ImageMetadata.RepoDigests = nil
+	subjects, err := Subjects(local)
+	require.NoError(t, err)
+	assert.Equal(t, []Subject{{Name: "example.com/app:1.0", Digest: map[string]string{"sha256": "fedcba9876543210"}}}, subjects)
+
+	_, err = Subjects(source.Metadata{Scheme: source.DirectoryScheme, Path: "."})
+	assert.Error(t, err)
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+func noPassword() ([]byte, error) {
+	panic("the password should not be needed for unencrypted keys")
+}
+
+func TestSign(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecPKCS8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	ecSEC1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	edPKCS8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	verifyEC := func(message, sig []byte) bool {
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(&ecKey.PublicKey, digest[:], sig)
+	}
+	verifyED := func(message, sig []byte) bool {
+		return ed25519.Verify(edKey.Public().(ed25519.PublicKey), message, sig)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		verify func(message, sig []byte) bool
+	}{
+		{name: "ECDSA PKCS #8", path: writePEM(t, pkcs8KeyType, ecPKCS8), verify: verifyEC},
+		{name: "ECDSA SEC 1", path: writePEM(t, ecKeyType, ecSEC1), verify: verifyEC},
+		{name: "ed25519", path: writePEM(t, pkcs8KeyType, edPKCS8), verify: verifyED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LoadPrivateKey(tt.path, noPassword)
+			require.NoError(t, err)
+
+			statement, err := NewStatement(testImage(), syft.CycloneDxJSONFormatID, []byte(`{"bomFormat": "CycloneDX"}`))
+			require.NoError(t, err)
+
+			envelope, err := Sign(*statement, key)
+			require.NoError(t, err)
+			require.Len(t, envelope.Signatures, 1)
+			assert.Equal(t, PayloadType, envelope.PayloadType)
+			assert.True(t, tt.verify(pae(envelope.PayloadType, envelope.Payload), envelope.Signatures[0].Sig))
+
+			// the payload is base64 encoded in the serialized envelope
+			var buf bytes.Buffer
+			require.NoError(t, envelope.Write(&buf))
+			var got Envelope
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, *envelope, got)
+
+			var payload Statement
+			require.NoError(t, json.Unmarshal(got.Payload, &payload))
+			assert.Equal(t, CycloneDXPredicateType, payload.PredicateType)
+		})
+	}
+}
+
+// encryptCosignKey encrypts a PKCS #8 key in the same way as "cosign generate-key-pair".
+func encryptCosignKey(t *testing.T, der, password []byte) []byte {
+	t.Helper()
+	var k encryptedKey
+	k.KDF.Name = "scrypt"
+	k.KDF.Params.N = 1 << 10 // cosign uses 2^15, which is slow for tests
+	k.KDF.Params.R = 8
+	k.KDF.Params.P = 1
+	k.KDF.Salt = make([]byte, 32)
+	k.Cipher.Name = "nacl/secretbox"
+	k.Cipher.Nonce = make([]byte, 24)
+	_, err := rand.Read(k.KDF.Salt)
+	require.NoError(t, err)
+	_, err = rand.Read(k.Cipher.Nonce)
+	require.NoError(t, err)
+
+	secret, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, 32)
+	require.NoError(t, err)
+	var key [32]byte
+	var nonce [24]byte
+	copy(key[:], secret)
+	copy(nonce[:], k.Cipher.Nonce)
+	k.Ciphertext = secretbox.Seal(nil, der, &nonce, &key)
+
+	by, err := json.Marshal(k)
+	require.NoError(t, err)
+	return by
+}
+
+func TestLoadPrivateKey_cosign(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	path := writePEM(t, cosignEncryptedKeyType, encryptCosignKey(t, der, []byte("s3cr3t")))
+
+	password := func(pw string) func() ([]byte, error) {
+		return func() ([]byte, error) { return []byte(pw), nil }
+	}
+
+	key, err := LoadPrivateKey(path, password("s3cr3t"))
+	require.NoError(t, err)
+	assert.Equal(t, &ecKey.PublicKey, key.Public())
+
+	_, err = LoadPrivateKey(path, password("wrong"))
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+}
+
+func TestLoadPrivateKey_invalid(t *testing.T) {
+	_, err := LoadPrivateKey(filepath.Join(t.TempDir(), "missing.key"), noPassword)
+	assert.Error(t, err)
+
+	_, err = LoadPrivateKey(writePEM(t, "RSA PRIVATE KEY", []byte("...")), noPassword)
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0600))
+	_, err = LoadPrivateKey(path, noPassword)
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package attest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"` // base64 encoded when serialized
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature over the payload of an envelope.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"` // base64 encoded when serialized
+}
+
+// pae returns the DSSE pre-authentication encoding of a payload, which is the message that is signed.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// Sign creates an envelope for the statement signed with the given key.
+func Sign(statement Statement, key *PrivateKey) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode statement: %w", err)
+	}
+
+	sig, err := key.Sign(pae(PayloadType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign statement: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures:  []Signature{{Sig: sig}},
+	}, nil
+}
+
+// Write encodes the envelope as JSON.
+func (e Envelope) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(e)
+}
@@ -0,0 +1,153 @@
+package attest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PEM block types for private keys
+const (
+	cosignEncryptedKeyType   = "ENCRYPTED COSIGN PRIVATE KEY"
+	sigstoreEncryptedKeyType = "ENCRYPTED SIGSTORE PRIVATE KEY"
+	pkcs8KeyType             = "PRIVATE KEY"
+	ecKeyType                = "EC PRIVATE KEY"
+)
+
+// ErrIncorrectPassword is returned when an encrypted key cannot be decrypted with the given password.
+var ErrIncorrectPassword = errors.New("unable to decrypt the private key (is the password correct?)")
+
+// PrivateKey is an ECDSA or ed25519 key used to sign attestations.
+type PrivateKey struct {
+	key crypto.Signer
+}
+
+// Sign signs the given message, where ECDSA signatures are over the SHA-256 digest of the message (as with cosign).
+func (k PrivateKey) Sign(message []byte) ([]byte, error) {
+	switch key := k.key.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, message), nil
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(message)
+		return ecdsa.SignASN1(rand.Reader, key, digest[:])
+	}
+	return nil, fmt.Errorf("unsupported key type %T", k.key)
+}
+
+// Public returns the public key.
+func (k PrivateKey) Public() crypto.PublicKey {
+	return k.key.Public()
+}
+
+// LoadPrivateKey reads a PEM encoded private key: a cosign encrypted key (as created by "cosign generate-key-pair"),
+// or an unencrypted PKCS #8 or SEC 1 key. The password function is only called for encrypted keys.
+func LoadPrivateKey(path string, password func() ([]byte, error)) (*PrivateKey, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(by)
+	if block == nil {
+		return nil, fmt.Errorf("unable to read private key %q: no PEM data found", path)
+	}
+
+	key, err := parsePrivateKey(block, password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read private key %q: %w", path, err)
+	}
+	return key, nil
+}
+
+func parsePrivateKey(block *pem.Block, password func() ([]byte, error)) (*PrivateKey, error) {
+	der := block.Bytes
+	switch block.Type {
+	case cosignEncryptedKeyType, sigstoreEncryptedKeyType:
+		pw, err := password()
+		if err != nil {
+			return nil, err
+		}
+		if der, err = decrypt(der, pw); err != nil {
+			return nil, err
+		}
+	case pkcs8KeyType:
+	case ecKeyType:
+		key, err := x509.ParseECPrivateKey(der)
+		if err != nil {
+			return nil, err
+		}
+		return &PrivateKey{key: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return &PrivateKey{key: key}, nil
+	case ed25519.PrivateKey:
+		return &PrivateKey{key: key}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T (only ECDSA and ed25519 keys are supported)", key)
+}
+
+// encryptedKey is the cosign encrypted key format (scrypt key derivation with a NaCl secretbox cipher).
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func decrypt(data, password []byte) ([]byte, error) {
+	var k encryptedKey
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("unable to read encrypted key: %w", err)
+	}
+	if k.KDF.Name != "scrypt" || k.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported key encryption (kdf=%q, cipher=%q)", k.KDF.Name, k.Cipher.Name)
+	}
+	if len(k.Cipher.Nonce) != 24 {
+		return nil, fmt.Errorf("bad nonce length %d", len(k.Cipher.Nonce))
+	}
+
+	secret, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive key: %w", err)
+	}
+
+	var key [32]byte
+	var nonce [24]byte
+	copy(key[:], secret)
+	copy(nonce[:], k.Cipher.Nonce)
+
+	plaintext, ok := secretbox.Open(nil, k.Ciphertext, &nonce, &key)
+	if !ok {
+		return nil, ErrIncorrectPassword
+	}
+	return plaintext, nil
+}
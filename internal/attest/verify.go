@@ -0,0 +1,159 @@
+package attest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// statementTypePrefix is common to all in-toto statement versions.
+const statementTypePrefix = "https://in-toto.io/Statement/"
+
+// Reasons that an attestation fails verification (see errors.Is).
+var (
+	ErrSignature            = errors.New("signature verification failed")
+	ErrSubjectMismatch      = errors.New("the attestation subject does not match the image")
+	ErrUnknownPredicateType = errors.New("unknown predicate type")
+)
+
+// PublicKey is an ECDSA or ed25519 key used to verify attestations.
+type PublicKey struct {
+	key crypto.PublicKey
+}
+
+// Verify checks the signature of the given message (see PrivateKey.Sign).
+func (k PublicKey) Verify(message, sig []byte) bool {
+	switch key := k.key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, sig)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(key, digest[:], sig)
+	}
+	return false
+}
+
+// LoadPublicKey reads a PEM encoded PKIX public key (e.g. a cosign.pub file as created by "cosign generate-key-pair").
+func LoadPublicKey(path string) (*PublicKey, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(by)
+	if block == nil {
+		return nil, fmt.Errorf("unable to read public key %q: no PEM data found", path)
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("unable to read public key %q: unsupported PEM block type %q", path, block.Type)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read public key %q: %w", path, err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		return &PublicKey{key: key}, nil
+	}
+	return nil, fmt.Errorf("unable to read public key %q: unsupported key type %T (only ECDSA and ed25519 keys are supported)", path, key)
+}
+
+// ReadEnvelope reads a DSSE envelope from a file.
+func ReadEnvelope(path string) (*Envelope, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read attestation: %w", err)
+	}
+
+	var e Envelope
+	if err := json.Unmarshal(by, &e); err != nil {
+		return nil, fmt.Errorf("unable to read attestation %q: %w", path, err)
+	}
+	if e.PayloadType == "" || len(e.Payload) == 0 {
+		return nil, fmt.Errorf("unable to read attestation %q: not a DSSE envelope", path)
+	}
+	return &e, nil
+}
+
+// Verify checks that the envelope is signed with the given key and returns the in-toto statement it contains.
+func Verify(e Envelope, key *PublicKey) (*Statement, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unsupported payload type %q (expected %q)", e.PayloadType, PayloadType)
+	}
+	if len(e.Signatures) == 0 {
+		return nil, fmt.Errorf("%w: the envelope is not signed", ErrSignature)
+	}
+
+	message := pae(e.PayloadType, e.Payload)
+	verified := false
+	for _, sig := range e.Signatures {
+		if key.Verify(message, sig.Sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("%w: none of the %d signature(s) were made by the given key", ErrSignature, len(e.Signatures))
+	}
+
+	var s Statement
+	if err := json.Unmarshal(e.Payload, &s); err != nil {
+		return nil, fmt.Errorf("unable to decode statement: %w", err)
+	}
+	if !strings.HasPrefix(s.Type, statementTypePrefix) {
+		return nil, fmt.Errorf("unsupported statement type %q", s.Type)
+	}
+	return &s, nil
+}
+
+// Format returns the format of the SBOM document in the predicate of the statement.
+func (s Statement) Format() (sbom.FormatID, error) {
+	for id, predicateType := range predicateTypes {
+		if s.PredicateType == predicateType {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q (expected one of %v)", ErrUnknownPredicateType, s.PredicateType, knownPredicateTypes())
+}
+
+// CheckSubject checks that one of the statement subjects has one of the given digests (e.g. "sha256:...").
+func (s Statement) CheckSubject(digests ...string) error {
+	for _, subject := range s.Subject {
+		for algorithm, value := range subject.Digest {
+			for _, d := range digests {
+				if d == algorithm+":"+value {
+					return nil
+				}
+			}
+		}
+	}
+
+	var subjects []string
+	for _, subject := range s.Subject {
+		for algorithm, value := range subject.Digest {
+			subjects = append(subjects, fmt.Sprintf("%s@%s:%s", subject.Name, algorithm, value))
+		}
+	}
+	sort.Strings(subjects)
+	return fmt.Errorf("%w: the attestation is about %v but the image has digests %v", ErrSubjectMismatch, subjects, digests)
+}
+
+func knownPredicateTypes() (types []string) {
+	for _, predicateType := range predicateTypes {
+		types = append(types, predicateType)
+	}
+	sort.Strings(types)
+	return types
+}
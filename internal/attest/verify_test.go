@@ -0,0 +1,130 @@
+package attest
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+)
+
+func generateKeys(t *testing.T) (*PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return &PrivateKey{key: key}, writePEM(t, "PUBLIC KEY", der)
+}
+
+func signedEnvelope(t *testing.T, key *PrivateKey) *Envelope {
+	t.Helper()
+	statement, err := NewStatement(testImage(), syft.SPDXJSONFormatID, []byte(`{"spdxVersion": "SPDX-2.2"}`))
+	require.NoError(t, err)
+	envelope, err := Sign(*statement, key)
+	require.NoError(t, err)
+	return envelope
+}
+
+func TestVerify(t *testing.T) {
+	key, pubPath := generateKeys(t)
+	pub, err := LoadPublicKey(pubPath)
+	require.NoError(t, err)
+
+	// round trip through a file, as written with --attest
+	path := filepath.Join(t.TempDir(), "attestation.json")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, signedEnvelope(t, key).Write(f))
+	require.NoError(t, f.Close())
+
+	envelope, err := ReadEnvelope(path)
+	require.NoError(t, err)
+
+	statement, err := Verify(*envelope, pub)
+	require.NoError(t, err)
+	assert.Equal(t, SPDXPredicateType, statement.PredicateType)
+	assert.JSONEq(t, `{"spdxVersion": "SPDX-2.2"}`, string(statement.Predicate))
+
+	id, err := statement.Format()
+	require.NoError(t, err)
+	assert.Equal(t, syft.SPDXJSONFormatID, id)
+
+	assert.NoError(t, statement.CheckSubject("sha256:fedcba9876543210", "sha256:0123456789abcdef"))
+	assert.ErrorIs(t, statement.CheckSubject("sha256:fedcba9876543210"), ErrSubjectMismatch)
+}
+
+func TestVerify_ed25519(t *testing.T) {
+	pubKey, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	require.NoError(t, err)
+
+	pub, err := LoadPublicKey(writePEM(t, "PUBLIC KEY", der))
+	require.NoError(t, err)
+
+	_, err = Verify(*signedEnvelope(t, &PrivateKey{key: key}), pub)
+	assert.NoError(t, err)
+}
+
+func TestVerify_failures(t *testing.T) {
+	key, pubPath := generateKeys(t)
+	pub, err := LoadPublicKey(pubPath)
+	require.NoError(t, err)
+
+	otherKey, _ := generateKeys(t)
+
+	t.Run("signed with another key", func(t *testing.T) {
+		_, err := Verify(*signedEnvelope(t, otherKey), pub)
+		assert.ErrorIs(t, err, ErrSignature)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		envelope := signedEnvelope(t, key)
+		envelope.Payload = append(envelope.Payload, ' ')
+		_, err := Verify(*envelope, pub)
+		assert.ErrorIs(t, err, ErrSignature)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		envelope := signedEnvelope(t, key)
+		envelope.Signatures = nil
+		_, err := Verify(*envelope, pub)
+		assert.ErrorIs(t, err, ErrSignature)
+	})
+
+	t.Run("unknown predicate type", func(t *testing.T) {
+		statement, err := NewStatement(testImage(), syft.SPDXJSONFormatID, []byte(`{}`))
+		require.NoError(t, err)
+		statement.PredicateType = "https://slsa.dev/provenance/v0.2"
+		envelope, err := Sign(*statement, key)
+		require.NoError(t, err)
+
+		got, err := Verify(*envelope, pub)
+		require.NoError(t, err)
+		_, err = got.Format()
+		assert.ErrorIs(t, err, ErrUnknownPredicateType)
+	})
+}
+
+func TestLoadPublicKey_invalid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	// a private key is not accepted in place of the public key
+	_, err = LoadPublicKey(writePEM(t, pkcs8KeyType, der))
+	assert.Error(t, err)
+
+	_, err = LoadPublicKey(filepath.Join(t.TempDir(), "missing.pub"))
+	assert.Error(t, err)
+}
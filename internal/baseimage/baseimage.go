@@ -0,0 +1,196 @@
+/*
+Package baseimage finds the base image of an image by its layers, and tells the packages that were inherited from the
+base image apart from the packages that were added on top of it (by the application).
+*/
+package baseimage
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// Origin tells whether a package was inherited from the base image or added on top of it.
+type Origin string
+
+const (
+	BaseOrigin        Origin = "base"
+	ApplicationOrigin Origin = "application"
+)
+
+// Image is an image that could be the base of another image.
+type Image struct {
+	Name   string   `json:"name" yaml:"name"`     // a reference to the image (its ID when the image has no tags)
+	ID     string   `json:"id" yaml:"id"`         // the image ID
+	Layers []string `json:"layers" yaml:"layers"` // the digests of the uncompressed layers (diff IDs), from the bottom layer up
+}
+
+// IsBaseOf indicates if the image is the base of an image with the given ID and layers, i.e. the layers of the image
+// start with all layers of the base image. An image is not the base of itself, but an image that only differs by its
+// config (e.g. labels) is.
+func (i Image) IsBaseOf(id string, layers []string) bool {
+	if i.ID == id || len(i.Layers) == 0 || len(i.Layers) > len(layers) {
+		return false
+	}
+	for idx, layer := range i.Layers {
+		if layers[idx] != layer {
+			return false
+		}
+	}
+	return true
+}
+
+// Detect returns the candidate that is the closest base of the image with the given ID and layers: the base with the
+// most layers, where tagged images are preferred over untagged images with the same layers. Nil is returned when none
+// of the candidates is a base of the image.
+func Detect(id string, layers []string, candidates []Image) *Image {
+	var bases []Image
+	for _, c := range candidates {
+		if c.IsBaseOf(id, layers) {
+			bases = append(bases, c)
+		}
+	}
+	if len(bases) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(bases, func(i, j int) bool {
+		a, b := bases[i], bases[j]
+		switch {
+		case len(a.Layers) != len(b.Layers):
+			return len(a.Layers) > len(b.Layers)
+		case a.tagged() != b.tagged():
+			return a.tagged()
+		}
+		return a.Name < b.Name
+	})
+	return &bases[0]
+}
+
+func (i Image) tagged() bool {
+	return i.Name != i.ID && !strings.HasPrefix(i.Name, "sha256:")
+}
+
+// packageDBTypes are the types of packages that are found by a package database shared by all packages of the type
+// (e.g. /lib/apk/db/installed), which is rewritten in the layer of any change to the installed packages.
+var packageDBTypes = map[pkg.Type]struct{}{
+	pkg.ApkPkg: {},
+	pkg.DebPkg: {},
+	pkg.RpmPkg: {},
+}
+
+// identity tells packages apart regardless of where they were found.
+type identity struct {
+	name    string
+	version string
+	typ     pkg.Type
+}
+
+func identityOf(p pkg.Package) identity {
+	return identity{name: p.Name, version: p.Version, typ: p.Type}
+}
+
+// Origins returns the origin of every package in the catalog, given the packages of the base image (the base catalog).
+//
+// Packages found by a package database (OS packages) are inherited from the base image when the base image has the
+// same package (by package URL, or by name, version, and type). Their location cannot tell, since installing any
+// package on top of the base image rewrites the database in a layer of the application.
+//
+// Other packages are found by their own files, and are inherited from the base image when all locations the package
+// was found at are within the layers of the base image. For both, a package that was changed by the application (e.g.
+// upgraded) is an application package.
+func Origins(catalog *pkg.Catalog, base Image, baseCatalog *pkg.Catalog) map[artifact.ID]Origin {
+	baseLayers := make(map[string]struct{}, len(base.Layers))
+	for _, layer := range base.Layers {
+		baseLayers[layer] = struct{}{}
+	}
+
+	basePURLs := make(map[string]struct{})
+	baseIdentities := make(map[identity]struct{})
+	if baseCatalog != nil {
+		for p := range baseCatalog.Enumerate() {
+			if p.PURL != "" {
+				basePURLs[p.PURL] = struct{}{}
+			}
+			baseIdentities[identityOf(p)] = struct{}{}
+		}
+	}
+
+	origins := make(map[artifact.ID]Origin)
+	if catalog == nil {
+		return origins
+	}
+	for p := range catalog.Enumerate() {
+		origin := ApplicationOrigin
+		if _, ok := packageDBTypes[p.Type]; ok {
+			if inBaseCatalog(p, basePURLs, baseIdentities) {
+				origin = BaseOrigin
+			}
+		} else if inBaseLayers(p, baseLayers) {
+			origin = BaseOrigin
+		}
+		origins[p.ID()] = origin
+	}
+	return origins
+}
+
+func inBaseCatalog(p pkg.Package, purls map[string]struct{}, identities map[identity]struct{}) bool {
+	if _, ok := purls[p.PURL]; ok && p.PURL != "" {
+		return true
+	}
+	_, ok := identities[identityOf(p)]
+	return ok
+}
+
+func inBaseLayers(p pkg.Package, layers map[string]struct{}) bool {
+	locations := p.Locations.ToSlice()
+	if len(locations) == 0 {
+		return false
+	}
+	for _, l := range locations {
+		if _, ok := layers[l.FileSystemID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplicationPackages returns the SBOM with only the application packages, where relationships with the removed
+// packages are left out.
+func ApplicationPackages(s sbom.SBOM, origins map[artifact.ID]Origin) sbom.SBOM {
+	if s.Artifacts.PackageCatalog == nil {
+		return s
+	}
+
+	var kept []pkg.Package
+	removed := make(map[artifact.ID]struct{})
+	for _, p := range s.Artifacts.PackageCatalog.Sorted() {
+		if origins[p.ID()] == BaseOrigin {
+			removed[p.ID()] = struct{}{}
+			continue
+		}
+		kept = append(kept, p)
+	}
+
+	result := s
+	result.Artifacts.PackageCatalog = pkg.NewCatalog(kept...)
+	result.Relationships = nil
+	for _, r := range s.Relationships {
+		if isRemoved(r.From, removed) || isRemoved(r.To, removed) {
+			continue
+		}
+		result.Relationships = append(result.Relationships, r)
+	}
+	return result
+}
+
+func isRemoved(i artifact.Identifiable, removed map[artifact.ID]struct{}) bool {
+	if i == nil {
+		return false
+	}
+	_, ok := removed[i.ID()]
+	return ok
+}
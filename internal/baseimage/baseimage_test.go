@@ -0,0 +1,154 @@
+package baseimage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+var imageLayers = []string{"sha256:a", "sha256:b", "sha256:c"}
+
+func TestImage_IsBaseOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		image Image
+		want  bool
+	}{
+		{
+			name:  "prefix",
+			image: Image{ID: "sha256:base", Layers: []string{"sha256:a", "sha256:b"}},
+			want:  true,
+		},
+		{
+			name:  "same layers with another config",
+			image: Image{ID: "sha256:labeled", Layers: imageLayers},
+			want:  true,
+		},
+		{
+			name:  "itself",
+			image: Image{ID: "sha256:image", Layers: imageLayers},
+		},
+		{
+			name:  "diverging",
+			image: Image{ID: "sha256:other", Layers: []string{"sha256:a", "sha256:x"}},
+		},
+		{
+			name:  "more layers",
+			image: Image{ID: "sha256:child", Layers: append(imageLayers, "sha256:d")},
+		},
+		{
+			name:  "no layers",
+			image: Image{ID: "sha256:scratch"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.image.IsBaseOf("sha256:image", imageLayers))
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	candidates := []Image{
+		{Name: "alpine:3.16", ID: "sha256:alpine", Layers: []string{"sha256:a"}},
+		{Name: "sha256:untagged", ID: "sha256:untagged", Layers: []string{"sha256:a", "sha256:b"}},
+		{Name: "runtime:latest", ID: "sha256:runtime", Layers: []string{"sha256:a", "sha256:b"}},
+		{Name: "other:latest", ID: "sha256:other", Layers: []string{"sha256:x"}},
+	}
+
+	base := Detect("sha256:image", imageLayers, candidates)
+	require.NotNil(t, base)
+	assert.Equal(t, "runtime:latest", base.Name)
+
+	assert.Nil(t, Detect("sha256:image", imageLayers, candidates[3:]))
+}
+
+func testPackage(name, version string, ty pkg.Type, path string, layers ...string) pkg.Package {
+	p := pkg.Package{Name: name, Version: version, Type: ty}
+	for _, layer := range layers {
+		p.Locations.Add(source.NewLocationFromCoordinates(source.Coordinates{RealPath: path, FileSystemID: layer}))
+	}
+	p.SetID()
+	return p
+}
+
+func testAPK(name, version string, layers ...string) pkg.Package {
+	return testPackage(name, version, pkg.ApkPkg, "/lib/apk/db/installed", layers...)
+}
+
+func testNPM(name, version string, layers ...string) pkg.Package {
+	return testPackage(name, version, pkg.NpmPkg, "/usr/lib/node_modules/"+name+"/package.json", layers...)
+}
+
+func TestOrigins(t *testing.T) {
+	// the base image as cataloged on its own
+	baseCatalog := pkg.NewCatalog(
+		testAPK("musl", "1.2.3", "sha256:a"),
+		testAPK("openssl", "1.1.1n", "sha256:a"),
+		testNPM("npm", "8.11.0", "sha256:b"),
+	)
+
+	// the application ran "apk add", so the package database of all OS packages is found in its layer
+	musl := testAPK("musl", "1.2.3", "sha256:c")
+	upgraded := testAPK("openssl", "1.1.1q", "sha256:c")
+	added := testAPK("curl", "7.83.1", "sha256:c")
+	npm := testNPM("npm", "8.11.0", "sha256:b")
+	app := testNPM("app", "1.0.0", "sha256:c")
+	copied := testPackage("npm", "8.11.0", pkg.NpmPkg, "/app/node_modules/npm/package.json", "sha256:c")
+	unknown := testNPM("unknown", "1.0.0")
+
+	origins := Origins(pkg.NewCatalog(musl, upgraded, added, npm, app, copied, unknown), Image{Layers: []string{"sha256:a", "sha256:b"}}, baseCatalog)
+	assert.Equal(t, map[artifact.ID]Origin{
+		musl.ID():     BaseOrigin,
+		upgraded.ID(): ApplicationOrigin,
+		added.ID():    ApplicationOrigin,
+		npm.ID():      BaseOrigin,
+		app.ID():      ApplicationOrigin,
+		copied.ID():   ApplicationOrigin,
+		unknown.ID():  ApplicationOrigin,
+	}, origins)
+}
+
+func TestOrigins_byPURL(t *testing.T) {
+	base := testAPK("musl", "1.2.3", "sha256:a")
+	base.PURL = "pkg:alpine/musl@1.2.3?arch=x86_64"
+	base.SetID()
+
+	// the package URL matches while the name differs (e.g. cataloged by another version of the cataloger)
+	musl := testAPK("musl-libc", "1.2.3", "sha256:c")
+	musl.PURL = base.PURL
+	musl.SetID()
+
+	origins := Origins(pkg.NewCatalog(musl), Image{Layers: []string{"sha256:a"}}, pkg.NewCatalog(base))
+	assert.Equal(t, BaseOrigin, origins[musl.ID()])
+}
+
+func TestOrigins_noBase(t *testing.T) {
+	musl := testAPK("musl", "1.2.3", "sha256:a")
+	origins := Origins(pkg.NewCatalog(musl), Image{}, nil)
+	assert.Equal(t, ApplicationOrigin, origins[musl.ID()])
+}
+
+func TestApplicationPackages(t *testing.T) {
+	musl := testAPK("musl", "1.2.3", "sha256:c")
+	app := testNPM("app", "1.0.0", "sha256:c")
+	s := sbom.SBOM{
+		Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(musl, app)},
+		Relationships: []artifact.Relationship{
+			{From: app, To: musl, Type: artifact.DependencyOfRelationship},
+		},
+	}
+
+	base := pkg.NewCatalog(testAPK("musl", "1.2.3", "sha256:a"))
+	result := ApplicationPackages(s, Origins(s.Artifacts.PackageCatalog, Image{Layers: []string{"sha256:a"}}, base))
+	assert.Equal(t, 1, result.Artifacts.PackageCatalog.PackageCount())
+	assert.NotNil(t, result.Artifacts.PackageCatalog.Package(app.ID()))
+	assert.Empty(t, result.Relationships)
+	assert.Equal(t, 2, s.Artifacts.PackageCatalog.PackageCount(), "the original SBOM should be unchanged")
+}
@@ -0,0 +1,329 @@
+/*
+Package browse is an interactive full-screen terminal browser for the packages of an SBOM: a filterable package list,
+a detail pane with the evidence behind the selected package, a sidebar to select the packages of a single image layer,
+and an export of the current (filtered) view to a file in any SBOM format.
+*/
+package browse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/explain"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// Exporter writes the given SBOM to the path in the named format.
+type Exporter func(s sbom.SBOM, format, path string) error
+
+type mode int
+
+const (
+	browsing mode = iota
+	filtering
+	exportingFormat
+	exportingPath
+)
+
+type focus int
+
+const (
+	packageList focus = iota
+	layerList
+)
+
+// Browser is the state of the browser, which is changed by key presses and rendered to lines of the screen.
+type Browser struct {
+	sbom     sbom.SBOM
+	title    string
+	export   Exporter
+	packages []pkg.Package // all packages, sorted
+
+	filter      string
+	layer       int // the index of the selected layer (-1 for all layers)
+	visible     []pkg.Package
+	cursor      int
+	offset      int
+	layerCursor int // the cursor in the layer list, where 0 is "all layers"
+	focus       focus
+
+	mode    mode
+	input   string
+	format  string
+	message string
+}
+
+// New creates a browser for the packages of the SBOM, where the title describes the SBOM (e.g. the image name) and the
+// current view is exported with the given exporter.
+func New(s sbom.SBOM, title string, export Exporter) *Browser {
+	b := &Browser{
+		sbom:   s,
+		title:  title,
+		export: export,
+		layer:  -1,
+	}
+	if s.Artifacts.PackageCatalog != nil {
+		b.packages = s.Artifacts.PackageCatalog.Sorted()
+	}
+	b.refresh()
+	return b
+}
+
+// Visible returns the packages in the current view (matching the filter and within the selected layer).
+func (b *Browser) Visible() []pkg.Package {
+	return b.visible
+}
+
+// Selected returns the package under the cursor (nil when no package is visible).
+func (b *Browser) Selected() *pkg.Package {
+	if b.cursor < 0 || b.cursor >= len(b.visible) {
+		return nil
+	}
+	return &b.visible[b.cursor]
+}
+
+// refresh applies the filter and layer selection to the packages, keeping the cursor within the view.
+func (b *Browser) refresh() {
+	b.visible = b.visible[:0]
+	filter := strings.ToLower(b.filter)
+	for _, p := range b.packages {
+		if filter != "" && !matches(p, filter) {
+			continue
+		}
+		if b.layer >= 0 && !inLayer(p, b.sbom.Source.ImageMetadata.Layers[b.layer].Digest) {
+			continue
+		}
+		b.visible = append(b.visible, p)
+	}
+
+	if b.cursor >= len(b.visible) {
+		b.cursor = len(b.visible) - 1
+	}
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+}
+
+func matches(p pkg.Package, filter string) bool {
+	for _, value := range []string{p.Name, p.Version, string(p.Type), p.PURL} {
+		if strings.Contains(strings.ToLower(value), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+func inLayer(p pkg.Package, digest string) bool {
+	for _, l := range p.Locations.ToSlice() {
+		if l.FileSystemID == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle changes the state for a key press, returning true when the browser should quit.
+func (b *Browser) Handle(k Key) bool {
+	switch b.mode {
+	case filtering:
+		b.handleInput(k, func(value string) {
+			b.mode = browsing
+		}, func() {
+			b.filter = b.input
+			b.refresh()
+		})
+		return false
+	case exportingFormat:
+		b.handleInput(k, func(value string) {
+			b.format = value
+			b.mode = exportingPath
+			b.input = ""
+		}, nil)
+		return false
+	case exportingPath:
+		b.handleInput(k, func(value string) {
+			b.mode = browsing
+			b.exportView(b.format, value)
+		}, nil)
+		return false
+	}
+
+	b.message = ""
+	switch k {
+	case KeyCtrlC, "q":
+		return true
+	case "/":
+		b.mode = filtering
+		b.input = b.filter
+	case "e":
+		b.mode = exportingFormat
+		b.input = ""
+	case KeyTab:
+		if b.focus == packageList {
+			b.focus = layerList
+		} else {
+			b.focus = packageList
+		}
+	case KeyEscape:
+		// clear the filter and layer selection
+		b.filter, b.layer, b.layerCursor = "", -1, 0
+		b.refresh()
+	case KeyUp, "k":
+		b.move(-1)
+	case KeyDown, "j":
+		b.move(1)
+	case KeyPageUp:
+		b.move(-10)
+	case KeyPageDown:
+		b.move(10)
+	case KeyHome:
+		b.move(-len(b.packages) - len(b.sbom.Source.ImageMetadata.Layers) - 1)
+	case KeyEnd:
+		b.move(len(b.packages) + len(b.sbom.Source.ImageMetadata.Layers) + 1)
+	}
+	return false
+}
+
+// handleInput edits the prompt input, calling done with the input on enter and changed after every edit.
+func (b *Browser) handleInput(k Key, done func(string), changed func()) {
+	switch k {
+	case KeyEnter:
+		done(b.input)
+		return
+	case KeyEscape, KeyCtrlC:
+		b.mode = browsing
+		return
+	case KeyBackspace:
+		if r := []rune(b.input); len(r) > 0 {
+			b.input = string(r[:len(r)-1])
+		}
+	default:
+		if len([]rune(string(k))) != 1 {
+			return
+		}
+		b.input += string(k)
+	}
+	if changed != nil {
+		changed()
+	}
+}
+
+func (b *Browser) move(delta int) {
+	if b.focus == layerList {
+		b.layerCursor = clamp(b.layerCursor+delta, 0, len(b.sbom.Source.ImageMetadata.Layers))
+		b.layer = b.layerCursor - 1
+		b.refresh()
+		return
+	}
+	b.cursor = clamp(b.cursor+delta, 0, len(b.visible)-1)
+}
+
+func clamp(v, min, max int) int {
+	if v > max {
+		v = max
+	}
+	if v < min {
+		v = min
+	}
+	return v
+}
+
+// View returns an SBOM with only the packages in the current view, along with the relationships between them (and
+// their files).
+func (b *Browser) View() sbom.SBOM {
+	view := b.sbom
+	view.Artifacts.PackageCatalog = pkg.NewCatalog(b.visible...)
+
+	removed := make(map[artifact.ID]struct{})
+	for _, p := range b.packages {
+		removed[p.ID()] = struct{}{}
+	}
+	for _, p := range b.visible {
+		delete(removed, p.ID())
+	}
+
+	view.Relationships = nil
+	for _, r := range b.sbom.Relationships {
+		if isRemoved(r.From, removed) || isRemoved(r.To, removed) {
+			continue
+		}
+		view.Relationships = append(view.Relationships, r)
+	}
+	return view
+}
+
+func isRemoved(i artifact.Identifiable, removed map[artifact.ID]struct{}) bool {
+	if i == nil {
+		return false
+	}
+	_, ok := removed[i.ID()]
+	return ok
+}
+
+func (b *Browser) exportView(format, path string) {
+	if path == "" {
+		b.message = "export cancelled: no file given"
+		return
+	}
+	if err := b.export(b.View(), format, path); err != nil {
+		b.message = fmt.Sprintf("export failed: %v", err)
+		return
+	}
+	b.message = fmt.Sprintf("exported %d packages to %s (%s)", len(b.visible), path, format)
+}
+
+// details returns the lines of the detail pane for the selected package.
+func (b *Browser) details() []string {
+	p := b.Selected()
+	if p == nil {
+		return []string{"no packages"}
+	}
+	e := explain.Package(b.sbom, *p)
+
+	lines := []string{
+		fmt.Sprintf("%s %s", p.Name, p.Version),
+		"Type:      " + string(p.Type),
+		"Found by:  " + p.FoundBy,
+		"PURL:      " + valueOrNone(p.PURL),
+		"Licenses:  " + valueOrNone(strings.Join(p.Licenses, ", ")),
+		"",
+		"Locations:",
+	}
+	for _, l := range e.Locations {
+		if l.LayerIndex >= 0 {
+			lines = append(lines, fmt.Sprintf("  %s (layer %d)", l.Path, l.LayerIndex))
+			continue
+		}
+		lines = append(lines, "  "+l.Path)
+	}
+
+	if p.MetadataType != "" {
+		lines = append(lines, "", fmt.Sprintf("Metadata (%s):", p.MetadataType))
+		for _, f := range e.MetadataFields {
+			lines = append(lines, fmt.Sprintf("  %s: %s", f.Name, f.Value))
+		}
+	}
+
+	lines = append(lines, "", "Relationships:")
+	if len(e.Relationships) == 0 {
+		lines = append(lines, "  none")
+	}
+	for _, r := range e.Relationships {
+		arrow := "<-"
+		if r.Outgoing {
+			arrow = "->"
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s", arrow, r.Type, r.Other))
+	}
+	return lines
+}
+
+func valueOrNone(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return v
+}
@@ -0,0 +1,220 @@
+package browse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+func newSBOM() sbom.SBOM {
+	curl := pkg.Package{
+		Name:    "curl",
+		Version: "7.74.0",
+		Type:    pkg.DebPkg,
+		FoundBy: "dpkgdb-cataloger",
+		Locations: source.NewLocationSet(source.Location{
+			Coordinates: source.Coordinates{RealPath: "/var/lib/dpkg/status", FileSystemID: "sha256:layer0"},
+		}),
+		Licenses: []string{"curl"},
+	}
+	curl.SetID()
+
+	libcurl := pkg.Package{
+		Name:    "libcurl4",
+		Version: "7.74.0",
+		Type:    pkg.DebPkg,
+		Locations: source.NewLocationSet(source.Location{
+			Coordinates: source.Coordinates{RealPath: "/var/lib/dpkg/status", FileSystemID: "sha256:layer0"},
+		}),
+	}
+	libcurl.SetID()
+
+	express := pkg.Package{
+		Name:    "express",
+		Version: "4.18.1",
+		Type:    pkg.NpmPkg,
+		PURL:    "pkg:npm/express@4.18.1",
+		Locations: source.NewLocationSet(source.Location{
+			Coordinates: source.Coordinates{RealPath: "/app/package.json", FileSystemID: "sha256:layer1"},
+		}),
+	}
+	express.SetID()
+
+	return sbom.SBOM{
+		Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(curl, libcurl, express)},
+		Relationships: []artifact.Relationship{
+			{From: libcurl, To: curl, Type: artifact.DependencyOfRelationship},
+			{From: curl, To: source.Coordinates{RealPath: "/usr/bin/curl"}, Type: artifact.ContainsRelationship},
+		},
+		Source: source.Metadata{
+			Scheme: source.ImageScheme,
+			ImageMetadata: source.ImageMetadata{
+				Layers: []source.LayerMetadata{{Digest: "sha256:layer0"}, {Digest: "sha256:layer1"}},
+			},
+		},
+	}
+}
+
+func names(packages []pkg.Package) []string {
+	var result []string
+	for _, p := range packages {
+		result = append(result, p.Name)
+	}
+	return result
+}
+
+func press(b *Browser, keys ...Key) {
+	for _, k := range keys {
+		b.Handle(k)
+	}
+}
+
+func typeText(b *Browser, text string) {
+	for _, r := range text {
+		b.Handle(Key(r))
+	}
+}
+
+func TestBrowser_filter(t *testing.T) {
+	b := New(newSBOM(), "debian:latest", nil)
+	assert.Equal(t, []string{"curl", "express", "libcurl4"}, names(b.Visible()))
+
+	b.Handle("/")
+	typeText(b, "CURL")
+	assert.Equal(t, []string{"curl", "libcurl4"}, names(b.Visible()))
+
+	press(b, KeyBackspace, KeyBackspace, KeyBackspace, KeyBackspace)
+	typeText(b, "npm")
+	assert.Equal(t, []string{"express"}, names(b.Visible()))
+
+	// the filter stays after the prompt is closed and is cleared with escape
+	press(b, KeyEnter)
+	assert.Equal(t, []string{"express"}, names(b.Visible()))
+	press(b, KeyEscape)
+	assert.Len(t, b.Visible(), 3)
+}
+
+func TestBrowser_layers(t *testing.T) {
+	b := New(newSBOM(), "debian:latest", nil)
+
+	press(b, KeyTab, KeyDown)
+	assert.Equal(t, []string{"curl", "libcurl4"}, names(b.Visible()))
+	press(b, KeyDown)
+	assert.Equal(t, []string{"express"}, names(b.Visible()))
+	press(b, KeyHome)
+	assert.Len(t, b.Visible(), 3)
+}
+
+func TestBrowser_cursor(t *testing.T) {
+	b := New(newSBOM(), "debian:latest", nil)
+	assert.Equal(t, "curl", b.Selected().Name)
+
+	press(b, KeyDown, "j")
+	assert.Equal(t, "libcurl4", b.Selected().Name)
+	press(b, KeyDown)
+	assert.Equal(t, "libcurl4", b.Selected().Name)
+	press(b, "k")
+	assert.Equal(t, "express", b.Selected().Name)
+
+	// the cursor stays within the view when the view shrinks
+	press(b, KeyEnd, "/")
+	typeText(b, "express")
+	assert.Equal(t, "express", b.Selected().Name)
+
+	typeText(b, "x")
+	assert.Nil(t, b.Selected())
+}
+
+func TestBrowser_quit(t *testing.T) {
+	b := New(newSBOM(), "debian:latest", nil)
+	assert.True(t, b.Handle("q"))
+	assert.True(t, b.Handle(KeyCtrlC))
+
+	// "q" is part of the filter while filtering
+	b.Handle("/")
+	assert.False(t, b.Handle("q"))
+	assert.Empty(t, b.Visible())
+}
+
+func TestBrowser_export(t *testing.T) {
+	var exported sbom.SBOM
+	var format, path string
+	b := New(newSBOM(), "debian:latest", func(s sbom.SBOM, f, p string) error {
+		exported, format, path = s, f, p
+		return nil
+	})
+
+	b.Handle("/")
+	typeText(b, "curl")
+	press(b, KeyEnter, "e")
+	typeText(b, "spdx-json")
+	press(b, KeyEnter)
+	typeText(b, "curl.json")
+	press(b, KeyEnter)
+
+	assert.Equal(t, "spdx-json", format)
+	assert.Equal(t, "curl.json", path)
+	assert.Equal(t, []string{"curl", "libcurl4"}, names(exported.Artifacts.PackageCatalog.Sorted()))
+	assert.Len(t, exported.Relationships, 2)
+	assert.Equal(t, "exported 2 packages to curl.json (spdx-json)", b.message)
+
+	// relationships to packages outside of the view are dropped
+	b.Handle("/")
+	typeText(b, "4")
+	press(b, KeyEnter)
+	assert.Equal(t, []string{"libcurl4"}, names(b.Visible()))
+	assert.Empty(t, b.View().Relationships)
+
+	b.export = func(sbom.SBOM, string, string) error { return errors.New("unknown format") }
+	press(b, "e", KeyEnter)
+	typeText(b, "out")
+	press(b, KeyEnter)
+	assert.Equal(t, "export failed: unknown format", b.message)
+}
+
+func TestBrowser_Render(t *testing.T) {
+	b := New(newSBOM(), "debian:latest", nil)
+
+	lines := b.Render(100, 20)
+	require.Len(t, lines, 20)
+	assert.Contains(t, lines[0], "debian:latest  3/3 packages")
+	assert.Equal(t, help+strings.Repeat(" ", 100-len([]rune(help))), lines[19])
+
+	screen := strings.Join(lines, "\n")
+	assert.Contains(t, screen, "all layers")
+	assert.Contains(t, screen, "1 layer1")
+	assert.Contains(t, screen, "> curl 7.74.0 (deb)")
+	assert.Contains(t, screen, "Licenses:  curl")
+	assert.Contains(t, screen, "/var/lib/dpkg/status (layer 0)")
+	assert.Contains(t, screen, "-> contains file /usr/bin/curl")
+
+	for _, l := range lines[1:] {
+		l = strings.ReplaceAll(strings.ReplaceAll(l, reverse, ""), reset, "")
+		assert.Equal(t, 100, len([]rune(l)), "line %q", l)
+	}
+
+	// the list scrolls to keep the cursor in view
+	press(b, KeyEnd)
+	lines = b.Render(100, 4)
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[2], "> libcurl4")
+}
+
+func TestRun(t *testing.T) {
+	// a trailing escape (with nothing buffered after it) is the escape key
+	b := New(newSBOM(), "", nil)
+	var out strings.Builder
+	require.NoError(t, Run(strings.NewReader("/x\x7f\r\x1b[B\x1b[Bé\x1b"), &out, func() (int, int) { return 80, 10 }, b))
+
+	assert.Equal(t, "libcurl4", b.Selected().Name)
+	assert.Equal(t, "", b.filter)
+	assert.Equal(t, browsing, b.mode)
+}
@@ -0,0 +1,97 @@
+package browse
+
+import (
+	"bufio"
+	"unicode/utf8"
+)
+
+// Key is a single key press: either a printable character or one of the named keys below.
+type Key string
+
+const (
+	KeyUp        Key = "<up>"
+	KeyDown      Key = "<down>"
+	KeyPageUp    Key = "<pgup>"
+	KeyPageDown  Key = "<pgdn>"
+	KeyHome      Key = "<home>"
+	KeyEnd       Key = "<end>"
+	KeyTab       Key = "<tab>"
+	KeyEnter     Key = "<enter>"
+	KeyEscape    Key = "<esc>"
+	KeyBackspace Key = "<backspace>"
+	KeyCtrlC     Key = "<ctrl-c>"
+	keyUnknown   Key = ""
+)
+
+// escape sequences (after the escape byte) sent by terminals for the named keys
+var sequences = map[string]Key{
+	"[A":  KeyUp,
+	"OA":  KeyUp,
+	"[B":  KeyDown,
+	"OB":  KeyDown,
+	"[5~": KeyPageUp,
+	"[6~": KeyPageDown,
+	"[H":  KeyHome,
+	"OH":  KeyHome,
+	"[1~": KeyHome,
+	"[F":  KeyEnd,
+	"OF":  KeyEnd,
+	"[4~": KeyEnd,
+}
+
+// readKey reads a single key press from a terminal in raw mode. An escape byte is taken as the escape key unless the
+// rest of an escape sequence has already arrived with it.
+func readKey(r *bufio.Reader) (Key, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return keyUnknown, err
+	}
+
+	switch c {
+	case 0x1b:
+		if r.Buffered() == 0 {
+			return KeyEscape, nil
+		}
+		return readSequence(r)
+	case '\r', '\n':
+		return KeyEnter, nil
+	case '\t':
+		return KeyTab, nil
+	case 0x7f, 0x08:
+		return KeyBackspace, nil
+	case 0x03:
+		return KeyCtrlC, nil
+	}
+
+	if c < utf8.RuneSelf {
+		if c < 0x20 {
+			return keyUnknown, nil
+		}
+		return Key(c), nil
+	}
+
+	if err := r.UnreadByte(); err != nil {
+		return keyUnknown, err
+	}
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return keyUnknown, err
+	}
+	return Key(ch), nil
+}
+
+// readSequence reads the rest of an escape sequence, which ends with a letter or "~".
+func readSequence(r *bufio.Reader) (Key, error) {
+	var seq []byte
+	for r.Buffered() > 0 && len(seq) < 8 {
+		c, err := r.ReadByte()
+		if err != nil {
+			return keyUnknown, err
+		}
+		seq = append(seq, c)
+		if len(seq) > 1 && (c == '~' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')) {
+			break
+		}
+	}
+	return sequences[string(seq)], nil
+}
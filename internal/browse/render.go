@@ -0,0 +1,172 @@
+package browse
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	sidebarWidth = 24
+	separator    = "│"
+	reverse      = "\x1b[7m"
+	reset        = "\x1b[0m"
+	help         = "↑/↓ move  tab switch pane  / filter  esc clear  e export  q quit"
+)
+
+// Render returns the lines of the screen for the given terminal size, where every line is exactly the width of the
+// terminal (not counting the escape codes for highlighting).
+func (b *Browser) Render(width, height int) []string {
+	if width < 1 || height < 1 {
+		return nil
+	}
+
+	lines := []string{reverse + fit(b.header(), width) + reset}
+	if height == 1 {
+		return lines
+	}
+
+	bodyHeight := height - 2
+	if bodyHeight > 0 {
+		var columns [][]string
+		remaining := width
+
+		// the sidebar is only shown for images and when there is room for it
+		if len(b.sbom.Source.ImageMetadata.Layers) > 0 && width >= 3*sidebarWidth {
+			columns = append(columns, b.layerColumn(bodyHeight, sidebarWidth))
+			remaining -= sidebarWidth + 1
+		}
+
+		listWidth := remaining * 2 / 5
+		columns = append(columns,
+			b.packageColumn(bodyHeight, listWidth),
+			fitColumn(b.details(), bodyHeight, remaining-listWidth-1),
+		)
+
+		for row := 0; row < bodyHeight; row++ {
+			var cells []string
+			for _, column := range columns {
+				cells = append(cells, column[row])
+			}
+			lines = append(lines, strings.Join(cells, separator))
+		}
+	}
+
+	return append(lines, fit(b.footer(), width))
+}
+
+func (b *Browser) header() string {
+	header := fmt.Sprintf(" %s  %d/%d packages", b.title, len(b.visible), len(b.packages))
+	if b.filter != "" {
+		header += fmt.Sprintf("  filter: %q", b.filter)
+	}
+	if b.layer >= 0 {
+		header += fmt.Sprintf("  layer: %d", b.layer)
+	}
+	return header
+}
+
+func (b *Browser) footer() string {
+	switch b.mode {
+	case filtering:
+		return "/" + b.input
+	case exportingFormat:
+		return "export format (e.g. spdx-json, cyclonedx-json): " + b.input
+	case exportingPath:
+		return fmt.Sprintf("export %s to file: %s", b.format, b.input)
+	}
+	if b.message != "" {
+		return b.message
+	}
+	return help
+}
+
+// packageColumn renders the package list, scrolling to keep the cursor in view.
+func (b *Browser) packageColumn(height, width int) []string {
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+height {
+		b.offset = b.cursor - height + 1
+	}
+
+	var rows []string
+	for i := b.offset; i < len(b.visible) && len(rows) < height; i++ {
+		p := b.visible[i]
+		rows = append(rows, b.row(fmt.Sprintf("%s %s (%s)", p.Name, p.Version, p.Type), i == b.cursor, b.focus == packageList, width))
+	}
+	if len(b.visible) == 0 {
+		rows = append(rows, fit(" no matching packages", width))
+	}
+	return pad(rows, height, width)
+}
+
+// layerColumn renders the layer sidebar, where the first row selects all layers.
+func (b *Browser) layerColumn(height, width int) []string {
+	offset := 0
+	if b.layerCursor >= height {
+		offset = b.layerCursor - height + 1
+	}
+
+	var rows []string
+	for i := offset; i <= len(b.sbom.Source.ImageMetadata.Layers) && len(rows) < height; i++ {
+		label := "all layers"
+		if i > 0 {
+			label = fmt.Sprintf("%d %s", i-1, shortDigest(b.sbom.Source.ImageMetadata.Layers[i-1].Digest))
+		}
+		rows = append(rows, b.row(label, i == b.layerCursor, b.focus == layerList, width))
+	}
+	return pad(rows, height, width)
+}
+
+// row renders a single list row, marking the cursor (and highlighting it when the list has the focus).
+func (b *Browser) row(label string, cursor, focused bool, width int) string {
+	if !cursor {
+		return fit("  "+label, width)
+	}
+	row := fit("> "+label, width)
+	if focused {
+		return reverse + row + reset
+	}
+	return row
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func fitColumn(lines []string, height, width int) []string {
+	var rows []string
+	for _, l := range lines {
+		if len(rows) == height {
+			break
+		}
+		rows = append(rows, fit(" "+l, width))
+	}
+	return pad(rows, height, width)
+}
+
+func pad(rows []string, height, width int) []string {
+	for len(rows) < height {
+		rows = append(rows, strings.Repeat(" ", width))
+	}
+	return rows
+}
+
+// fit truncates or pads the string to exactly the given width (in runes).
+func fit(s string, width int) string {
+	if width < 1 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(r))
+}
@@ -0,0 +1,75 @@
+package browse
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l" // switch to the alternate screen and hide the cursor
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l" // show the cursor and switch back to the main screen
+	cursorHome     = "\x1b[H"
+
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// Run draws the browser and handles key presses read from the input until the browser quits or the input ends. The
+// size of the screen is requested before every redraw, so that resizing the terminal takes effect with the next key.
+func Run(in io.Reader, out io.Writer, size func() (int, int), b *Browser) error {
+	reader := bufio.NewReader(in)
+	for {
+		if err := draw(out, b.Render(size())); err != nil {
+			return err
+		}
+
+		key, err := readKey(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read key: %w", err)
+		}
+		if b.Handle(key) {
+			return nil
+		}
+	}
+}
+
+// RunTerminal runs the browser full screen on the given terminal, restoring the terminal when the browser quits.
+func RunTerminal(in, out *os.File, b *Browser) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("unable to set the terminal to raw mode: %w", err)
+	}
+	defer func() {
+		_ = term.Restore(int(in.Fd()), state)
+	}()
+
+	if _, err := io.WriteString(out, enterAltScreen); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.WriteString(out, exitAltScreen)
+	}()
+
+	return Run(in, out, func() (int, int) {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil || width < 1 || height < 1 {
+			return defaultWidth, defaultHeight
+		}
+		return width, height
+	}, b)
+}
+
+// draw writes the lines over the whole screen (in raw mode, so every line break must also return the cursor).
+func draw(out io.Writer, lines []string) error {
+	_, err := io.WriteString(out, cursorHome+strings.Join(lines, "\r\n"))
+	return err
+}
@@ -0,0 +1,82 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package browse
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// screen collects everything written to the terminal.
+type screen struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *screen) String() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.buf.String()
+}
+
+func (s *screen) waitFor(t *testing.T, text string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return strings.Contains(s.String(), text)
+	}, 5*time.Second, 10*time.Millisecond, "the terminal never showed %q:\n%s", text, s.String())
+}
+
+func TestRunTerminal(t *testing.T) {
+	ptmx, tty, err := pty.Open()
+	require.NoError(t, err)
+	defer ptmx.Close()
+	require.NoError(t, pty.Setsize(tty, &pty.Winsize{Rows: 20, Cols: 100}))
+
+	out := &screen{}
+	go func() {
+		_, _ = io.Copy(out, ptmx)
+	}()
+
+	done := make(chan error)
+	b := New(newSBOM(), "debian:latest", nil)
+	go func() {
+		done <- RunTerminal(tty, tty, b)
+	}()
+
+	out.waitFor(t, "3/3 packages")
+	assert.Contains(t, out.String(), enterAltScreen)
+
+	_, err = ptmx.WriteString("/express\r")
+	require.NoError(t, err)
+	out.waitFor(t, `1/3 packages  filter: "express"`)
+	out.waitFor(t, "PURL:      pkg:npm/express@4.18.1")
+
+	_, err = ptmx.WriteString("q")
+	require.NoError(t, err)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the browser did not quit")
+	}
+	out.waitFor(t, exitAltScreen)
+
+	// input is not echoed in raw mode
+	assert.NotContains(t, out.String(), "/express\r\n")
+	require.NoError(t, tty.Close())
+}
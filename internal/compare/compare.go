@@ -0,0 +1,123 @@
+/*
+Package compare reports the discrepancies between the packages of two SBOMs for the same image (e.g. an SBOM attached to
+the image in its registry and a freshly generated SBOM).
+*/
+package compare
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/table"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// Difference is a package that is not found with the same versions in both SBOMs.
+type Difference struct {
+	Name      string
+	Type      pkg.Type
+	Attached  []string // the versions in the attached SBOM (empty when the package is only in the generated SBOM)
+	Generated []string // the versions in the generated SBOM (empty when the package is only in the attached SBOM)
+}
+
+// Result is the outcome of comparing an attached SBOM to a generated SBOM.
+type Result struct {
+	Attached          string // a description of where the attached SBOM was found
+	AttachedPackages  int
+	GeneratedPackages int
+	Differences       []Difference
+}
+
+type key struct {
+	name string
+	typ  pkg.Type
+}
+
+// Packages compares the packages of the attached SBOM with the packages of the generated SBOM, where packages are
+// identified by type and (case-insensitive) name.
+func Packages(description string, attached, generated sbom.SBOM) Result {
+	attachedVersions := versions(attached)
+	generatedVersions := versions(generated)
+
+	keys := make(map[key]struct{})
+	for k := range attachedVersions {
+		keys[k] = struct{}{}
+	}
+	for k := range generatedVersions {
+		keys[k] = struct{}{}
+	}
+
+	result := Result{
+		Attached:          description,
+		AttachedPackages:  attached.Artifacts.PackageCatalog.PackageCount(),
+		GeneratedPackages: generated.Artifacts.PackageCatalog.PackageCount(),
+	}
+	for k := range keys {
+		a, g := attachedVersions[k], generatedVersions[k]
+		if strings.Join(a, ",") == strings.Join(g, ",") {
+			continue
+		}
+		result.Differences = append(result.Differences, Difference{Name: k.name, Type: k.typ, Attached: a, Generated: g})
+	}
+
+	sort.Slice(result.Differences, func(i, j int) bool {
+		a, b := result.Differences[i], result.Differences[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Type < b.Type
+	})
+	return result
+}
+
+// versions returns the sorted distinct versions of each package in the SBOM.
+func versions(s sbom.SBOM) map[key][]string {
+	seen := make(map[key]map[string]struct{})
+	for p := range s.Artifacts.PackageCatalog.Enumerate() {
+		k := key{name: strings.ToLower(p.Name), typ: p.Type}
+		if seen[k] == nil {
+			seen[k] = make(map[string]struct{})
+		}
+		seen[k][p.Version] = struct{}{}
+	}
+
+	result := make(map[key][]string, len(seen))
+	for k, vs := range seen {
+		for v := range vs {
+			result[k] = append(result[k], v)
+		}
+		sort.Strings(result[k])
+	}
+	return result
+}
+
+// Write renders the comparison as a summary followed by a table of the discrepancies.
+func (r Result) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Compared the attached SBOM (%s, %d packages) with a generated SBOM (%d packages): ", r.Attached, r.AttachedPackages, r.GeneratedPackages); err != nil {
+		return err
+	}
+	if len(r.Differences) == 0 {
+		_, err := fmt.Fprintln(w, "no discrepancies")
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%d discrepancies\n", len(r.Differences)); err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(r.Differences))
+	for _, d := range r.Differences {
+		rows = append(rows, []string{d.Name, string(d.Type), orNone(d.Attached), orNone(d.Generated)})
+	}
+	return table.Write(w, []string{"Name", "Type", "Attached", "Generated"}, rows)
+}
+
+func orNone(versions []string) string {
+	if len(versions) == 0 {
+		return "(none)"
+	}
+	return strings.Join(versions, ", ")
+}
@@ -0,0 +1,58 @@
+package compare
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+func newSBOM(packages ...pkg.Package) sbom.SBOM {
+	for i := range packages {
+		packages[i].SetID()
+	}
+	return sbom.SBOM{Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(packages...)}}
+}
+
+func TestPackages(t *testing.T) {
+	attached := newSBOM(
+		pkg.Package{Name: "curl", Version: "7.74.0", Type: pkg.DebPkg},
+		pkg.Package{Name: "zlib1g", Version: "1.2.11", Type: pkg.DebPkg},
+		pkg.Package{Name: "Lodash", Version: "4.17.20", Type: pkg.NpmPkg},
+		pkg.Package{Name: "removed", Version: "1.0", Type: pkg.DebPkg},
+	)
+	generated := newSBOM(
+		pkg.Package{Name: "curl", Version: "7.74.0", Type: pkg.DebPkg},
+		pkg.Package{Name: "zlib1g", Version: "1.2.13", Type: pkg.DebPkg},
+		pkg.Package{Name: "lodash", Version: "4.17.20", Type: pkg.NpmPkg},
+		pkg.Package{Name: "added", Version: "2.0", Type: pkg.PythonPkg},
+	)
+
+	result := Packages("spdx-json from example.com/app@sha256:abc", attached, generated)
+	assert.Equal(t, 4, result.AttachedPackages)
+	assert.Equal(t, 4, result.GeneratedPackages)
+	assert.Equal(t, []Difference{
+		{Name: "added", Type: pkg.PythonPkg, Generated: []string{"2.0"}},
+		{Name: "removed", Type: pkg.DebPkg, Attached: []string{"1.0"}},
+		{Name: "zlib1g", Type: pkg.DebPkg, Attached: []string{"1.2.11"}, Generated: []string{"1.2.13"}},
+	}, result.Differences)
+
+	var buf bytes.Buffer
+	require.NoError(t, result.Write(&buf))
+	assert.Contains(t, buf.String(), "3 discrepancies")
+	assert.Contains(t, buf.String(), "(none)")
+}
+
+func TestPackages_noDifferences(t *testing.T) {
+	s := newSBOM(pkg.Package{Name: "curl", Version: "7.74.0", Type: pkg.DebPkg})
+	result := Packages("syft-json from example.com/app@sha256:abc", s, s)
+	assert.Empty(t, result.Differences)
+
+	var buf bytes.Buffer
+	require.NoError(t, result.Write(&buf))
+	assert.Contains(t, buf.String(), "no discrepancies")
+}
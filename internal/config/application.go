@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -20,14 +21,34 @@ type parser interface {
 
 // Application is the main syft application configuration.
 type Application struct {
-	Package    pkg      `yaml:"package" json:"package" mapstructure:"package"`    // package cataloging related options
-	Exclusions []string `yaml:"exclude" json:"exclude" mapstructure:"exclude"`    // --exclude, ignore paths within an image
-	Platform   string   `yaml:"platform" json:"platform" mapstructure:"platform"` // --platform, override OS and architecture from image
-	Output     string   `yaml:"output" json:"output" mapstructure:"output"`       // --output, the file to write report output to
-	Format     string   `yaml:"format" json:"format" mapstructure:"format"`       // --format, the format to use for output
-	Quiet      bool     `yaml:"quiet" json:"quiet" mapstructure:"quiet"`          // -q, indicates to not show any status output to stderr (ETUI or logging UI)
-	Log        logging  `yaml:"log" json:"log" mapstructure:"log"`                // all logging-related options
-	Debug      bool     `yaml:"debug" json:"debug" mapstructure:"debug"`          // -D/--debug, enable debug logging
+	Package       pkg           `yaml:"package" json:"package" mapstructure:"package"`                               // package cataloging related options
+	Exclusions    []string      `yaml:"exclude" json:"exclude" mapstructure:"exclude"`                               // --exclude, ignore paths within an image
+	Platform      string        `yaml:"platform" json:"platform" mapstructure:"platform"`                            // --platform, override OS and architecture from image
+	EnvDenylist   []string      `yaml:"env-denylist" json:"env-denylist" mapstructure:"env-denylist"`                // --env-denylist, patterns of image environment variables to leave out of the SBOM
+	Base          string        `yaml:"base" json:"base" mapstructure:"base"`                                        // --base, the base image to tell inherited packages apart from application packages ("auto" to detect it)
+	OnlyApp       bool          `yaml:"only-app-packages" json:"only-app-packages" mapstructure:"only-app-packages"` // --only-app-packages, leave the packages of the base image out of the SBOM
+	Output        string        `yaml:"output" json:"output" mapstructure:"output"`                                  // --output, the file to write report output to
+	Format        string        `yaml:"format" json:"format" mapstructure:"format"`                                  // --format, the format to use for output
+	Quiet         bool          `yaml:"quiet" json:"quiet" mapstructure:"quiet"`                                     // -q, indicates to not show any status output to stderr (ETUI or logging UI)
+	AllLocal      bool          `yaml:"all-local" json:"all-local" mapstructure:"all-local"`                         // --all-local, inventory every image in the local daemon
+	LicensePolicy string        `yaml:"license-policy" json:"license-policy" mapstructure:"license-policy"`          // --license-policy, the license policy file to check packages against
+	PackagePolicy string        `yaml:"package-policy" json:"package-policy" mapstructure:"package-policy"`          // --package-policy, the package policy file with denied packages
+	SARIFOutput   string        `yaml:"sarif-output" json:"sarif-output" mapstructure:"sarif-output"`                // --sarif-output, the file to write package policy violations to (as SARIF)
+	VulnDB        string        `yaml:"vuln-db" json:"vuln-db" mapstructure:"vuln-db"`                               // --vuln-db, the directory of OSV records to match packages against
+	VEX           []string      `yaml:"vex" json:"vex" mapstructure:"vex"`                                           // --vex, VEX documents with triage decisions for the vulnerability matches
+	VEXOutput     string        `yaml:"vex-output" json:"vex-output" mapstructure:"vex-output"`                      // --vex-output, the file to write an OpenVEX document for the vulnerability matches to
+	FailOn        string        `yaml:"fail-on-severity" json:"fail-on-severity" mapstructure:"fail-on-severity"`    // --fail-on-severity, fail when a vulnerability of this severity (or higher) is found
+	Attest        bool          `yaml:"attest" json:"attest" mapstructure:"attest"`                                  // --attest, write the SBOM as a signed in-toto attestation
+	Key           string        `yaml:"key" json:"key" mapstructure:"key"`                                           // --key, the private key to sign attestations with
+	Push          bool          `yaml:"push" json:"push" mapstructure:"push"`                                        // --push, attach the SBOM to the image in its registry
+	UseAttached   string        `yaml:"use-attached" json:"use-attached" mapstructure:"use-attached"`                // --use-attached, use an SBOM attached to the image instead of generating one ("never", "prefer", or "only")
+	Compare       bool          `yaml:"compare" json:"compare" mapstructure:"compare"`                               // --compare, report discrepancies between the attached SBOM and a generated SBOM
+	Progress      string        `yaml:"progress" json:"progress" mapstructure:"progress"`                            // --progress, how to show progress ("auto", "plain", or "json")
+	Timeout       time.Duration `yaml:"timeout" json:"timeout" mapstructure:"timeout"`                               // --timeout, stop fetching and cataloging the image after this duration (0 is no timeout)
+	Interactive   bool          `yaml:"interactive" json:"interactive" mapstructure:"interactive"`                   // --interactive, browse the packages in a full-screen terminal UI after cataloging
+	ErrorFormat   string        `yaml:"error-format" json:"error-format" mapstructure:"error-format"`                // --error-format, how to report a failure ("text" or "json")
+	Log           logging       `yaml:"log" json:"log" mapstructure:"log"`                                           // all logging-related options
+	Debug         bool          `yaml:"debug" json:"debug" mapstructure:"debug"`                                     // -D/--debug, enable debug logging
 }
 
 func newApplicationConfig(v *viper.Viper) *Application {
@@ -110,6 +131,25 @@ func (cfg *Application) parseLogLevelOption() error {
 		cfg.Log.LevelOpt = logrus.WarnLevel
 	}
 
+	// libraries log at the application log level unless given their own (quiet is still quiet)
+	cfg.Log.LibraryLevelOpts = make(map[string]logrus.Level)
+	for _, lib := range LogLibraries {
+		cfg.Log.LibraryLevelOpts[lib] = cfg.Log.LevelOpt
+	}
+	for _, libraryLevel := range cfg.Log.LibraryLevels {
+		lib, level, ok := strings.Cut(libraryLevel, "=")
+		if _, known := cfg.Log.LibraryLevelOpts[lib]; !ok || !known {
+			return fmt.Errorf("bad library log level %q: must be LIBRARY=LEVEL where the library is one of %v", libraryLevel, LogLibraries)
+		}
+		lvl, err := logrus.ParseLevel(strings.ToLower(level))
+		if err != nil {
+			return fmt.Errorf("bad log level configured for %s (%q): %w", lib, level, err)
+		}
+		if !cfg.Quiet {
+			cfg.Log.LibraryLevelOpts[lib] = lvl
+		}
+	}
+
 	return nil
 }
 
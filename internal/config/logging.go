@@ -1,19 +1,65 @@
 package config
 
 import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
+// Modes for opening an existing log file.
+const (
+	TruncateLogFile = "truncate"
+	AppendLogFile   = "append"
+)
+
+// LogFileModes are all modes for opening an existing log file.
+var LogFileModes = []string{TruncateLogFile, AppendLogFile}
+
+// LogLibraries are the libraries that can be given their own log level, by the "from-lib" field of their log entries.
+var LogLibraries = []string{"syft", "stereoscope"}
+
 // logging contains all logging-related configuration options available to the user via the application config.
 type logging struct {
-	Structured   bool         `yaml:"structured" json:"structured" mapstructure:"structured"` // show all log entries as JSON formatted strings
-	LevelOpt     logrus.Level `yaml:"-" json:"-"`                                             // the native log level object used by the logger
-	Level        string       `yaml:"level" json:"level" mapstructure:"level"`                // the log level string hint
-	FileLocation string       `yaml:"file" json:"file-location" mapstructure:"file"`          // the file path to write logs to
+	Structured       bool                    `yaml:"structured" json:"structured" mapstructure:"structured"`             // show all log entries as JSON formatted strings
+	LevelOpt         logrus.Level            `yaml:"-" json:"-"`                                                         // the native log level object used by the logger
+	Level            string                  `yaml:"level" json:"level" mapstructure:"level"`                            // the log level string hint
+	FileLocation     string                  `yaml:"file" json:"file-location" mapstructure:"file"`                      // the file path to write logs to
+	FileMode         string                  `yaml:"file-mode" json:"file-mode" mapstructure:"file-mode"`                // how to open an existing log file ("truncate" or "append")
+	MaxSize          string                  `yaml:"max-size" json:"max-size" mapstructure:"max-size"`                   // rotate the log file when it would exceed this size (e.g. "10MB", empty for no rotation)
+	MaxSizeOpt       uint64                  `yaml:"-" json:"-"`                                                         // the parsed maximum size of the log file in bytes (0 for no rotation)
+	MaxBackups       int                     `yaml:"max-backups" json:"max-backups" mapstructure:"max-backups"`          // the number of rotated log files to keep
+	LibraryLevels    []string                `yaml:"library-levels" json:"library-levels" mapstructure:"library-levels"` // the log levels of libraries as LIBRARY=LEVEL (e.g. "stereoscope=error")
+	LibraryLevelOpts map[string]logrus.Level `yaml:"-" json:"-"`                                                         // the native log level of every library (the log level when not given)
 }
 
 func (cfg logging) loadDefaultValues(v *viper.Viper) {
 	v.SetDefault("log.structured", false)
 	v.SetDefault("log.file", "")
+	v.SetDefault("log.file-mode", TruncateLogFile)
+	v.SetDefault("log.max-size", "")
+	v.SetDefault("log.max-backups", 3)
+}
+
+func (cfg *logging) parseConfigValues() error {
+	switch cfg.FileMode {
+	case TruncateLogFile, AppendLogFile:
+	default:
+		return fmt.Errorf("bad log file mode %q (options=%v)", cfg.FileMode, LogFileModes)
+	}
+
+	cfg.MaxSizeOpt = 0
+	if cfg.MaxSize != "" {
+		size, err := humanize.ParseBytes(cfg.MaxSize)
+		if err != nil {
+			return fmt.Errorf("bad maximum log file size %q: %w", cfg.MaxSize, err)
+		}
+		cfg.MaxSizeOpt = size
+	}
+
+	if cfg.MaxBackups < 0 {
+		return fmt.Errorf("bad number of rotated log files to keep: %d", cfg.MaxBackups)
+	}
+	return nil
 }
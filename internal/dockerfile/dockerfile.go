@@ -0,0 +1,336 @@
+/*
+Package dockerfile reads the build stages of a Dockerfile: the base of every stage (FROM) with the build args resolved,
+so that the base images can be cataloged before anything is built.
+*/
+package dockerfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// the base of a stage that starts from an empty filesystem
+const scratch = "scratch"
+
+var directiveExpr = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+
+// heredocExpr matches a heredoc of an instruction (e.g. "<<EOF", "<<-EOF", or "<<'EOF'"), where the body follows the
+// instruction up to a line with only the delimiter.
+var heredocExpr = regexp.MustCompile(`<<(-?)(["']?)([a-zA-Z_][a-zA-Z0-9_]*)(["']?)`)
+
+// heredocKeywords are the instructions that can have heredocs.
+var heredocKeywords = map[string]struct{}{
+	"run":  {},
+	"copy": {},
+	"add":  {},
+}
+
+// Stage is a build stage of a Dockerfile.
+type Stage struct {
+	Index     int    // the position of the stage in the Dockerfile (from 0)
+	Name      string // the name given to the stage with "AS" (optional)
+	From      string // the base of the stage with the build args resolved (an image reference, "scratch", or an earlier stage)
+	Platform  string // the --platform of the FROM instruction with the build args resolved (optional)
+	Line      int    // the line of the FROM instruction
+	BaseStage int    // the index of the earlier stage the stage is based on (-1 when the stage is not based on a stage)
+}
+
+// Scratch indicates if the stage starts from an empty filesystem.
+func (s Stage) Scratch() bool {
+	return s.BaseStage < 0 && strings.EqualFold(s.From, scratch)
+}
+
+// External indicates if the stage is based on an image (instead of scratch or an earlier stage).
+func (s Stage) External() bool {
+	return s.BaseStage < 0 && !s.Scratch()
+}
+
+// String describes the stage by its index and name (e.g. "stage 1 (build)").
+func (s Stage) String() string {
+	if s.Name == "" {
+		return fmt.Sprintf("stage %d", s.Index)
+	}
+	return fmt.Sprintf("stage %d (%s)", s.Index, s.Name)
+}
+
+// instruction is a single (logical) instruction of a Dockerfile, with line continuations joined.
+type instruction struct {
+	keyword string // the lowercase instruction keyword (e.g. "from")
+	args    string // everything after the keyword
+	line    int    // the line the instruction starts at
+}
+
+// heredoc is a heredoc body that follows an instruction.
+type heredoc struct {
+	delimiter string
+	stripTabs bool // leading tabs of the body lines are removed ("<<-"), also before the delimiter
+	line      int  // the line of the instruction
+}
+
+// heredocs returns the heredocs of the instruction, in the order their bodies follow the instruction.
+func (i instruction) heredocs() []heredoc {
+	if _, ok := heredocKeywords[i.keyword]; !ok {
+		return nil
+	}
+	var result []heredoc
+	for _, match := range heredocExpr.FindAllStringSubmatch(i.args, -1) {
+		if match[2] != match[4] {
+			// unbalanced quotes are not a heredoc
+			continue
+		}
+		result = append(result, heredoc{delimiter: match[3], stripTabs: match[1] == "-", line: i.line})
+	}
+	return result
+}
+
+// Parse reads the stages of the Dockerfile. The build args override the defaults of the ARG instructions before the
+// first FROM (the only ARG instructions that FROM instructions can refer to), where build args that are not declared
+// by an ARG instruction are ignored (as docker build does).
+func Parse(r io.Reader, buildArgs map[string]string) ([]Stage, error) {
+	instructions, escape, err := readInstructions(r)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make(map[string]string)
+	var stages []Stage
+	for _, inst := range instructions {
+		switch inst.keyword {
+		case "arg":
+			if len(stages) > 0 {
+				// args declared within a stage are not available to FROM instructions
+				continue
+			}
+			if err := declareArgs(args, inst.args, escape, buildArgs); err != nil {
+				return nil, fmt.Errorf("line %d: %w", inst.line, err)
+			}
+		case "from":
+			stage, err := parseFrom(inst, escape, args, stages)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", inst.line, err)
+			}
+			stages = append(stages, *stage)
+		default:
+			if len(stages) == 0 {
+				return nil, fmt.Errorf("line %d: %s instruction before the first FROM", inst.line, strings.ToUpper(inst.keyword))
+			}
+		}
+	}
+
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("no FROM instruction found")
+	}
+	return stages, nil
+}
+
+// readInstructions returns all instructions of the Dockerfile along with the escape character (from the parser
+// directives at the top of the file, "\" by default).
+func readInstructions(r io.Reader) ([]instruction, rune, error) {
+	escape := '\\'
+	directives := true
+
+	var instructions []instruction
+	var current *instruction
+	var bodies []heredoc
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+
+		// heredoc bodies are skipped, since a body line is not an instruction (even when it looks like one)
+		if len(bodies) > 0 {
+			if bodies[0].stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == bodies[0].delimiter {
+				bodies = bodies[1:]
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+
+		if directives {
+			if match := directiveExpr.FindStringSubmatch(trimmed); match != nil {
+				if strings.EqualFold(match[1], "escape") {
+					switch match[2] {
+					case "\\", "`":
+						escape = rune(match[2][0])
+					default:
+						return nil, escape, fmt.Errorf("line %d: invalid escape character %q", lineNumber, match[2])
+					}
+				}
+				continue
+			}
+			directives = false
+		}
+
+		// comments and empty lines are skipped, also within continued instructions
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		continued := strings.HasSuffix(trimmed, string(escape))
+		if continued {
+			trimmed = strings.TrimSpace(strings.TrimSuffix(trimmed, string(escape)))
+		}
+
+		if current == nil {
+			// the keyword is separated from the arguments by any whitespace (e.g. a tab)
+			keyword, args := trimmed, ""
+			if idx := strings.IndexFunc(trimmed, unicode.IsSpace); idx >= 0 {
+				keyword, args = trimmed[:idx], strings.TrimSpace(trimmed[idx:])
+			}
+			current = &instruction{keyword: strings.ToLower(keyword), args: args, line: lineNumber}
+		} else if trimmed != "" {
+			current.args = strings.TrimSpace(current.args + " " + trimmed)
+		}
+
+		if !continued {
+			instructions = append(instructions, *current)
+			bodies = current.heredocs()
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, escape, fmt.Errorf("unable to read Dockerfile: %w", err)
+	}
+	if len(bodies) > 0 {
+		return nil, escape, fmt.Errorf("line %d: unterminated heredoc (missing %q)", bodies[0].line, bodies[0].delimiter)
+	}
+	if current != nil {
+		instructions = append(instructions, *current)
+	}
+	return instructions, escape, nil
+}
+
+// declareArgs declares the args of an ARG instruction (e.g. "VERSION=1.0 VARIANT"), where build args take precedence
+// over the defaults and args without either are empty.
+func declareArgs(args map[string]string, declaration string, escape rune, buildArgs map[string]string) error {
+	words := splitWords(declaration, escape)
+	if len(words) == 0 {
+		return fmt.Errorf("ARG requires at least one argument")
+	}
+	for _, word := range words {
+		name, value, hasDefault := strings.Cut(word, "=")
+		if name == "" {
+			return fmt.Errorf("invalid ARG %q", word)
+		}
+		if v, ok := buildArgs[name]; ok {
+			args[name] = v
+			continue
+		}
+		if !hasDefault {
+			args[name] = ""
+			continue
+		}
+		expanded, err := expand(value, escape, args)
+		if err != nil {
+			return fmt.Errorf("invalid ARG %q: %w", word, err)
+		}
+		args[name] = expanded
+	}
+	return nil
+}
+
+// parseFrom reads a FROM instruction ("FROM [--platform=<platform>] <image> [AS <name>]").
+func parseFrom(inst instruction, escape rune, args map[string]string, earlier []Stage) (*Stage, error) {
+	words := splitWords(inst.args, escape)
+
+	var platform string
+	for len(words) > 0 && strings.HasPrefix(words[0], "--") {
+		name, value, _ := strings.Cut(strings.TrimPrefix(words[0], "--"), "=")
+		if name != "platform" {
+			return nil, fmt.Errorf("unknown FROM flag %q", words[0])
+		}
+		platform = value
+		words = words[1:]
+	}
+
+	var name string
+	switch {
+	case len(words) == 1:
+	case len(words) == 3 && strings.EqualFold(words[1], "as"):
+		name = strings.ToLower(words[2])
+	default:
+		return nil, fmt.Errorf("FROM requires either one or three arguments (got %q)", inst.args)
+	}
+
+	from, err := expand(words[0], escape, args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FROM %q: %w", words[0], err)
+	}
+	if from == "" {
+		return nil, fmt.Errorf("FROM %q resolves to an empty base (is a build arg missing?)", words[0])
+	}
+
+	platform, err = expand(platform, escape, args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FROM platform %q: %w", platform, err)
+	}
+
+	stage := &Stage{
+		Index:     len(earlier),
+		Name:      name,
+		From:      from,
+		Platform:  platform,
+		Line:      inst.line,
+		BaseStage: baseStage(from, earlier),
+	}
+	return stage, nil
+}
+
+// baseStage returns the index of the earlier stage that is referred to by name or index (-1 if there is none).
+func baseStage(from string, earlier []Stage) int {
+	for _, s := range earlier {
+		if s.Name != "" && strings.EqualFold(s.Name, from) {
+			return s.Index
+		}
+	}
+	if idx, err := strconv.Atoi(from); err == nil && idx >= 0 && idx < len(earlier) {
+		return idx
+	}
+	return -1
+}
+
+// splitWords splits the arguments of an instruction on whitespace, keeping quoted strings and escaped characters
+// within a single word (the quotes and escapes are kept, to be processed by expand).
+func splitWords(s string, escape rune) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == escape && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+			continue
+		}
+		word.WriteRune(r)
+		inWord = true
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
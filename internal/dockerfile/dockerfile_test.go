@@ -0,0 +1,202 @@
+package dockerfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		buildArgs  map[string]string
+		want       []Stage
+	}{
+		{
+			name:       "single stage",
+			dockerfile: "FROM alpine:3.16\nRUN apk add curl\n",
+			want: []Stage{
+				{Index: 0, From: "alpine:3.16", Line: 1, BaseStage: -1},
+			},
+		},
+		{
+			name: "stages based on earlier stages",
+			dockerfile: `# syntax=docker/dockerfile:1
+from golang:1.18 as Build
+RUN go build ./...
+
+FROM build AS test
+RUN go test ./...
+
+FROM 0
+FROM --platform=linux/arm64 gcr.io/distroless/static AS final
+COPY --from=build /app /app
+`,
+			want: []Stage{
+				{Index: 0, Name: "build", From: "golang:1.18", Line: 2, BaseStage: -1},
+				{Index: 1, Name: "test", From: "build", Line: 5, BaseStage: 0},
+				{Index: 2, From: "0", Line: 8, BaseStage: 0},
+				{Index: 3, Name: "final", From: "gcr.io/distroless/static", Platform: "linux/arm64", Line: 9, BaseStage: -1},
+			},
+		},
+		{
+			name: "build args",
+			dockerfile: `ARG REGISTRY=docker.io VARIANT
+ARG GO_VERSION=1.18
+ARG BASE="${REGISTRY}/library/alpine:${ALPINE_VERSION:-3.16}"
+ARG PLATFORM
+FROM --platform=$PLATFORM golang:${GO_VERSION}-${VARIANT:-bullseye} AS build
+ARG GO_VERSION=1.17
+FROM ${BASE}
+`,
+			buildArgs: map[string]string{"VARIANT": "alpine", "PLATFORM": "linux/amd64", "UNUSED": "x"},
+			want: []Stage{
+				{Index: 0, Name: "build", From: "golang:1.18-alpine", Platform: "linux/amd64", Line: 5, BaseStage: -1},
+				{Index: 1, From: "docker.io/library/alpine:3.16", Line: 7, BaseStage: -1},
+			},
+		},
+		{
+			name:       "undeclared build args are ignored",
+			dockerfile: "FROM alpine:${VERSION:-3.16}\n",
+			buildArgs:  map[string]string{"VERSION": "3.15"},
+			want: []Stage{
+				{Index: 0, From: "alpine:3.16", Line: 1, BaseStage: -1},
+			},
+		},
+		{
+			name: "line continuations and comments",
+			dockerfile: `FROM \
+  # the base image
+  alpine:3.16 \
+  AS base
+RUN apk add \
+  curl
+FROM scratch
+`,
+			want: []Stage{
+				{Index: 0, Name: "base", From: "alpine:3.16", Line: 1, BaseStage: -1},
+				{Index: 1, From: "scratch", Line: 7, BaseStage: -1},
+			},
+		},
+		{
+			name:       "tabs between arguments",
+			dockerfile: "FROM\talpine:3.16\tAS\tbase\n",
+			want: []Stage{
+				{Index: 0, Name: "base", From: "alpine:3.16", Line: 1, BaseStage: -1},
+			},
+		},
+		{
+			name: "heredocs",
+			dockerfile: "FROM golang:1.18 AS build\n" +
+				"RUN <<EOF\nFROM this is not a stage\ngo build ./...\nEOF\n" +
+				"COPY <<-\"CONFIG\" <<APP /etc/\n\tFROM neither is this\n\tCONFIG\nFROM nor this\nAPP\n" +
+				"FROM alpine:3.16\n",
+			want: []Stage{
+				{Index: 0, Name: "build", From: "golang:1.18", Line: 1, BaseStage: -1},
+				{Index: 1, From: "alpine:3.16", Line: 11, BaseStage: -1},
+			},
+		},
+		{
+			name:       "escape directive",
+			dockerfile: "# escape=`\nARG TAG=ltsc2022\nFROM mcr.microsoft.com/windows/servercore:$TAG `\n  AS base\nRUN dir C:\\\n",
+			want: []Stage{
+				{Index: 0, Name: "base", From: "mcr.microsoft.com/windows/servercore:ltsc2022", Line: 3, BaseStage: -1},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stages, err := Parse(strings.NewReader(test.dockerfile), test.buildArgs)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, stages)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		wantErr    string
+	}{
+		{
+			name:       "no stages",
+			dockerfile: "# nothing\nARG VERSION=1\n",
+			wantErr:    "no FROM instruction found",
+		},
+		{
+			name:       "instruction before FROM",
+			dockerfile: "RUN true\nFROM alpine\n",
+			wantErr:    "line 1: RUN instruction before the first FROM",
+		},
+		{
+			name:       "too many arguments",
+			dockerfile: "FROM alpine base\n",
+			wantErr:    "line 1: FROM requires either one or three arguments",
+		},
+		{
+			name:       "unknown flag",
+			dockerfile: "FROM --pull alpine\n",
+			wantErr:    `line 1: unknown FROM flag "--pull"`,
+		},
+		{
+			name:       "missing build arg",
+			dockerfile: "ARG BASE\nFROM $BASE\n",
+			wantErr:    "line 2: FROM \"$BASE\" resolves to an empty base",
+		},
+		{
+			name:       "unterminated heredoc",
+			dockerfile: "FROM alpine\nRUN <<EOF\necho hello\n",
+			wantErr:    `line 2: unterminated heredoc (missing "EOF")`,
+		},
+		{
+			name:       "bad substitution",
+			dockerfile: "FROM alpine:${VERSION\n",
+			wantErr:    "line 1: invalid FROM",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.dockerfile), nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.wantErr)
+		})
+	}
+}
+
+func TestStage_External(t *testing.T) {
+	assert.True(t, Stage{From: "alpine", BaseStage: -1}.External())
+	assert.False(t, Stage{From: "SCRATCH", BaseStage: -1}.External())
+	assert.False(t, Stage{From: "build", BaseStage: 0}.External())
+}
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{"NAME": "alpine", "EMPTY": ""}
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "$NAME:3.16", want: "alpine:3.16"},
+		{word: "${NAME}_x", want: "alpine_x"},
+		{word: "${EMPTY:-busybox}", want: "busybox"},
+		{word: "${EMPTY-busybox}", want: ""},
+		{word: "${MISSING-$NAME}", want: "alpine"},
+		{word: "${NAME:+set}", want: "set"},
+		{word: "${EMPTY+set}", want: "set"},
+		{word: "${MISSING:+set}", want: ""},
+		{word: `"$NAME"`, want: "alpine"},
+		{word: `'$NAME'`, want: "$NAME"},
+		{word: `\$NAME`, want: "$NAME"},
+		{word: "cost$", want: "cost$"},
+	}
+	for _, test := range tests {
+		t.Run(test.word, func(t *testing.T) {
+			got, err := expand(test.word, '\\', vars)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expand processes a word of an instruction as docker build does: quotes are removed, escaped characters are kept
+// literally, and variables ($NAME, ${NAME}, ${NAME:-default}, ${NAME:+alternative}, ${NAME-default} and
+// ${NAME+alternative}) are replaced by their values (unset variables are empty). Variables are not replaced within
+// single quotes.
+func expand(word string, escape rune, vars map[string]string) (string, error) {
+	runes := []rune(word)
+	var result strings.Builder
+	var quote rune
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == escape && quote != '\'':
+			if i+1 < len(runes) {
+				i++
+				result.WriteRune(runes[i])
+			}
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote != 0 && r == quote:
+			quote = 0
+		case r == '$' && quote != '\'':
+			value, n, err := variable(runes[i+1:], escape, vars)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(value)
+			i += n
+		default:
+			result.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return "", fmt.Errorf("unterminated quote %q", string(quote))
+	}
+	return result.String(), nil
+}
+
+// variable returns the value of the variable at the start of the runes (following a "$") along with the number of
+// runes the variable spans. A "$" that is not followed by a variable name is kept literally.
+func variable(runes []rune, escape rune, vars map[string]string) (string, int, error) {
+	if len(runes) == 0 {
+		return "$", 0, nil
+	}
+
+	if runes[0] != '{' {
+		n := nameLength(runes)
+		if n == 0 {
+			return "$", 0, nil
+		}
+		return vars[string(runes[:n])], n, nil
+	}
+
+	n := nameLength(runes[1:])
+	if n == 0 {
+		return "", 0, fmt.Errorf("bad substitution %q", "$"+string(runes))
+	}
+	name := string(runes[1 : 1+n])
+	rest := runes[1+n:]
+
+	end := closingBrace(rest, escape)
+	if end < 0 {
+		return "", 0, fmt.Errorf("missing '}' in substitution %q", "$"+string(runes))
+	}
+	modifier := string(rest[:end])
+	length := 1 + n + end + 1
+
+	value, set := vars[name]
+	if modifier == "" {
+		return value, length, nil
+	}
+
+	op, alternative := modifier, ""
+	switch {
+	case strings.HasPrefix(modifier, ":-"), strings.HasPrefix(modifier, ":+"):
+		op, alternative = modifier[:2], modifier[2:]
+		// with a colon an empty variable is treated as unset
+		set = set && value != ""
+	case strings.HasPrefix(modifier, "-"), strings.HasPrefix(modifier, "+"):
+		op, alternative = modifier[:1], modifier[1:]
+	default:
+		return "", 0, fmt.Errorf("unsupported modifier %q in substitution of %q", modifier, name)
+	}
+
+	switch strings.TrimPrefix(op, ":") {
+	case "-":
+		if set {
+			return value, length, nil
+		}
+	case "+":
+		if !set {
+			return "", length, nil
+		}
+	}
+	expanded, err := expand(alternative, escape, vars)
+	if err != nil {
+		return "", 0, err
+	}
+	return expanded, length, nil
+}
+
+// nameLength returns the length of the variable name at the start of the runes (0 when there is none).
+func nameLength(runes []rune) int {
+	for i, r := range runes {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if letter || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return i
+	}
+	return len(runes)
+}
+
+// closingBrace returns the index of the "}" closing a substitution (-1 when there is none), where nested
+// substitutions and escaped characters are skipped.
+func closingBrace(runes []rune, escape rune) int {
+	depth := 0
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == escape:
+			i++
+		case runes[i] == '{':
+			depth++
+		case runes[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
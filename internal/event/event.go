@@ -0,0 +1,83 @@
+/*
+Package event provides the event types that the plugin publishes onto the event bus (in addition to the syft and
+stereoscope events), along with a parser for each event.
+*/
+package event
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/wagoodman/go-partybus"
+	"github.com/wagoodman/go-progress"
+
+	"github.com/anchore/syft/syft/sbom"
+)
+
+const (
+	// CatalogerStarted is a partybus event that occurs when a single package cataloger has begun cataloging
+	CatalogerStarted partybus.EventType = "sbom-cli-plugin-cataloger-started-event"
+
+	// SBOMReady is a partybus event that occurs when the SBOM of the image is complete (before the report is written)
+	SBOMReady partybus.EventType = "sbom-cli-plugin-sbom-ready-event"
+)
+
+// CatalogerMonitor provides the progress of a single package cataloger (published on the event bus).
+type CatalogerMonitor struct {
+	Name     string
+	Files    progress.Monitorable // the number of files read by the cataloger
+	Packages progress.Monitorable // the number of packages discovered by the cataloger (set once the cataloger is done)
+}
+
+// ParseCatalogerStarted returns the monitor of a CatalogerStarted event.
+func ParseCatalogerStarted(e partybus.Event) (*CatalogerMonitor, error) {
+	if e.Type != CatalogerStarted {
+		return nil, fmt.Errorf("unexpected event type: %q (expected %q)", e.Type, CatalogerStarted)
+	}
+	monitor, ok := e.Value.(CatalogerMonitor)
+	if !ok {
+		return nil, fmt.Errorf("bad %s event value: %+v", e.Type, e.Value)
+	}
+	return &monitor, nil
+}
+
+// ParseSBOMReady returns the SBOM of a SBOMReady event (which should be treated as read-only).
+func ParseSBOMReady(e partybus.Event) (*sbom.SBOM, error) {
+	if e.Type != SBOMReady {
+		return nil, fmt.Errorf("unexpected event type: %q (expected %q)", e.Type, SBOMReady)
+	}
+	s, ok := e.Value.(sbom.SBOM)
+	if !ok {
+		return nil, fmt.Errorf("bad %s event value: %+v", e.Type, e.Value)
+	}
+	return &s, nil
+}
+
+// Counter is a progress.Monitorable that is safe to update while the UI polls it.
+type Counter struct {
+	n    int64
+	done int32
+}
+
+func (c *Counter) Add(n int64) {
+	atomic.AddInt64(&c.n, n)
+}
+
+func (c *Counter) Set(n int64) {
+	atomic.StoreInt64(&c.n, n)
+}
+
+func (c *Counter) SetCompleted() {
+	atomic.StoreInt32(&c.done, 1)
+}
+
+func (c *Counter) Current() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+func (c *Counter) Error() error {
+	if atomic.LoadInt32(&c.done) == 1 {
+		return progress.ErrCompleted
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+/*
+Package explain describes the evidence behind packages within an SBOM: where each package was found, which cataloger
+found it, the metadata it was found with, how its identifiers (CPEs and package URL) were derived, and its
+relationships to other packages and files.
+*/
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/query"
+
+	"github.com/anchore/packageurl-go"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+// the maximum number of list items shown for a single metadata field (e.g. the files owned by a dpkg package)
+const maxListItems = 5
+
+// Location is a single path that a package was found by.
+type Location struct {
+	Path        string // the real path within the image
+	VirtualPath string // the path as seen by the cataloger (e.g. a path within a nested archive)
+	LayerIndex  int    // the index of the layer containing the path (-1 when unknown)
+	LayerDigest string // the digest of the layer containing the path
+}
+
+// Relationship is a relationship between the explained package and another package or file.
+type Relationship struct {
+	Type     artifact.RelationshipType
+	Outgoing bool   // true when the explained package is the source of the relationship
+	Other    string // a description of the other side of the relationship
+}
+
+// Explanation is all evidence about a single package.
+type Explanation struct {
+	Package        pkg.Package
+	Locations      []Location
+	PURLDerivation string
+	CPEDerivation  string
+	MetadataFields []Field
+	Relationships  []Relationship
+}
+
+// Field is a single (top level) field of the package metadata.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Packages returns an explanation for every package within the SBOM selected by the given matcher.
+func Packages(s sbom.SBOM, m query.Matcher) []Explanation {
+	if s.Artifacts.PackageCatalog == nil {
+		return nil
+	}
+
+	var explanations []Explanation
+	for _, p := range s.Artifacts.PackageCatalog.Sorted() {
+		if !m.Matches(p) {
+			continue
+		}
+		explanations = append(explanations, explain(s, p))
+	}
+	return explanations
+}
+
+// Package returns the explanation of a single package within the SBOM.
+func Package(s sbom.SBOM, p pkg.Package) Explanation {
+	return explain(s, p)
+}
+
+func explain(s sbom.SBOM, p pkg.Package) Explanation {
+	e := Explanation{
+		Package:        p,
+		PURLDerivation: purlDerivation(p, s.Artifacts.LinuxDistribution),
+		CPEDerivation:  cpeDerivation(p),
+		MetadataFields: metadataFields(p.Metadata),
+	}
+
+	for _, l := range p.Locations.ToSlice() {
+		loc := Location{
+			Path:        l.RealPath,
+			VirtualPath: l.VirtualPath,
+			LayerIndex:  -1,
+			LayerDigest: l.FileSystemID,
+		}
+		for i, layer := range s.Source.ImageMetadata.Layers {
+			if layer.Digest == l.FileSystemID {
+				loc.LayerIndex = i
+				break
+			}
+		}
+		e.Locations = append(e.Locations, loc)
+	}
+
+	for _, r := range s.Relationships {
+		switch {
+		case r.From != nil && r.From.ID() == p.ID():
+			e.Relationships = append(e.Relationships, Relationship{Type: r.Type, Outgoing: true, Other: describe(s, r.To)})
+		case r.To != nil && r.To.ID() == p.ID():
+			e.Relationships = append(e.Relationships, Relationship{Type: r.Type, Other: describe(s, r.From)})
+		}
+	}
+	sort.SliceStable(e.Relationships, func(i, j int) bool {
+		a, b := e.Relationships[i], e.Relationships[j]
+		if a.Outgoing != b.Outgoing {
+			return a.Outgoing
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Other < b.Other
+	})
+
+	return e
+}
+
+func describe(s sbom.SBOM, i artifact.Identifiable) string {
+	switch v := i.(type) {
+	case pkg.Package:
+		return fmt.Sprintf("package %s %s (%s)", v.Name, v.Version, v.Type)
+	case *pkg.Package:
+		return fmt.Sprintf("package %s %s (%s)", v.Name, v.Version, v.Type)
+	case source.Coordinates:
+		return "file " + v.RealPath
+	case nil:
+		return "unknown"
+	}
+
+	// relationships decoded from a document may only reference the package ID
+	if s.Artifacts.PackageCatalog != nil {
+		if p := s.Artifacts.PackageCatalog.Package(i.ID()); p != nil {
+			return fmt.Sprintf("package %s %s (%s)", p.Name, p.Version, p.Type)
+		}
+	}
+	return string(i.ID())
+}
+
+type purlIdentifier interface {
+	PackageURL(*linux.Release) string
+}
+
+func purlDerivation(p pkg.Package, distro *linux.Release) string {
+	if p.PURL == "" {
+		return "no package URL (there is no package URL type for " + string(p.Type) + " packages)"
+	}
+
+	var derivation string
+	if _, ok := p.Metadata.(purlIdentifier); ok {
+		derivation = fmt.Sprintf("derived from the %s metadata", p.MetadataType)
+		if distro != nil && distro.ID != "" && distro.VersionID != "" {
+			derivation += fmt.Sprintf(" and the %s-%s distro", distro.ID, distro.VersionID)
+		}
+	} else {
+		derivation = "derived from the package type, name, and version"
+	}
+
+	purl, err := packageurl.FromString(p.PURL)
+	if err != nil {
+		return derivation
+	}
+
+	parts := []string{"type=" + purl.Type}
+	if purl.Namespace != "" {
+		parts = append(parts, "namespace="+purl.Namespace)
+	}
+	parts = append(parts, "name="+purl.Name)
+	if purl.Version != "" {
+		parts = append(parts, "version="+purl.Version)
+	}
+	for _, q := range purl.Qualifiers {
+		parts = append(parts, q.Key+"="+q.Value)
+	}
+	if purl.Subpath != "" {
+		parts = append(parts, "subpath="+purl.Subpath)
+	}
+
+	return derivation + ": " + strings.Join(parts, " ")
+}
+
+func cpeDerivation(p pkg.Package) string {
+	if len(p.CPEs) == 0 {
+		return "no CPEs"
+	}
+
+	var sources []string
+	sources = append(sources, "the package name")
+	if p.MetadataType != "" {
+		sources = append(sources, string(p.MetadataType)+" metadata")
+	}
+	return fmt.Sprintf("generated from candidate vendors and products for %s packages (based on %s) and the package version", p.Type, strings.Join(sources, " and "))
+}
+
+func metadataFields(metadata interface{}) []Field {
+	if metadata == nil {
+		return nil
+	}
+
+	by, err := json.Marshal(metadata)
+	if err != nil {
+		return []Field{{Name: "error", Value: err.Error()}}
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(by, &values); err != nil {
+		return []Field{{Name: "value", Value: string(by)}}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, Field{Name: name, Value: fieldValue(values[name])})
+	}
+	return fields
+}
+
+func fieldValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	case []interface{}:
+		if len(value) > maxListItems {
+			by, _ := json.Marshal(value[:maxListItems])
+			return fmt.Sprintf("%s (%d more)", by, len(value)-maxListItems)
+		}
+	}
+	by, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(by)
+}
+
+// Write renders the given explanations in a human-readable form.
+func Write(w io.Writer, explanations []Explanation) error {
+	var sb strings.Builder
+	for i, e := range explanations {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		p := e.Package
+
+		fmt.Fprintf(&sb, "%s %s (%s)\n", p.Name, p.Version, p.Type)
+		fmt.Fprintf(&sb, "  ID:         %s\n", p.ID())
+		fmt.Fprintf(&sb, "  Found by:   %s\n", p.FoundBy)
+		if p.Language != "" {
+			fmt.Fprintf(&sb, "  Language:   %s\n", p.Language)
+		}
+		if len(p.Licenses) > 0 {
+			fmt.Fprintf(&sb, "  Licenses:   %s\n", strings.Join(p.Licenses, ", "))
+		}
+
+		sb.WriteString("  Locations:\n")
+		for _, l := range e.Locations {
+			fmt.Fprintf(&sb, "    - %s\n", l.Path)
+			if l.VirtualPath != "" && l.VirtualPath != l.Path {
+				fmt.Fprintf(&sb, "      virtual path: %s\n", l.VirtualPath)
+			}
+			switch {
+			case l.LayerIndex >= 0:
+				fmt.Fprintf(&sb, "      layer:        %d (%s)\n", l.LayerIndex, l.LayerDigest)
+			case l.LayerDigest != "":
+				fmt.Fprintf(&sb, "      layer:        %s\n", l.LayerDigest)
+			}
+		}
+
+		if p.MetadataType != "" {
+			fmt.Fprintf(&sb, "  Metadata (%s):\n", p.MetadataType)
+			for _, f := range e.MetadataFields {
+				fmt.Fprintf(&sb, "    %s: %s\n", f.Name, f.Value)
+			}
+		}
+
+		fmt.Fprintf(&sb, "  Package URL: %s\n", valueOrNone(p.PURL))
+		fmt.Fprintf(&sb, "    %s\n", e.PURLDerivation)
+
+		sb.WriteString("  CPEs:\n")
+		fmt.Fprintf(&sb, "    %s\n", e.CPEDerivation)
+		for _, c := range p.CPEs {
+			fmt.Fprintf(&sb, "    - %s\n", pkg.CPEString(c))
+		}
+
+		sb.WriteString("  Relationships:\n")
+		if len(e.Relationships) == 0 {
+			sb.WriteString("    none\n")
+		}
+		for _, r := range e.Relationships {
+			if r.Outgoing {
+				fmt.Fprintf(&sb, "    -> %s %s\n", r.Type, r.Other)
+				continue
+			}
+			fmt.Fprintf(&sb, "    <- %s %s\n", r.Type, r.Other)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func valueOrNone(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+package explain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docker/sbom-cli-plugin/internal/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+func newSBOM() sbom.SBOM {
+	distro := &linux.Release{ID: "debian", VersionID: "11"}
+
+	curl := pkg.Package{
+		Name:    "curl",
+		Version: "7.74.0-1.3+deb11u1",
+		FoundBy: "dpkgdb-cataloger",
+		Type:    pkg.DebPkg,
+		Locations: source.NewLocationSet(source.Location{
+			Coordinates: source.Coordinates{RealPath: "/var/lib/dpkg/status", FileSystemID: "sha256:layer1"},
+		}),
+		MetadataType: pkg.DpkgMetadataType,
+		Metadata: pkg.DpkgMetadata{
+			Package:      "curl",
+			Version:      "7.74.0-1.3+deb11u1",
+			Architecture: "amd64",
+		},
+		CPEs: []pkg.CPE{pkg.MustCPE("cpe:2.3:a:haxx:curl:7.74.0-1.3+deb11u1:*:*:*:*:*:*:*")},
+	}
+	curl.PURL = pkg.URL(curl, distro)
+	curl.SetID()
+
+	libcurl := pkg.Package{
+		Name:    "libcurl4",
+		Version: "7.74.0-1.3+deb11u1",
+		Type:    pkg.DebPkg,
+	}
+	libcurl.SetID()
+
+	catalog := pkg.NewCatalog(curl, libcurl)
+
+	return sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			PackageCatalog:    catalog,
+			LinuxDistribution: distro,
+		},
+		Relationships: []artifact.Relationship{
+			{From: libcurl, To: curl, Type: artifact.DependencyOfRelationship},
+			{From: curl, To: source.Coordinates{RealPath: "/usr/bin/curl"}, Type: artifact.ContainsRelationship},
+		},
+		Source: source.Metadata{
+			Scheme: source.ImageScheme,
+			ImageMetadata: source.ImageMetadata{
+				Layers: []source.LayerMetadata{{Digest: "sha256:layer0"}, {Digest: "sha256:layer1"}},
+			},
+		},
+	}
+}
+
+func TestPackages(t *testing.T) {
+	explanations := Packages(newSBOM(), query.Matcher{Package: "curl"})
+	require.Len(t, explanations, 1)
+
+	e := explanations[0]
+	assert.Equal(t, []Location{{Path: "/var/lib/dpkg/status", LayerIndex: 1, LayerDigest: "sha256:layer1"}}, e.Locations)
+	assert.Equal(t, "derived from the DpkgMetadata metadata and the debian-11 distro: type=deb namespace=debian name=curl version=7.74.0-1.3+deb11u1 arch=amd64 distro=debian-11", e.PURLDerivation)
+	assert.Equal(t, []Relationship{
+		{Type: artifact.ContainsRelationship, Outgoing: true, Other: "file /usr/bin/curl"},
+		{Type: artifact.DependencyOfRelationship, Other: "package libcurl4 7.74.0-1.3+deb11u1 (deb)"},
+	}, e.Relationships)
+	assert.Contains(t, e.MetadataFields, Field{Name: "architecture", Value: "amd64"})
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Packages(newSBOM(), query.Matcher{Package: "curl"})))
+
+	actual := buf.String()
+	for _, expected := range []string{
+		"curl 7.74.0-1.3+deb11u1 (deb)\n",
+		"  Found by:   dpkgdb-cataloger\n",
+		"    - /var/lib/dpkg/status\n      layer:        1 (sha256:layer1)\n",
+		"  Metadata (DpkgMetadata):\n",
+		"    architecture: amd64\n",
+		"  Package URL: pkg:deb/debian/curl@7.74.0-1.3+deb11u1?arch=amd64&distro=debian-11\n",
+		"    - cpe:2.3:a:haxx:curl:7.74.0-1.3\\+deb11u1:*:*:*:*:*:*:*\n",
+		"    -> contains file /usr/bin/curl\n",
+		"    <- dependency-of package libcurl4 7.74.0-1.3+deb11u1 (deb)\n",
+	} {
+		assert.Contains(t, actual, expected)
+	}
+}
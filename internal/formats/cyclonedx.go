@@ -0,0 +1,188 @@
+package formats
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+
+	"github.com/anchore/syft/syft/artifact"
+)
+
+const (
+	cycloneDXJSON = cyclonedx.BOMFileFormatJSON
+	cycloneDXXML  = cyclonedx.BOMFileFormatXML
+)
+
+func extendCycloneDX(output io.Writer, document []byte, fileFormat cyclonedx.BOMFileFormat, ext Extension) error {
+	bom := cyclonedx.BOM{}
+	if err := cyclonedx.NewBOMDecoder(bytes.NewReader(document), fileFormat).Decode(&bom); err != nil {
+		return err
+	}
+
+	extendCycloneDXBOM(&bom, ext)
+
+	enc := cyclonedx.NewBOMEncoder(output, fileFormat)
+	enc.SetPretty(true)
+	return enc.Encode(&bom)
+}
+
+func extendCycloneDXBOM(bom *cyclonedx.BOM, ext Extension) {
+	if bom.Metadata == nil {
+		bom.Metadata = &cyclonedx.Metadata{}
+	}
+
+	if ext.Describes != nil {
+		bom.Metadata.Component = toCycloneDXComponent(*ext.Describes)
+	}
+
+	if len(ext.Tools) > 0 {
+		bom.Metadata.Tools = appendCycloneDXTools(bom.Metadata.Tools, ext.Tools)
+	}
+	bom.Metadata.Properties = appendCycloneDXProperties(bom.Metadata.Properties, ext.Properties)
+
+	if len(ext.Components) > 0 {
+		var related []cyclonedx.Component
+		for _, c := range ext.Components {
+			related = append(related, *toCycloneDXComponent(c))
+		}
+
+		// related components are nested under the component the document describes when there is one, otherwise
+		// they are listed alongside the packages
+		if bom.Metadata.Component != nil {
+			bom.Metadata.Component.Components = appendCycloneDXComponents(bom.Metadata.Component.Components, related)
+		} else {
+			bom.Components = appendCycloneDXComponents(bom.Components, related)
+		}
+	}
+
+	bom.Dependencies = appendCycloneDXContains(bom.Dependencies, bom.Components, ext)
+
+	if len(ext.PackageProperties) > 0 && bom.Components != nil {
+		components := *bom.Components
+		for i := range components {
+			props, ok := ext.PackageProperties[cycloneDXPackageID(components[i].BOMRef)]
+			if !ok {
+				continue
+			}
+			components[i].Properties = appendCycloneDXProperties(components[i].Properties, props)
+		}
+	}
+
+	if len(ext.Vulnerabilities) > 0 {
+		bom.Vulnerabilities = toCycloneDXVulnerabilities(bom.Components, ext.Vulnerabilities)
+	}
+}
+
+func toCycloneDXComponent(c Component) *cyclonedx.Component {
+	return &cyclonedx.Component{
+		BOMRef:     c.ID,
+		Type:       cyclonedx.ComponentType(c.Type),
+		Name:       c.Name,
+		Version:    c.Version,
+		Properties: appendCycloneDXProperties(nil, c.Properties),
+	}
+}
+
+// appendCycloneDXContains lists the packages within each component as dependencies of the component. CycloneDX only
+// expresses containment by nesting components, which would take the packages out of the list of components (where
+// package properties and vulnerabilities refer to them).
+func appendCycloneDXContains(existing *[]cyclonedx.Dependency, components *[]cyclonedx.Component, ext Extension) *[]cyclonedx.Dependency {
+	related := ext.Components
+	if ext.Describes != nil {
+		related = append([]Component{*ext.Describes}, related...)
+	}
+
+	bomRefs := make(map[artifact.ID]string)
+	if components != nil {
+		for _, c := range *components {
+			bomRefs[cycloneDXPackageID(c.BOMRef)] = c.BOMRef
+		}
+	}
+
+	var result []cyclonedx.Dependency
+	if existing != nil {
+		result = *existing
+	}
+	for _, c := range related {
+		var dependencies []cyclonedx.Dependency
+		for _, id := range c.Contains {
+			if ref, ok := bomRefs[id]; ok {
+				dependencies = append(dependencies, cyclonedx.Dependency{Ref: ref})
+			}
+		}
+		if len(dependencies) == 0 {
+			continue
+		}
+		result = append(result, cyclonedx.Dependency{Ref: c.ID, Dependencies: &dependencies})
+	}
+	if len(result) == 0 {
+		return existing
+	}
+	return &result
+}
+
+func appendCycloneDXComponents(existing *[]cyclonedx.Component, components []cyclonedx.Component) *[]cyclonedx.Component {
+	var result []cyclonedx.Component
+	if existing != nil {
+		result = *existing
+	}
+	result = append(result, components...)
+	return &result
+}
+
+func appendCycloneDXProperties(existing *[]cyclonedx.Property, props []Property) *[]cyclonedx.Property {
+	if len(props) == 0 {
+		return existing
+	}
+	var result []cyclonedx.Property
+	if existing != nil {
+		result = *existing
+	}
+	for _, p := range props {
+		result = append(result, cyclonedx.Property{Name: p.Name, Value: p.Value})
+	}
+	return &result
+}
+
+// appendCycloneDXTools adds the tools that are not listed yet (by vendor and name).
+func appendCycloneDXTools(existing *[]cyclonedx.Tool, tools []Tool) *[]cyclonedx.Tool {
+	var result []cyclonedx.Tool
+	if existing != nil {
+		result = *existing
+	}
+	for _, t := range tools {
+		listed := false
+		for _, r := range result {
+			if strings.EqualFold(r.Vendor, t.Vendor) && r.Name == t.Name {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			result = append(result, cyclonedx.Tool{Vendor: t.Vendor, Name: t.Name, Version: t.Version})
+		}
+	}
+	return &result
+}
+
+// cycloneDXPackageID returns the syft package ID from a component bom-ref. Syft uses the package URL with an
+// additional "package-id" qualifier when a package URL is available, otherwise the package ID is used directly.
+func cycloneDXPackageID(bomRef string) artifact.ID {
+	fields := strings.SplitN(bomRef, "?", 2)
+	if len(fields) != 2 || !strings.HasPrefix(bomRef, "pkg:") {
+		return artifact.ID(bomRef)
+	}
+
+	qualifiers, err := url.ParseQuery(strings.SplitN(fields[1], "#", 2)[0])
+	if err != nil {
+		return artifact.ID(bomRef)
+	}
+
+	if id := qualifiers.Get("package-id"); id != "" {
+		return artifact.ID(id)
+	}
+	return artifact.ID(bomRef)
+}
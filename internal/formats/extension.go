@@ -0,0 +1,188 @@
+/*
+Package formats layers plugin-specific content on top of the SBOM documents produced by syft's encoders. Syft's format
+models are internal to syft and cannot be extended directly, so an extension is applied by encoding the document with
+the original syft format, reading the encoded document back with the format's own model, amending it, and encoding it
+again in the same format.
+*/
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// Property is a single name-value fact attached to a document element.
+type Property struct {
+	Name  string
+	Value string
+}
+
+// Component describes an element that is related to the cataloged packages but is not itself a package (for example
+// an image or document that a merged SBOM was assembled from).
+type Component struct {
+	ID         string        // a reference for the component that is unique within the document
+	Type       string        // the CycloneDX component type (e.g. "container", "application", "file")
+	Name       string        // the component name
+	Version    string        // the component version (optional)
+	Properties []Property    // additional facts about the component
+	Contains   []artifact.ID // the packages in the document that are contained within this component
+}
+
+// Vulnerability is a known vulnerability that affects packages in the document.
+type Vulnerability struct {
+	ID         string            // the vulnerability ID within the source (e.g. "GHSA-jfh8-c2jp-5v3q")
+	Source     string            // the name of the vulnerability data source
+	URL        string            // the location of the vulnerability within the source
+	Aliases    []string          // other identifiers for the same vulnerability (e.g. CVE IDs)
+	Summary    string            // a short description of the vulnerability
+	Severity   string            // the qualitative severity ("critical", "high", "medium", "low", or "unknown")
+	Score      float64           // the CVSS base score (0 when unknown)
+	Vector     string            // the CVSS vector the score was calculated from (optional)
+	References []string          // URLs with further information
+	Affects    []AffectedPackage // the packages in the document that are affected
+	Analysis   *Analysis         // the triage decision for the affected packages (optional)
+}
+
+// Analysis is the outcome of triaging a vulnerability for the affected packages (e.g. from a VEX statement).
+type Analysis struct {
+	Status          string // the OpenVEX status ("not_affected", "affected", "fixed", or "under_investigation")
+	Justification   string // why the packages are not affected
+	ImpactStatement string // a description of why the packages are (not) affected
+	ActionStatement string // the action to take when the packages are affected
+}
+
+// Resolved indicates if the vulnerability does not need further action (i.e. the packages are not affected or fixed).
+func (a *Analysis) Resolved() bool {
+	return a != nil && (a.Status == "not_affected" || a.Status == "fixed")
+}
+
+// AffectedPackage is a package in the document that is affected by a vulnerability.
+type AffectedPackage struct {
+	Package artifact.ID
+	FixedIn []string // the versions that the vulnerability is fixed in (if known)
+}
+
+// Tool is a tool that took part in producing the document (in addition to syft, which is always listed).
+type Tool struct {
+	Vendor  string // the organization that provides the tool (optional)
+	Name    string // the tool name
+	Version string // the tool version (optional)
+}
+
+// Column is a column to add to the table of packages, with a value for each package (empty when there is none).
+type Column struct {
+	Header string
+	Values map[artifact.ID]string
+}
+
+// Extension is the set of additions to be made to an encoded SBOM document.
+type Extension struct {
+	Describes         *Component                 // overrides the element that the document describes (optional)
+	Components        []Component                // related components to add to the document
+	PackageProperties map[artifact.ID][]Property // facts to attach to existing packages in the document
+	Vulnerabilities   []Vulnerability            // known vulnerabilities affecting packages in the document
+	Tools             []Tool                     // tools to list as creators of the document
+	Properties        []Property                 // facts about how the document was produced
+	PackageColumns    []Column                   // columns to add to the table of packages (only for the table format)
+}
+
+// Extender produces an Extension for the given SBOM at encoding time. A nil Extension indicates there is nothing to add.
+type Extender func(sbom.SBOM) (*Extension, error)
+
+// Extend wraps the given format such that all extensions are applied to every document it encodes. Formats that do
+// not have a place for additional content (e.g. text) are returned unchanged, the table format only has a place for
+// package columns and vulnerabilities, and the syft JSON format only has a place for vulnerabilities (other additions
+// are ignored, where syft JSON records the provenance of the document in its descriptor instead).
+func Extend(f sbom.Format, extenders ...Extender) sbom.Format {
+	if f == nil || len(extenders) == 0 || !isExtensible(f.ID()) {
+		return f
+	}
+
+	encoder := func(output io.Writer, s sbom.SBOM) error {
+		ext, err := collect(s, extenders)
+		if err != nil {
+			return err
+		}
+
+		buf := &bytes.Buffer{}
+		if err := f.Encode(buf, s); err != nil {
+			return err
+		}
+
+		if ext == nil {
+			_, err = io.Copy(output, buf)
+			return err
+		}
+
+		return apply(f.ID(), output, buf.Bytes(), s, *ext)
+	}
+
+	return sbom.NewFormat(f.ID(), encoder, f.Decode, f.Validate)
+}
+
+func isExtensible(id sbom.FormatID) bool {
+	switch id {
+	case syft.CycloneDxJSONFormatID, syft.CycloneDxXMLFormatID, syft.SPDXJSONFormatID, syft.SPDXTagValueFormatID,
+		syft.TableFormatID, syft.JSONFormatID:
+		return true
+	}
+	return false
+}
+
+func apply(id sbom.FormatID, output io.Writer, document []byte, s sbom.SBOM, ext Extension) error {
+	var err error
+	switch id {
+	case syft.TableFormatID:
+		err = extendTable(output, document, s, ext)
+	case syft.JSONFormatID:
+		err = extendSyftJSON(output, document, s, ext)
+	case syft.CycloneDxJSONFormatID:
+		err = extendCycloneDX(output, document, cycloneDXJSON, ext)
+	case syft.CycloneDxXMLFormatID:
+		err = extendCycloneDX(output, document, cycloneDXXML, ext)
+	case syft.SPDXJSONFormatID:
+		err = extendSPDXJSON(output, document, ext)
+	case syft.SPDXTagValueFormatID:
+		err = extendSPDXTagValue(output, document, ext)
+	default:
+		return fmt.Errorf("unable to extend format %q", id)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to extend %q document: %w", id, err)
+	}
+	return nil
+}
+
+// collect invokes all extenders and combines the results into a single extension (nil if there is nothing to add).
+func collect(s sbom.SBOM, extenders []Extender) (*Extension, error) {
+	var result *Extension
+	for _, extender := range extenders {
+		ext, err := extender(s)
+		if err != nil {
+			return nil, err
+		}
+		if ext == nil {
+			continue
+		}
+		if result == nil {
+			result = &Extension{PackageProperties: make(map[artifact.ID][]Property)}
+		}
+		if ext.Describes != nil {
+			result.Describes = ext.Describes
+		}
+		result.Components = append(result.Components, ext.Components...)
+		result.Vulnerabilities = append(result.Vulnerabilities, ext.Vulnerabilities...)
+		result.Tools = append(result.Tools, ext.Tools...)
+		result.Properties = append(result.Properties, ext.Properties...)
+		result.PackageColumns = append(result.PackageColumns, ext.PackageColumns...)
+		for id, props := range ext.PackageProperties {
+			result.PackageProperties[id] = append(result.PackageProperties[id], props...)
+		}
+	}
+	return result, nil
+}
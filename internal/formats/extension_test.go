@@ -0,0 +1,425 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+func testSBOM() (sbom.SBOM, pkg.Package) {
+	p := pkg.Package{
+		Name:    "lodash",
+		Version: "4.17.21",
+		PURL:    "pkg:npm/lodash@4.17.21",
+		Type:    pkg.NpmPkg,
+	}
+	p.SetID()
+
+	return sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			PackageCatalog: pkg.NewCatalog(p),
+		},
+		Source: source.Metadata{
+			Scheme: source.DirectoryScheme,
+			Path:   "/app",
+		},
+	}, p
+}
+
+func testExtension(p pkg.Package) Extender {
+	return func(_ sbom.SBOM) (*Extension, error) {
+		return &Extension{
+			Components: []Component{
+				{
+					ID:       "related-1",
+					Type:     "container",
+					Name:     "image:latest",
+					Contains: []artifact.ID{p.ID()},
+				},
+			},
+			PackageProperties: map[artifact.ID][]Property{
+				p.ID(): {{Name: "test:source", Value: "image:latest"}},
+			},
+		}, nil
+	}
+}
+
+func Test_cycloneDXPackageID(t *testing.T) {
+	tests := []struct {
+		bomRef string
+		want   artifact.ID
+	}{
+		{
+			bomRef: "pkg:npm/lodash@4.17.21?package-id=0123456789abcdef",
+			want:   "0123456789abcdef",
+		},
+		{
+			bomRef: "pkg:deb/debian/libc6@2.31?arch=amd64&package-id=fedcba9876543210&distro=debian-11",
+			want:   "fedcba9876543210",
+		},
+		{
+			bomRef: "0123456789abcdef",
+			want:   "0123456789abcdef",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.bomRef, func(t *testing.T) {
+			assert.Equal(t, tt.want, cycloneDXPackageID(tt.bomRef))
+		})
+	}
+}
+
+func TestExtend_unsupportedFormatsAreUnchanged(t *testing.T) {
+	f := syft.FormatByID(syft.TextFormatID)
+	assert.Equal(t, f, Extend(f, testExtension(pkg.Package{})))
+}
+
+func TestExtend_CycloneDXJSON(t *testing.T) {
+	s, p := testSBOM()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(syft.FormatByID(syft.CycloneDxJSONFormatID), testExtension(p)).Encode(buf, s))
+
+	bom := cyclonedx.BOM{}
+	require.NoError(t, cyclonedx.NewBOMDecoder(buf, cyclonedx.BOMFileFormatJSON).Decode(&bom))
+
+	require.NotNil(t, bom.Metadata.Component)
+	require.NotNil(t, bom.Metadata.Component.Components)
+	assert.Equal(t, "image:latest", (*bom.Metadata.Component.Components)[0].Name)
+
+	var found bool
+	var lodashRef string
+	for _, c := range *bom.Components {
+		if c.Name != "lodash" {
+			continue
+		}
+		lodashRef = c.BOMRef
+		require.NotNil(t, c.Properties)
+		for _, prop := range *c.Properties {
+			if prop.Name == "test:source" {
+				found = true
+				assert.Equal(t, "image:latest", prop.Value)
+			}
+		}
+	}
+	assert.True(t, found, "package property not found")
+
+	// the packages within a component are its dependencies
+	require.NotNil(t, bom.Dependencies)
+	assert.Equal(t, []cyclonedx.Dependency{
+		{Ref: "related-1", Dependencies: &[]cyclonedx.Dependency{{Ref: lodashRef}}},
+	}, *bom.Dependencies)
+}
+
+func TestExtend_SPDXJSON(t *testing.T) {
+	s, p := testSBOM()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(syft.FormatByID(syft.SPDXJSONFormatID), testExtension(p)).Encode(buf, s))
+
+	var doc struct {
+		Packages []struct {
+			SPDXID      string `json:"SPDXID"`
+			Name        string `json:"name"`
+			Annotations []struct {
+				Comment string `json:"comment"`
+			} `json:"annotations"`
+		} `json:"packages"`
+		Relationships []struct {
+			From string `json:"spdxElementId"`
+			To   string `json:"relatedSpdxElement"`
+			Type string `json:"relationshipType"`
+		} `json:"relationships"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	require.Len(t, doc.Packages, 2)
+	assert.Equal(t, "lodash", doc.Packages[0].Name)
+	require.Len(t, doc.Packages[0].Annotations, 1)
+	assert.Equal(t, "test:source: image:latest", doc.Packages[0].Annotations[0].Comment)
+	assert.Equal(t, "SPDXRef-related-1", doc.Packages[1].SPDXID)
+
+	require.Len(t, doc.Relationships, 1)
+	assert.Equal(t, "SPDXRef-related-1", doc.Relationships[0].From)
+	assert.Equal(t, "SPDXRef-"+string(p.ID()), doc.Relationships[0].To)
+	assert.Equal(t, "CONTAINS", doc.Relationships[0].Type)
+}
+
+func TestExtend_SPDXTagValue(t *testing.T) {
+	s, p := testSBOM()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(syft.FormatByID(syft.SPDXTagValueFormatID), testExtension(p)).Encode(buf, s))
+
+	assert.Contains(t, buf.String(), "SPDXID: SPDXRef-related-1")
+	assert.Contains(t, buf.String(), "Relationship: SPDXRef-related-1 CONTAINS SPDXRef-Package-npm-lodash-"+string(p.ID()))
+	assert.Contains(t, buf.String(), "AnnotationComment: test:source: image:latest")
+}
+
+func testVulnerability(p pkg.Package) Extender {
+	return func(_ sbom.SBOM) (*Extension, error) {
+		return &Extension{
+			Vulnerabilities: []Vulnerability{
+				{
+					ID:       "GHSA-35jh-r3h4-6jhm",
+					Source:   "OSV",
+					URL:      "https://osv.dev/vulnerability/GHSA-35jh-r3h4-6jhm",
+					Aliases:  []string{"CVE-2021-23337"},
+					Severity: "high",
+					Score:    7.2,
+					Vector:   "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H",
+					Affects:  []AffectedPackage{{Package: p.ID(), FixedIn: []string{"4.17.22"}}},
+				},
+			},
+		}, nil
+	}
+}
+
+func TestExtend_vulnerabilitiesTable(t *testing.T) {
+	s, p := testSBOM()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(syft.FormatByID(syft.TableFormatID), testVulnerability(p)).Encode(buf, s))
+
+	assert.Contains(t, buf.String(), "VULNERABILITY")
+	assert.Regexp(t, `lodash\s+4\.17\.21\s+4\.17\.22\s+npm\s+GHSA-35jh-r3h4-6jhm\s+high`, buf.String())
+}
+
+func TestExtend_tableWithoutVulnerabilities(t *testing.T) {
+	s, p := testSBOM()
+	f := syft.FormatByID(syft.TableFormatID)
+
+	expected := &bytes.Buffer{}
+	require.NoError(t, f.Encode(expected, s))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(f, testExtension(p)).Encode(buf, s))
+	assert.Equal(t, expected.String(), buf.String())
+}
+
+func TestExtend_tablePackageColumns(t *testing.T) {
+	s, p := testSBOM()
+	columns := func(_ sbom.SBOM) (*Extension, error) {
+		return &Extension{
+			PackageColumns: []Column{{Header: "Origin", Values: map[artifact.ID]string{p.ID(): "application"}}},
+		}, nil
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(syft.FormatByID(syft.TableFormatID), columns).Encode(buf, s))
+	assert.Regexp(t, `^NAME\s+VERSION\s+TYPE\s+ORIGIN\s*\nlodash\s+4\.17\.21\s+npm\s+application\s*\n$`, buf.String())
+}
+
+func TestExtend_vulnerabilitiesSyftJSON(t *testing.T) {
+	s, p := testSBOM()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(syft.FormatByID(syft.JSONFormatID), testVulnerability(p)).Encode(buf, s))
+
+	var doc struct {
+		Artifacts []struct {
+			ID string `json:"id"`
+		} `json:"artifacts"`
+		Vulnerabilities []struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			CVSS     struct {
+				Score float64 `json:"score"`
+			} `json:"cvss"`
+			Affects []struct {
+				Package string   `json:"package"`
+				Name    string   `json:"name"`
+				FixedIn []string `json:"fixedIn"`
+			} `json:"affects"`
+		} `json:"vulnerabilities"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	require.Len(t, doc.Artifacts, 1)
+	require.Len(t, doc.Vulnerabilities, 1)
+	v := doc.Vulnerabilities[0]
+	assert.Equal(t, "GHSA-35jh-r3h4-6jhm", v.ID)
+	assert.Equal(t, "high", v.Severity)
+	assert.Equal(t, 7.2, v.CVSS.Score)
+	require.Len(t, v.Affects, 1)
+	assert.Equal(t, doc.Artifacts[0].ID, v.Affects[0].Package)
+	assert.Equal(t, "lodash", v.Affects[0].Name)
+
+	// the extended document should still be readable as a syft JSON document
+	_, err := syft.FormatByID(syft.JSONFormatID).Decode(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+}
+
+func TestExtend_vulnerabilitiesCycloneDX(t *testing.T) {
+	s, p := testSBOM()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Extend(syft.FormatByID(syft.CycloneDxJSONFormatID), testVulnerability(p)).Encode(buf, s))
+
+	bom := cyclonedx.BOM{}
+	require.NoError(t, cyclonedx.NewBOMDecoder(buf, cyclonedx.BOMFileFormatJSON).Decode(&bom))
+
+	require.NotNil(t, bom.Vulnerabilities)
+	require.Len(t, *bom.Vulnerabilities, 1)
+	v := (*bom.Vulnerabilities)[0]
+	assert.Equal(t, "GHSA-35jh-r3h4-6jhm", v.ID)
+
+	require.NotNil(t, v.Affects)
+	require.Len(t, *v.Affects, 1)
+	assert.Equal(t, (*bom.Components)[0].BOMRef, (*v.Affects)[0].Ref)
+
+	require.NotNil(t, v.Ratings)
+	rating := (*v.Ratings)[0]
+	assert.Equal(t, cyclonedx.SeverityHigh, rating.Severity)
+	assert.Equal(t, cyclonedx.ScoringMethodCVSSv31, rating.Method)
+	require.NotNil(t, rating.Score)
+	assert.Equal(t, 7.2, *rating.Score)
+
+	require.NotNil(t, v.References)
+	assert.Equal(t, "CVE-2021-23337", (*v.References)[0].ID)
+	assert.Equal(t, "NVD", (*v.References)[0].Source.Name)
+	assert.Equal(t, "Upgrade to a fixed version: 4.17.22", v.Recommendation)
+}
+
+func TestExtend_vulnerabilityAnalysis(t *testing.T) {
+	s, p := testSBOM()
+	triaged := func(s sbom.SBOM) (*Extension, error) {
+		ext, err := testVulnerability(p)(s)
+		if err != nil {
+			return nil, err
+		}
+		ext.Vulnerabilities[0].Analysis = &Analysis{
+			Status:          "not_affected",
+			Justification:   "vulnerable_code_not_in_execute_path",
+			ImpactStatement: "the template function is never called",
+		}
+		return ext, nil
+	}
+
+	t.Run("table", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Extend(syft.FormatByID(syft.TableFormatID), triaged).Encode(buf, s))
+
+		assert.NotContains(t, buf.String(), "GHSA-35jh-r3h4-6jhm")
+		assert.Contains(t, buf.String(), "1 vulnerabilities not shown (not affected or fixed according to VEX statements)")
+	})
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Extend(syft.FormatByID(syft.CycloneDxJSONFormatID), triaged).Encode(buf, s))
+
+		bom := cyclonedx.BOM{}
+		require.NoError(t, cyclonedx.NewBOMDecoder(buf, cyclonedx.BOMFileFormatJSON).Decode(&bom))
+		require.NotNil(t, bom.Vulnerabilities)
+
+		analysis := (*bom.Vulnerabilities)[0].Analysis
+		require.NotNil(t, analysis)
+		assert.Equal(t, cyclonedx.IASNotAffected, analysis.State)
+		assert.Equal(t, cyclonedx.IAJCodeNotReachable, analysis.Justification)
+		assert.Equal(t, "the template function is never called", analysis.Detail)
+	})
+
+	t.Run("syft-json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Extend(syft.FormatByID(syft.JSONFormatID), triaged).Encode(buf, s))
+
+		var doc struct {
+			Vulnerabilities []struct {
+				VEX struct {
+					Status        string `json:"status"`
+					Justification string `json:"justification"`
+				} `json:"vex"`
+			} `json:"vulnerabilities"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		require.Len(t, doc.Vulnerabilities, 1)
+		assert.Equal(t, "not_affected", doc.Vulnerabilities[0].VEX.Status)
+		assert.Equal(t, "vulnerable_code_not_in_execute_path", doc.Vulnerabilities[0].VEX.Justification)
+	})
+}
+
+func testProvenance(_ sbom.SBOM) (*Extension, error) {
+	return &Extension{
+		Tools: []Tool{
+			{Vendor: "Docker", Name: "docker-sbom", Version: "v0.6.0"},
+			{Vendor: "Docker", Name: "docker-engine", Version: "20.10.12"},
+		},
+		Properties: []Property{
+			{Name: "docker:image:id", Value: "sha256:e7d92cdc71fe"},
+			{Name: "docker:scan:timestamp", Value: "2022-06-01T12:00:00Z"},
+		},
+	}, nil
+}
+
+func TestExtend_provenance(t *testing.T) {
+	s, _ := testSBOM()
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Extend(syft.FormatByID(syft.CycloneDxJSONFormatID), testProvenance).Encode(buf, s))
+
+		bom := cyclonedx.BOM{}
+		require.NoError(t, cyclonedx.NewBOMDecoder(buf, cyclonedx.BOMFileFormatJSON).Decode(&bom))
+
+		require.NotNil(t, bom.Metadata.Tools)
+		var names []string
+		for _, tool := range *bom.Metadata.Tools {
+			names = append(names, tool.Name)
+		}
+		assert.Equal(t, []string{"syft", "docker-sbom", "docker-engine"}, names)
+		assert.Equal(t, cyclonedx.Tool{Vendor: "Docker", Name: "docker-sbom", Version: "v0.6.0"}, (*bom.Metadata.Tools)[1])
+		require.NotNil(t, bom.Metadata.Properties)
+		assert.Equal(t, []cyclonedx.Property{
+			{Name: "docker:image:id", Value: "sha256:e7d92cdc71fe"},
+			{Name: "docker:scan:timestamp", Value: "2022-06-01T12:00:00Z"},
+		}, *bom.Metadata.Properties)
+	})
+
+	t.Run("spdx-json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Extend(syft.FormatByID(syft.SPDXJSONFormatID), testProvenance).Encode(buf, s))
+
+		var doc struct {
+			CreationInfo struct {
+				Creators []string `json:"creators"`
+				Comment  string   `json:"comment"`
+			} `json:"creationInfo"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+		assert.Contains(t, doc.CreationInfo.Creators, "Tool: docker-sbom-v0.6.0")
+		assert.Contains(t, doc.CreationInfo.Creators, "Tool: docker-engine-20.10.12")
+		assert.Equal(t, "docker:image:id: sha256:e7d92cdc71fe\ndocker:scan:timestamp: 2022-06-01T12:00:00Z", doc.CreationInfo.Comment)
+	})
+
+	t.Run("spdx-tag-value", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Extend(syft.FormatByID(syft.SPDXTagValueFormatID), testProvenance).Encode(buf, s))
+
+		assert.Contains(t, buf.String(), "Creator: Tool: docker-sbom-v0.6.0\n")
+		assert.Contains(t, buf.String(), "Creator: Tool: docker-engine-20.10.12\n")
+		assert.Contains(t, buf.String(), "CreatorComment: <text>docker:image:id: sha256:e7d92cdc71fe\ndocker:scan:timestamp: 2022-06-01T12:00:00Z</text>")
+	})
+}
+
+func Test_spdxCreatorTools(t *testing.T) {
+	assert.Equal(t,
+		[]string{"docker-sbom-v0.6.0", "docker-engine"},
+		spdxCreatorTools([]string{"syft-v0.46.3"}, []Tool{
+			{Name: "docker-sbom", Version: "v0.6.0"},
+			{Name: "syft", Version: "v0.46.3"},
+			{Name: "docker-engine"},
+			{Name: "docker-sbom", Version: "v0.6.0"},
+		}),
+	)
+}
@@ -0,0 +1,316 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal"
+	"github.com/docker/sbom-cli-plugin/internal/version"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/tvloader"
+	"github.com/spdx/tools-golang/tvsaver"
+
+	"github.com/anchore/syft/syft/artifact"
+)
+
+const (
+	spdxNoAssertion = "NOASSERTION"
+	spdxDocumentID  = "DOCUMENT"
+	spdxContains    = "CONTAINS"
+	spdxDescribes   = "DESCRIBES"
+	spdxOther       = "OTHER"
+)
+
+// this follows the same element ID sanitization that syft uses when deriving SPDX IDs from package IDs
+var spdxElementIDExpr = regexp.MustCompile("[^a-zA-Z0-9.-]")
+
+func spdxElementID(id string) string {
+	return spdxElementIDExpr.ReplaceAllString(id, "-")
+}
+
+func spdxAnnotator() string {
+	return fmt.Sprintf("%s-%s", internal.BinaryName, version.FromBuild().Version)
+}
+
+func spdxAnnotationComment(p Property) string {
+	return fmt.Sprintf("%s: %s", p.Name, p.Value)
+}
+
+// spdxCreatorTools returns the creator tools of the extension (without the "Tool: " prefix) that are not listed yet.
+func spdxCreatorTools(existing []string, tools []Tool) (result []string) {
+	for _, t := range tools {
+		creator := t.Name
+		if t.Version != "" {
+			creator = fmt.Sprintf("%s-%s", t.Name, t.Version)
+		}
+		if !containsString(existing, creator) && !containsString(result, creator) {
+			result = append(result, creator)
+		}
+	}
+	return result
+}
+
+// spdxCreatorComment describes the properties of the extension as a creator comment (one property per line), which
+// is appended to an existing comment.
+func spdxCreatorComment(existing string, props []Property) string {
+	lines := make([]string, 0, len(props)+1)
+	if existing != "" {
+		lines = append(lines, existing)
+	}
+	for _, p := range props {
+		lines = append(lines, spdxAnnotationComment(p))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// spdxRelationship is a simple (format neutral) representation of a relationship between two SPDX element IDs.
+type spdxRelationship struct {
+	from, to, kind string
+}
+
+// spdxRelationships returns the relationships needed to place all components of the extension within the document,
+// using the given function to find the element ID of each package.
+func spdxRelationships(ext Extension, packageElementID func(artifact.ID) (string, bool)) (result []spdxRelationship) {
+	if ext.Describes != nil {
+		result = append(result, spdxRelationship{from: spdxDocumentID, to: spdxElementID(ext.Describes.ID), kind: spdxDescribes})
+	}
+	for _, c := range ext.Components {
+		if ext.Describes != nil {
+			result = append(result, spdxRelationship{from: spdxElementID(ext.Describes.ID), to: spdxElementID(c.ID), kind: spdxContains})
+		}
+		for _, id := range c.Contains {
+			to, ok := packageElementID(id)
+			if !ok {
+				continue
+			}
+			result = append(result, spdxRelationship{from: spdxElementID(c.ID), to: to, kind: spdxContains})
+		}
+	}
+	return result
+}
+
+func spdxComponents(ext Extension) []Component {
+	var components []Component
+	if ext.Describes != nil {
+		components = append(components, *ext.Describes)
+	}
+	return append(components, ext.Components...)
+}
+
+func sortedPackageIDs(props map[artifact.ID][]Property) []artifact.ID {
+	ids := make([]artifact.ID, 0, len(props))
+	for id := range props {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func extendSPDXJSON(output io.Writer, document []byte, ext Extension) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return err
+	}
+
+	extendSPDXJSONDocument(doc, ext, time.Now().UTC().Format(time.RFC3339))
+
+	enc := json.NewEncoder(output)
+	// prevent > and < from being escaped in the payload (matching syft)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", " ")
+	return enc.Encode(doc)
+}
+
+func extendSPDXJSONDocument(doc map[string]interface{}, ext Extension, now string) {
+	if ext.Describes != nil {
+		doc["name"] = ext.Describes.Name
+	}
+
+	if creationInfo, ok := doc["creationInfo"].(map[string]interface{}); ok {
+		creators, _ := creationInfo["creators"].([]interface{})
+		var existing []string
+		for _, c := range creators {
+			if creator, ok := c.(string); ok {
+				existing = append(existing, strings.TrimPrefix(creator, "Tool: "))
+			}
+		}
+		for _, tool := range spdxCreatorTools(existing, ext.Tools) {
+			creators = append(creators, "Tool: "+tool)
+		}
+		creationInfo["creators"] = creators
+
+		if len(ext.Properties) > 0 {
+			comment, _ := creationInfo["comment"].(string)
+			creationInfo["comment"] = spdxCreatorComment(comment, ext.Properties)
+		}
+	}
+
+	packages, _ := doc["packages"].([]interface{})
+
+	annotation := func(p Property) map[string]interface{} {
+		return map[string]interface{}{
+			"annotationDate": now,
+			"annotationType": spdxOther,
+			"annotator":      "Tool: " + spdxAnnotator(),
+			"comment":        spdxAnnotationComment(p),
+		}
+	}
+
+	for _, p := range packages {
+		pkg, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, id := range sortedPackageIDs(ext.PackageProperties) {
+			if pkg["SPDXID"] != "SPDXRef-"+spdxElementID(string(id)) {
+				continue
+			}
+			annotations, _ := pkg["annotations"].([]interface{})
+			for _, prop := range ext.PackageProperties[id] {
+				annotations = append(annotations, annotation(prop))
+			}
+			pkg["annotations"] = annotations
+		}
+	}
+
+	for _, c := range spdxComponents(ext) {
+		pkg := map[string]interface{}{
+			"SPDXID":           "SPDXRef-" + spdxElementID(c.ID),
+			"name":             c.Name,
+			"downloadLocation": spdxNoAssertion,
+			"filesAnalyzed":    false,
+			"licenseConcluded": spdxNoAssertion,
+			"licenseDeclared":  spdxNoAssertion,
+			"copyrightText":    spdxNoAssertion,
+		}
+		if c.Version != "" {
+			pkg["versionInfo"] = c.Version
+		}
+		if len(c.Properties) > 0 {
+			var annotations []interface{}
+			for _, prop := range c.Properties {
+				annotations = append(annotations, annotation(prop))
+			}
+			pkg["annotations"] = annotations
+		}
+		packages = append(packages, pkg)
+	}
+	doc["packages"] = packages
+
+	packageElementID := func(id artifact.ID) (string, bool) {
+		return spdxElementID(string(id)), true
+	}
+
+	relationships, _ := doc["relationships"].([]interface{})
+	for _, r := range spdxRelationships(ext, packageElementID) {
+		relationships = append(relationships, map[string]interface{}{
+			"spdxElementId":      "SPDXRef-" + r.from,
+			"relatedSpdxElement": "SPDXRef-" + r.to,
+			"relationshipType":   r.kind,
+		})
+	}
+	if len(relationships) > 0 {
+		doc["relationships"] = relationships
+	}
+}
+
+func extendSPDXTagValue(output io.Writer, document []byte, ext Extension) error {
+	doc, err := tvloader.Load2_2(bytes.NewReader(document))
+	if err != nil {
+		return err
+	}
+
+	extendSPDXTagValueDocument(doc, ext, time.Now().UTC().Format(time.RFC3339))
+
+	return tvsaver.Save2_2(doc, output)
+}
+
+func extendSPDXTagValueDocument(doc *spdx.Document2_2, ext Extension, now string) {
+	if doc.CreationInfo != nil {
+		if ext.Describes != nil {
+			doc.CreationInfo.DocumentName = ext.Describes.Name
+		}
+		doc.CreationInfo.CreatorTools = append(doc.CreationInfo.CreatorTools, spdxCreatorTools(doc.CreationInfo.CreatorTools, ext.Tools)...)
+		if len(ext.Properties) > 0 {
+			doc.CreationInfo.CreatorComment = spdxCreatorComment(doc.CreationInfo.CreatorComment, ext.Properties)
+		}
+	}
+
+	if doc.Packages == nil {
+		doc.Packages = make(map[spdx.ElementID]*spdx.Package2_2)
+	}
+
+	annotate := func(id string, p Property) {
+		doc.Annotations = append(doc.Annotations, &spdx.Annotation2_2{
+			Annotator:                spdxAnnotator(),
+			AnnotatorType:            "Tool",
+			AnnotationDate:           now,
+			AnnotationType:           spdxOther,
+			AnnotationSPDXIdentifier: spdx.MakeDocElementID("", id),
+			AnnotationComment:        spdxAnnotationComment(p),
+		})
+	}
+
+	// the tag-value encoder derives package element IDs from the package type, name, and ID (unlike the JSON encoder,
+	// which uses the package ID alone), so the element ID for each package must be looked up by the ID suffix
+	elementIDs := make(map[string]string)
+	for elementID := range doc.Packages {
+		fields := strings.Split(string(elementID), "-")
+		elementIDs[fields[len(fields)-1]] = string(elementID)
+	}
+	packageElementID := func(id artifact.ID) (string, bool) {
+		elementID, ok := elementIDs[spdxElementID(string(id))]
+		return elementID, ok
+	}
+
+	for _, id := range sortedPackageIDs(ext.PackageProperties) {
+		elementID, ok := packageElementID(id)
+		if !ok {
+			continue
+		}
+		for _, prop := range ext.PackageProperties[id] {
+			annotate(elementID, prop)
+		}
+	}
+
+	for _, c := range spdxComponents(ext) {
+		elementID := spdxElementID(c.ID)
+		doc.Packages[spdx.ElementID(elementID)] = &spdx.Package2_2{
+			PackageName:               c.Name,
+			PackageSPDXIdentifier:     spdx.ElementID(elementID),
+			PackageVersion:            c.Version,
+			PackageDownloadLocation:   spdxNoAssertion,
+			FilesAnalyzed:             false,
+			IsFilesAnalyzedTagPresent: true,
+			PackageLicenseConcluded:   spdxNoAssertion,
+			PackageLicenseDeclared:    spdxNoAssertion,
+			PackageCopyrightText:      spdxNoAssertion,
+		}
+		for _, prop := range c.Properties {
+			annotate(elementID, prop)
+		}
+	}
+
+	for _, r := range spdxRelationships(ext, packageElementID) {
+		doc.Relationships = append(doc.Relationships, &spdx.Relationship2_2{
+			RefA:         spdx.MakeDocElementID("", r.from),
+			RefB:         spdx.MakeDocElementID("", r.to),
+			Relationship: r.kind,
+		})
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,404 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/docker/sbom-cli-plugin/internal/table"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// vulnerabilityRow is a single vulnerability affecting a single package.
+type vulnerabilityRow struct {
+	pkg.Package
+	vulnerability Vulnerability
+	fixedIn       []string
+}
+
+func vulnerabilityRows(s sbom.SBOM, vulnerabilities []Vulnerability) (rows []vulnerabilityRow) {
+	if s.Artifacts.PackageCatalog == nil {
+		return nil
+	}
+	for _, v := range vulnerabilities {
+		if v.Analysis.Resolved() {
+			continue
+		}
+		for _, a := range v.Affects {
+			p := s.Artifacts.PackageCatalog.Package(a.Package)
+			if p == nil {
+				continue
+			}
+			rows = append(rows, vulnerabilityRow{Package: *p, vulnerability: v, fixedIn: a.FixedIn})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch {
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		case a.Version != b.Version:
+			return a.Version < b.Version
+		}
+		return a.vulnerability.ID < b.vulnerability.ID
+	})
+	return rows
+}
+
+// extendTable adds a table of the vulnerabilities after the table of packages. Vulnerabilities that have been
+// resolved by triage are left out of the table (only the number of them is shown). The table of packages is replaced
+// when there are package columns to add.
+func extendTable(output io.Writer, document []byte, s sbom.SBOM, ext Extension) error {
+	if len(ext.PackageColumns) > 0 {
+		if err := writePackageTable(output, s, ext.PackageColumns); err != nil {
+			return err
+		}
+	} else if _, err := output.Write(document); err != nil {
+		return err
+	}
+
+	rows := vulnerabilityRows(s, ext.Vulnerabilities)
+	if len(rows) > 0 {
+		cells := make([][]string, 0, len(rows))
+		for _, r := range rows {
+			cells = append(cells, []string{r.Name, r.Version, strings.Join(r.fixedIn, ", "), string(r.Type), r.vulnerability.ID, r.vulnerability.Severity})
+		}
+
+		if _, err := fmt.Fprintln(output); err != nil {
+			return err
+		}
+		if err := table.Write(output, []string{"Name", "Installed", "Fixed-In", "Type", "Vulnerability", "Severity"}, cells); err != nil {
+			return err
+		}
+	}
+
+	var resolved int
+	for _, v := range ext.Vulnerabilities {
+		if v.Analysis.Resolved() {
+			resolved += len(v.Affects)
+		}
+	}
+	if resolved > 0 {
+		_, err := fmt.Fprintf(output, "\n%d vulnerabilities not shown (not affected or fixed according to VEX statements)\n", resolved)
+		return err
+	}
+	return nil
+}
+
+// writePackageTable writes the table of packages as syft does (sorted by all columns and without duplicate rows), with
+// the additional columns after the name, version, and type.
+func writePackageTable(output io.Writer, s sbom.SBOM, columns []Column) error {
+	header := []string{"Name", "Version", "Type"}
+	for _, c := range columns {
+		header = append(header, c.Header)
+	}
+
+	var rows [][]string
+	if s.Artifacts.PackageCatalog != nil {
+		for _, p := range s.Artifacts.PackageCatalog.Sorted() {
+			row := []string{p.Name, p.Version, string(p.Type)}
+			for _, c := range columns {
+				row = append(row, c.Values[p.ID()])
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(output, "No packages discovered")
+		return err
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for col := range header {
+			if rows[i][col] != rows[j][col] {
+				return rows[i][col] < rows[j][col]
+			}
+		}
+		return false
+	})
+
+	seen := make(map[string]struct{})
+	unique := rows[:0]
+	for _, row := range rows {
+		key := strings.Join(row, "|")
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, row)
+	}
+
+	return table.Write(output, header, unique)
+}
+
+type syftJSONVulnerability struct {
+	ID         string                    `json:"id"`
+	DataSource string                    `json:"dataSource"`
+	Namespace  string                    `json:"namespace"`
+	Aliases    []string                  `json:"aliases,omitempty"`
+	Summary    string                    `json:"summary,omitempty"`
+	Severity   string                    `json:"severity"`
+	CVSS       *syftJSONCVSS             `json:"cvss,omitempty"`
+	URLs       []string                  `json:"urls,omitempty"`
+	Affects    []syftJSONAffectedPackage `json:"affects"`
+	VEX        *syftJSONAnalysis         `json:"vex,omitempty"`
+}
+
+type syftJSONAnalysis struct {
+	Status          string `json:"status"`
+	Justification   string `json:"justification,omitempty"`
+	ImpactStatement string `json:"impactStatement,omitempty"`
+	ActionStatement string `json:"actionStatement,omitempty"`
+}
+
+type syftJSONCVSS struct {
+	Score  float64 `json:"score"`
+	Vector string  `json:"vector,omitempty"`
+}
+
+type syftJSONAffectedPackage struct {
+	Package artifact.ID `json:"package"`
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	FixedIn []string    `json:"fixedIn,omitempty"`
+}
+
+// extendSyftJSON adds a top-level "vulnerabilities" section to a syft JSON document, where each affected package
+// refers to the ID of a package in the "artifacts" section.
+func extendSyftJSON(output io.Writer, document []byte, s sbom.SBOM, ext Extension) error {
+	if len(ext.Vulnerabilities) == 0 {
+		_, err := output.Write(document)
+		return err
+	}
+
+	vulnerabilities := make([]syftJSONVulnerability, 0, len(ext.Vulnerabilities))
+	for _, v := range ext.Vulnerabilities {
+		entry := syftJSONVulnerability{
+			ID:         v.ID,
+			DataSource: v.URL,
+			Namespace:  v.Source,
+			Aliases:    v.Aliases,
+			Summary:    v.Summary,
+			Severity:   v.Severity,
+			URLs:       v.References,
+		}
+		if v.Vector != "" {
+			entry.CVSS = &syftJSONCVSS{Score: v.Score, Vector: v.Vector}
+		}
+		if v.Analysis != nil {
+			entry.VEX = &syftJSONAnalysis{
+				Status:          v.Analysis.Status,
+				Justification:   v.Analysis.Justification,
+				ImpactStatement: v.Analysis.ImpactStatement,
+				ActionStatement: v.Analysis.ActionStatement,
+			}
+		}
+		for _, a := range v.Affects {
+			affected := syftJSONAffectedPackage{Package: a.Package, FixedIn: a.FixedIn}
+			if s.Artifacts.PackageCatalog != nil {
+				if p := s.Artifacts.PackageCatalog.Package(a.Package); p != nil {
+					affected.Name = p.Name
+					affected.Version = p.Version
+				}
+			}
+			entry.Affects = append(entry.Affects, affected)
+		}
+		vulnerabilities = append(vulnerabilities, entry)
+	}
+
+	// the section is appended to the document as-is (rather than decoding and re-encoding the document) to preserve
+	// the order of the existing sections
+	trimmed := bytes.TrimRight(document, " \t\r\n")
+	if !bytes.HasSuffix(trimmed, []byte("}")) {
+		return fmt.Errorf("unexpected end of document")
+	}
+	trimmed = bytes.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n")
+
+	section := &bytes.Buffer{}
+	enc := json.NewEncoder(section)
+	enc.SetEscapeHTML(false)
+	// the section is nested one level within the document, which is indented with a single space
+	enc.SetIndent(" ", " ")
+	if err := enc.Encode(vulnerabilities); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(output, "%s,\n \"vulnerabilities\": %s\n}\n", trimmed, bytes.TrimRight(section.Bytes(), "\n"))
+	return err
+}
+
+func toCycloneDXVulnerabilities(components *[]cyclonedx.Component, vulnerabilities []Vulnerability) *[]cyclonedx.Vulnerability {
+	bomRefs := make(map[artifact.ID]string)
+	if components != nil {
+		for _, c := range *components {
+			bomRefs[cycloneDXPackageID(c.BOMRef)] = c.BOMRef
+		}
+	}
+
+	var result []cyclonedx.Vulnerability
+	// a vulnerability is listed more than once when the affected packages have been triaged differently
+	occurrences := make(map[string]int)
+	for _, v := range vulnerabilities {
+		var affects []cyclonedx.Affects
+		var fixedIn []string
+		for _, a := range v.Affects {
+			ref, ok := bomRefs[a.Package]
+			if !ok {
+				continue
+			}
+			affects = append(affects, cyclonedx.Affects{Ref: ref})
+			fixedIn = append(fixedIn, a.FixedIn...)
+		}
+		if len(affects) == 0 {
+			continue
+		}
+
+		bomRef := v.ID
+		if n := occurrences[v.ID]; n > 0 {
+			bomRef = fmt.Sprintf("%s-%d", v.ID, n+1)
+		}
+		occurrences[v.ID]++
+
+		vulnerability := cyclonedx.Vulnerability{
+			BOMRef:      bomRef,
+			ID:          v.ID,
+			Source:      &cyclonedx.Source{Name: v.Source, URL: v.URL},
+			Description: v.Summary,
+			Affects:     &affects,
+			Analysis:    toCycloneDXAnalysis(v.Analysis),
+		}
+
+		if len(fixedIn) > 0 {
+			vulnerability.Recommendation = "Upgrade to a fixed version: " + strings.Join(dedupe(fixedIn), ", ")
+		}
+
+		rating := cyclonedx.VulnerabilityRating{
+			Source:   vulnerability.Source,
+			Severity: cyclonedx.Severity(v.Severity),
+			Vector:   v.Vector,
+			Method:   cycloneDXScoringMethod(v.Vector),
+		}
+		if v.Vector != "" {
+			score := v.Score
+			rating.Score = &score
+		}
+		vulnerability.Ratings = &[]cyclonedx.VulnerabilityRating{rating}
+
+		if len(v.Aliases) > 0 {
+			var references []cyclonedx.VulnerabilityReference
+			for _, alias := range v.Aliases {
+				references = append(references, cyclonedx.VulnerabilityReference{ID: alias, Source: aliasSource(alias)})
+			}
+			vulnerability.References = &references
+		}
+
+		if len(v.References) > 0 {
+			var advisories []cyclonedx.Advisory
+			for _, url := range v.References {
+				advisories = append(advisories, cyclonedx.Advisory{URL: url})
+			}
+			vulnerability.Advisories = &advisories
+		}
+
+		result = append(result, vulnerability)
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return &result
+}
+
+// cycloneDXStates maps OpenVEX statuses to CycloneDX impact analysis states.
+var cycloneDXStates = map[string]cyclonedx.ImpactAnalysisState{
+	"not_affected":        cyclonedx.IASNotAffected,
+	"affected":            cyclonedx.IASExploitable,
+	"fixed":               cyclonedx.IASResolved,
+	"under_investigation": cyclonedx.IASInTriage,
+}
+
+// cycloneDXJustifications maps OpenVEX justifications to the closest CycloneDX justification.
+var cycloneDXJustifications = map[string]cyclonedx.ImpactAnalysisJustification{
+	"component_not_present":                             cyclonedx.IAJCodeNotPresent,
+	"vulnerable_code_not_present":                       cyclonedx.IAJCodeNotPresent,
+	"vulnerable_code_not_in_execute_path":               cyclonedx.IAJCodeNotReachable,
+	"vulnerable_code_cannot_be_controlled_by_adversary": cyclonedx.IAJRequiresEnvironment,
+	"inline_mitigations_already_exist":                  cyclonedx.IAJProtectedByMitigatingControl,
+}
+
+func toCycloneDXAnalysis(a *Analysis) *cyclonedx.VulnerabilityAnalysis {
+	if a == nil {
+		return nil
+	}
+
+	analysis := cyclonedx.VulnerabilityAnalysis{State: cycloneDXStates[a.Status]}
+
+	details := []string{a.ImpactStatement, a.ActionStatement}
+	if j, ok := cycloneDXJustifications[a.Justification]; ok {
+		analysis.Justification = j
+	} else if a.Justification != "" {
+		switch j := cyclonedx.ImpactAnalysisJustification(a.Justification); j {
+		case cyclonedx.IAJCodeNotPresent, cyclonedx.IAJCodeNotReachable, cyclonedx.IAJRequiresConfiguration,
+			cyclonedx.IAJRequiresDependency, cyclonedx.IAJRequiresEnvironment, cyclonedx.IAJProtectedByCompiler,
+			cyclonedx.IAJProtectedAtRuntime, cyclonedx.IAJProtectedAtPerimeter, cyclonedx.IAJProtectedByMitigatingControl:
+			// the justification was already a CycloneDX justification (e.g. from a CycloneDX VEX document)
+			analysis.Justification = j
+		default:
+			// keep the justification even though there is no equivalent
+			details = append([]string{"justification: " + a.Justification}, details...)
+		}
+	}
+
+	var detail []string
+	for _, d := range details {
+		if d != "" {
+			detail = append(detail, d)
+		}
+	}
+	analysis.Detail = strings.Join(detail, "\n")
+	return &analysis
+}
+
+func cycloneDXScoringMethod(vector string) cyclonedx.ScoringMethod {
+	switch {
+	case vector == "":
+		return ""
+	case strings.HasPrefix(vector, "CVSS:3.1/"):
+		return cyclonedx.ScoringMethodCVSSv31
+	case strings.HasPrefix(vector, "CVSS:3.0/"):
+		return cyclonedx.ScoringMethodCVSSv3
+	}
+	return cyclonedx.ScoringMethodOther
+}
+
+// aliasSource returns the source of an alias of a vulnerability based on the ID prefix.
+func aliasSource(id string) *cyclonedx.Source {
+	switch {
+	case strings.HasPrefix(id, "CVE-"):
+		return &cyclonedx.Source{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + id}
+	case strings.HasPrefix(id, "GHSA-"):
+		return &cyclonedx.Source{Name: "GitHub", URL: "https://github.com/advisories/" + id}
+	}
+	return &cyclonedx.Source{Name: "OSV", URL: "https://osv.dev/vulnerability/" + id}
+}
+
+func dedupe(values []string) (result []string) {
+	seen := make(map[string]struct{})
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+/*
+Package imageconfig describes the image an SBOM was generated from by the labels, annotations, and runtime
+configuration recorded in the image config and manifest, so that the SBOM can be linked to the source the image was
+built from.
+*/
+package imageconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/source"
+)
+
+const (
+	// LabelProperty is the prefix of the properties with the labels of the image (e.g. "docker:image:label:org.opencontainers.image.source").
+	LabelProperty = "docker:image:label:"
+	// AnnotationProperty is the prefix of the properties with the annotations of the image manifest.
+	AnnotationProperty = "docker:image:annotation:"
+	// EnvProperty is the property naming an environment variable set by the image (one per variable, without values).
+	EnvProperty = "docker:image:env"
+	// EntrypointProperty is the property with the entrypoint of the image (as a JSON array).
+	EntrypointProperty = "docker:image:entrypoint"
+	// UserProperty is the property with the user the image runs as.
+	UserProperty = "docker:image:user"
+	// ExposedPortProperty is the property naming a port exposed by the image (one per port, e.g. "80/tcp").
+	ExposedPortProperty = "docker:image:exposed-port"
+)
+
+// DefaultEnvDenylist are the patterns of environment variable names that are left out of the SBOM, since the variables
+// could contain secrets.
+var DefaultEnvDenylist = []string{"*PASSWORD*", "*PASSWD*", "*SECRET*", "*TOKEN*", "*KEY*", "*CREDENTIAL*", "*AUTH*", "*PRIVATE*"}
+
+// ValidateEnvDenylist checks that all patterns are valid (see path.Match).
+func ValidateEnvDenylist(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad environment variable pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Describe returns the image of the source as a component with the image configuration as properties, where
+// environment variables matching any of the denylist patterns (case-insensitive) are left out. A nil component is
+// returned when the source is not an image.
+func Describe(src source.Metadata, envDenylist []string) (*formats.Component, error) {
+	if src.Scheme != source.ImageScheme {
+		return nil, nil
+	}
+	img := src.ImageMetadata
+
+	id, err := artifact.IDByHash(img.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to identify image %q: %w", img.ID, err)
+	}
+
+	// the component matches the one syft uses to describe the image
+	component := &formats.Component{
+		ID:      string(id),
+		Type:    "container",
+		Name:    img.UserInput,
+		Version: img.ManifestDigest,
+	}
+
+	if len(img.RawConfig) > 0 {
+		cfg, err := v1.ParseConfigFile(bytes.NewReader(img.RawConfig))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse image config: %w", err)
+		}
+		props, err := configProperties(cfg.Config, envDenylist)
+		if err != nil {
+			return nil, err
+		}
+		component.Properties = append(component.Properties, props...)
+	}
+
+	if len(img.RawManifest) > 0 {
+		manifest, err := v1.ParseManifest(bytes.NewReader(img.RawManifest))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse image manifest: %w", err)
+		}
+		component.Properties = append(component.Properties, mapProperties(AnnotationProperty, manifest.Annotations)...)
+	}
+
+	return component, nil
+}
+
+func configProperties(cfg v1.Config, envDenylist []string) ([]formats.Property, error) {
+	props := mapProperties(LabelProperty, cfg.Labels)
+
+	for _, env := range cfg.Env {
+		name := strings.SplitN(env, "=", 2)[0]
+		if denied(name, envDenylist) {
+			continue
+		}
+		props = append(props, formats.Property{Name: EnvProperty, Value: name})
+	}
+
+	if len(cfg.Entrypoint) > 0 {
+		entrypoint, err := json.Marshal(cfg.Entrypoint)
+		if err != nil {
+			return nil, fmt.Errorf("unable to describe image entrypoint: %w", err)
+		}
+		props = append(props, formats.Property{Name: EntrypointProperty, Value: string(entrypoint)})
+	}
+
+	if cfg.User != "" {
+		props = append(props, formats.Property{Name: UserProperty, Value: cfg.User})
+	}
+
+	ports := make([]string, 0, len(cfg.ExposedPorts))
+	for port := range cfg.ExposedPorts {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		props = append(props, formats.Property{Name: ExposedPortProperty, Value: port})
+	}
+
+	return props, nil
+}
+
+// mapProperties returns a property for each entry of the map (sorted by key), named by the key with the given prefix.
+func mapProperties(prefix string, values map[string]string) []formats.Property {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	props := make([]formats.Property, 0, len(keys))
+	for _, key := range keys {
+		props = append(props, formats.Property{Name: prefix + key, Value: values[key]})
+	}
+	return props
+}
+
+func denied(name string, denylist []string) bool {
+	for _, pattern := range denylist {
+		// patterns are validated by ValidateEnvDenylist
+		if matched, _ := path.Match(strings.ToUpper(pattern), strings.ToUpper(name)); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package imageconfig
+
+import (
+	"testing"
+
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/source"
+)
+
+const testConfig = `{
+  "architecture": "amd64",
+  "os": "linux",
+  "config": {
+    "User": "app",
+    "Env": ["PATH=/usr/local/bin:/usr/bin", "GITHUB_TOKEN=ghp_secret", "db_password=hunter2", "APP_PORT=8080"],
+    "Entrypoint": ["/docker-entrypoint.sh", "--serve"],
+    "ExposedPorts": {"8080/tcp": {}, "443/tcp": {}},
+    "Labels": {
+      "org.opencontainers.image.version": "1.2.3",
+      "org.opencontainers.image.source": "https://github.com/docker/sbom-cli-plugin",
+      "org.opencontainers.image.revision": "a1b2c3d"
+    }
+  },
+  "rootfs": {"type": "layers", "diff_ids": []}
+}`
+
+const testManifest = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "config": {"mediaType": "application/vnd.oci.image.config.v1+json", "size": 0, "digest": "sha256:e66264b98777e12192600bf9b4d663655c98a090072e1bab49e233d7531d1294"},
+  "layers": [],
+  "annotations": {"org.opencontainers.image.created": "2022-06-01T12:00:00Z"}
+}`
+
+func TestDescribe(t *testing.T) {
+	src := source.Metadata{
+		Scheme: source.ImageScheme,
+		ImageMetadata: source.ImageMetadata{
+			UserInput:      "app:latest",
+			ID:             "sha256:e66264b98777e12192600bf9b4d663655c98a090072e1bab49e233d7531d1294",
+			ManifestDigest: "sha256:686d8c9dfa6f3ccfc8230bc3178d23f84eeaf7e457f36f271ab1acc53015037c",
+			RawConfig:      []byte(testConfig),
+			RawManifest:    []byte(testManifest),
+		},
+	}
+
+	component, err := Describe(src, DefaultEnvDenylist)
+	require.NoError(t, err)
+
+	id, err := artifact.IDByHash(src.ImageMetadata.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &formats.Component{
+		ID:      string(id),
+		Type:    "container",
+		Name:    "app:latest",
+		Version: "sha256:686d8c9dfa6f3ccfc8230bc3178d23f84eeaf7e457f36f271ab1acc53015037c",
+		Properties: []formats.Property{
+			{Name: "docker:image:label:org.opencontainers.image.revision", Value: "a1b2c3d"},
+			{Name: "docker:image:label:org.opencontainers.image.source", Value: "https://github.com/docker/sbom-cli-plugin"},
+			{Name: "docker:image:label:org.opencontainers.image.version", Value: "1.2.3"},
+			{Name: "docker:image:env", Value: "PATH"},
+			{Name: "docker:image:env", Value: "APP_PORT"},
+			{Name: "docker:image:entrypoint", Value: `["/docker-entrypoint.sh","--serve"]`},
+			{Name: "docker:image:user", Value: "app"},
+			{Name: "docker:image:exposed-port", Value: "443/tcp"},
+			{Name: "docker:image:exposed-port", Value: "8080/tcp"},
+			{Name: "docker:image:annotation:org.opencontainers.image.created", Value: "2022-06-01T12:00:00Z"},
+		},
+	}, component)
+
+	// without a denylist all environment variables are included (still without values)
+	component, err = Describe(src, nil)
+	require.NoError(t, err)
+	var env []string
+	for _, p := range component.Properties {
+		if p.Name == EnvProperty {
+			env = append(env, p.Value)
+		}
+	}
+	assert.Equal(t, []string{"PATH", "GITHUB_TOKEN", "db_password", "APP_PORT"}, env)
+}
+
+func TestDescribe_notAnImage(t *testing.T) {
+	component, err := Describe(source.Metadata{Scheme: source.DirectoryScheme, Path: "/app"}, DefaultEnvDenylist)
+	require.NoError(t, err)
+	assert.Nil(t, component)
+}
+
+func TestDescribe_badConfig(t *testing.T) {
+	_, err := Describe(source.Metadata{
+		Scheme:        source.ImageScheme,
+		ImageMetadata: source.ImageMetadata{ID: "sha256:e66264b9", RawConfig: []byte("{")},
+	}, DefaultEnvDenylist)
+	assert.ErrorContains(t, err, "unable to parse image config")
+}
+
+func TestValidateEnvDenylist(t *testing.T) {
+	assert.NoError(t, ValidateEnvDenylist(DefaultEnvDenylist))
+	assert.Error(t, ValidateEnvDenylist([]string{"[A-"}))
+}
@@ -0,0 +1,147 @@
+/*
+Package inventory maintains a directory of SBOM documents for a set of images, along with an index file that maps
+image IDs, repo tags, and repo digests to the SBOM document for each image.
+*/
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// IndexFileName is the name of the index file within an inventory directory.
+const IndexFileName = "index.json"
+
+// Image is a single inventoried image.
+type Image struct {
+	ID          string        `json:"id"`          // the image ID (config digest)
+	RepoTags    []string      `json:"repoTags"`    // all tags that referenced the image when it was last seen
+	RepoDigests []string      `json:"repoDigests"` // all repo digests that referenced the image when it was last seen
+	Format      sbom.FormatID `json:"format"`      // the format of the SBOM document
+	SBOM        string        `json:"sbom"`        // the path to the SBOM document, relative to the inventory directory
+	Created     time.Time     `json:"created"`     // when the SBOM document was generated
+}
+
+// Index describes all SBOM documents within an inventory directory.
+type Index struct {
+	Images []Image `json:"images"`
+	// References maps every repo tag, repo digest, and image ID to the path of the SBOM document for that image
+	// (derived from Images when the index is saved).
+	References map[string]string `json:"references"`
+}
+
+// Load reads the index file in the given inventory directory. A missing index file results in an empty index.
+func Load(dir string) (*Index, error) {
+	by, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read inventory index: %w", err)
+	}
+
+	var idx Index
+	if err := json.Unmarshal(by, &idx); err != nil {
+		return nil, fmt.Errorf("unable to parse inventory index %q: %w", filepath.Join(dir, IndexFileName), err)
+	}
+	return &idx, nil
+}
+
+// Find returns the inventoried image with the given image ID (or nil if the image has not been inventoried).
+func (idx *Index) Find(id string) *Image {
+	for i := range idx.Images {
+		if idx.Images[i].ID == id {
+			return &idx.Images[i]
+		}
+	}
+	return nil
+}
+
+// Inventoried indicates if there is an SBOM document in the given format within the inventory directory for the
+// given image ID.
+func (idx *Index) Inventoried(dir, id string, format sbom.FormatID) bool {
+	img := idx.Find(id)
+	if img == nil || img.Format != format {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, img.SBOM))
+	return err == nil
+}
+
+// Add adds the given image to the index, replacing any existing entry with the same image ID.
+func (idx *Index) Add(img Image) {
+	if existing := idx.Find(img.ID); existing != nil {
+		*existing = img
+		return
+	}
+	idx.Images = append(idx.Images, img)
+}
+
+// Save writes the index file to the given inventory directory.
+func (idx *Index) Save(dir string) error {
+	sort.Slice(idx.Images, func(i, j int) bool {
+		return idx.Images[i].ID < idx.Images[j].ID
+	})
+
+	idx.References = make(map[string]string)
+	for _, img := range idx.Images {
+		idx.References[img.ID] = img.SBOM
+		for _, ref := range img.RepoDigests {
+			idx.References[ref] = img.SBOM
+		}
+	}
+	// tags are mutable, so they may have moved to another image since an image was inventoried. Tags are added last
+	// (ordered by creation time) so the most recently inventoried image wins.
+	images := make([]Image, len(idx.Images))
+	copy(images, idx.Images)
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Created.Before(images[j].Created)
+	})
+	for _, img := range images {
+		for _, ref := range img.RepoTags {
+			idx.References[ref] = img.SBOM
+		}
+	}
+
+	by, err := json.MarshalIndent(idx, "", " ")
+	if err != nil {
+		return fmt.Errorf("unable to encode inventory index: %w", err)
+	}
+
+	// write to a temporary file first so that an interrupted run never leaves a truncated index behind
+	path := filepath.Join(dir, IndexFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(by, '\n'), 0600); err != nil {
+		return fmt.Errorf("unable to write inventory index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("unable to write inventory index: %w", err)
+	}
+	return nil
+}
+
+// FileName returns the file name of the SBOM document for the given image ID in the given format.
+func FileName(id string, format sbom.FormatID) string {
+	return strings.ReplaceAll(id, ":", "-") + fileExtension(format)
+}
+
+func fileExtension(format sbom.FormatID) string {
+	switch format {
+	case syft.JSONFormatID, syft.SPDXJSONFormatID, syft.CycloneDxJSONFormatID, syft.GitHubID:
+		return ".json"
+	case syft.CycloneDxXMLFormatID:
+		return ".xml"
+	case syft.SPDXTagValueFormatID:
+		return ".spdx"
+	}
+	return ".txt"
+}
@@ -0,0 +1,73 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+)
+
+func TestIndex_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	idx, err := Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, idx.Images)
+
+	now := time.Now().UTC()
+	idx.Add(Image{
+		ID:          "sha256:bbb",
+		RepoTags:    []string{"app:latest", "app:1.0"},
+		RepoDigests: []string{"registry.example.com/app@sha256:111"},
+		Format:      syft.JSONFormatID,
+		SBOM:        FileName("sha256:bbb", syft.JSONFormatID),
+		Created:     now.Add(-time.Hour),
+	})
+	idx.Add(Image{
+		ID:       "sha256:aaa",
+		RepoTags: []string{"app:latest"},
+		Format:   syft.JSONFormatID,
+		SBOM:     FileName("sha256:aaa", syft.JSONFormatID),
+		Created:  now,
+	})
+	require.NoError(t, idx.Save(dir))
+
+	loaded, err := Load(dir)
+	require.NoError(t, err)
+
+	require.Len(t, loaded.Images, 2)
+	assert.Equal(t, "sha256:aaa", loaded.Images[0].ID)
+	assert.Equal(t, map[string]string{
+		"sha256:aaa":                          "sha256-aaa.json",
+		"sha256:bbb":                          "sha256-bbb.json",
+		"app:1.0":                             "sha256-bbb.json",
+		"registry.example.com/app@sha256:111": "sha256-bbb.json",
+		// the tag was moved to the most recently inventoried image
+		"app:latest": "sha256-aaa.json",
+	}, loaded.References)
+}
+
+func TestIndex_Inventoried(t *testing.T) {
+	dir := t.TempDir()
+	idx := &Index{}
+	idx.Add(Image{ID: "sha256:aaa", Format: syft.JSONFormatID, SBOM: "sha256-aaa.json"})
+
+	assert.False(t, idx.Inventoried(dir, "sha256:aaa", syft.JSONFormatID), "the SBOM document is missing")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sha256-aaa.json"), []byte("{}"), 0600))
+	assert.True(t, idx.Inventoried(dir, "sha256:aaa", syft.JSONFormatID))
+	assert.False(t, idx.Inventoried(dir, "sha256:aaa", syft.SPDXJSONFormatID), "the SBOM document is in another format")
+	assert.False(t, idx.Inventoried(dir, "sha256:bbb", syft.JSONFormatID))
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "sha256-aaa.json", FileName("sha256:aaa", syft.CycloneDxJSONFormatID))
+	assert.Equal(t, "sha256-aaa.xml", FileName("sha256:aaa", syft.CycloneDxXMLFormatID))
+	assert.Equal(t, "sha256-aaa.spdx", FileName("sha256:aaa", syft.SPDXTagValueFormatID))
+	assert.Equal(t, "sha256-aaa.txt", FileName("sha256:aaa", syft.TableFormatID))
+}
@@ -23,6 +23,10 @@ type LogrusConfig struct {
 	Structured    bool
 	Level         logrus.Level
 	FileLocation  string
+	AppendFile    bool                    // append to an existing log file instead of truncating it
+	MaxFileSize   uint64                  // rotate the log file when it would exceed this size (0 for no rotation)
+	MaxBackups    int                     // the number of rotated log files to keep
+	LibraryLevels map[string]logrus.Level // the log level of each library logger (see Library)
 }
 
 // LogrusLogger contains all runtime values for using Logrus with the configured output target and input configuration values.
@@ -33,8 +37,10 @@ type LogrusLogger struct {
 }
 
 // LogrusNestedLogger is a wrapper for Logrus to enable nested logging configuration (loggers that always attach key-value pairs to all log entries)
+// with their own log level (entries less severe than the level are dropped).
 type LogrusNestedLogger struct {
 	Logger *logrus.Entry
+	Level  logrus.Level
 }
 
 // NewLogrusLogger creates a new LogrusLogger with the given configuration
@@ -44,25 +50,25 @@ func NewLogrusLogger(cfg LogrusConfig) *LogrusLogger {
 	var output io.Writer
 	switch {
 	case cfg.EnableConsole && cfg.EnableFile:
-		logFile, err := os.OpenFile(cfg.FileLocation, os.O_WRONLY|os.O_CREATE, defaultLogFilePermissions)
-		if err != nil {
-			panic(fmt.Errorf("unable to setup log file: %w", err))
-		}
-		output = io.MultiWriter(os.Stderr, logFile)
+		output = io.MultiWriter(os.Stderr, newLogFile(cfg))
 	case cfg.EnableConsole:
 		output = os.Stderr
 	case cfg.EnableFile:
-		logFile, err := os.OpenFile(cfg.FileLocation, os.O_WRONLY|os.O_CREATE, defaultLogFilePermissions)
-		if err != nil {
-			panic(fmt.Errorf("unable to setup log file: %w", err))
-		}
-		output = logFile
+		output = newLogFile(cfg)
 	default:
 		output = ioutil.Discard
 	}
 
 	appLogger.SetOutput(output)
-	appLogger.SetLevel(cfg.Level)
+	// the logger lets through the entries of the most verbose logger, the application and library loggers filter
+	// entries by their own levels
+	level := cfg.Level
+	for _, l := range cfg.LibraryLevels {
+		if l > level {
+			level = l
+		}
+	}
+	appLogger.SetLevel(level)
 
 	if cfg.Structured {
 		appLogger.SetFormatter(&logrus.JSONFormatter{
@@ -86,82 +92,159 @@ func NewLogrusLogger(cfg LogrusConfig) *LogrusLogger {
 	}
 }
 
+func newLogFile(cfg LogrusConfig) io.Writer {
+	if cfg.MaxFileSize > 0 {
+		logFile, err := openRotatingFile(cfg.FileLocation, cfg.AppendFile, cfg.MaxFileSize, cfg.MaxBackups)
+		if err != nil {
+			panic(fmt.Errorf("unable to setup log file: %w", err))
+		}
+		return logFile
+	}
+
+	logFile, err := openLogFile(cfg.FileLocation, cfg.AppendFile)
+	if err != nil {
+		panic(fmt.Errorf("unable to setup log file: %w", err))
+	}
+	return logFile
+}
+
+// Library returns a logger for a library, which attaches the library name as the "from-lib" field to all log entries
+// and logs at the level configured for the library (the application level when there is none).
+func (l *LogrusLogger) Library(name string) *LogrusNestedLogger {
+	level, ok := l.Config.LibraryLevels[name]
+	if !ok {
+		level = l.Config.Level
+	}
+	return &LogrusNestedLogger{
+		Logger: l.Logger.WithField("from-lib", name),
+		Level:  level,
+	}
+}
+
 // Debugf takes a formatted template string and template arguments for the debug logging level.
 func (l *LogrusLogger) Debugf(format string, args ...interface{}) {
+	if l.Config.Level < logrus.DebugLevel {
+		return
+	}
 	l.Logger.Debugf(format, args...)
 }
 
 // Infof takes a formatted template string and template arguments for the info logging level.
 func (l *LogrusLogger) Infof(format string, args ...interface{}) {
+	if l.Config.Level < logrus.InfoLevel {
+		return
+	}
 	l.Logger.Infof(format, args...)
 }
 
 // Warnf takes a formatted template string and template arguments for the warning logging level.
 func (l *LogrusLogger) Warnf(format string, args ...interface{}) {
+	if l.Config.Level < logrus.WarnLevel {
+		return
+	}
 	l.Logger.Warnf(format, args...)
 }
 
 // Errorf takes a formatted template string and template arguments for the error logging level.
 func (l *LogrusLogger) Errorf(format string, args ...interface{}) {
+	if l.Config.Level < logrus.ErrorLevel {
+		return
+	}
 	l.Logger.Errorf(format, args...)
 }
 
 // Debug logs the given arguments at the debug logging level.
 func (l *LogrusLogger) Debug(args ...interface{}) {
+	if l.Config.Level < logrus.DebugLevel {
+		return
+	}
 	l.Logger.Debug(args...)
 }
 
 // Info logs the given arguments at the info logging level.
 func (l *LogrusLogger) Info(args ...interface{}) {
+	if l.Config.Level < logrus.InfoLevel {
+		return
+	}
 	l.Logger.Info(args...)
 }
 
 // Warn logs the given arguments at the warning logging level.
 func (l *LogrusLogger) Warn(args ...interface{}) {
+	if l.Config.Level < logrus.WarnLevel {
+		return
+	}
 	l.Logger.Warn(args...)
 }
 
 // Error logs the given arguments at the error logging level.
 func (l *LogrusLogger) Error(args ...interface{}) {
+	if l.Config.Level < logrus.ErrorLevel {
+		return
+	}
 	l.Logger.Error(args...)
 }
 
 // Debugf takes a formatted template string and template arguments for the debug logging level.
 func (l *LogrusNestedLogger) Debugf(format string, args ...interface{}) {
+	if l.Level < logrus.DebugLevel {
+		return
+	}
 	l.Logger.Debugf(format, args...)
 }
 
 // Infof takes a formatted template string and template arguments for the info logging level.
 func (l *LogrusNestedLogger) Infof(format string, args ...interface{}) {
+	if l.Level < logrus.InfoLevel {
+		return
+	}
 	l.Logger.Infof(format, args...)
 }
 
 // Warnf takes a formatted template string and template arguments for the warning logging level.
 func (l *LogrusNestedLogger) Warnf(format string, args ...interface{}) {
+	if l.Level < logrus.WarnLevel {
+		return
+	}
 	l.Logger.Warnf(format, args...)
 }
 
 // Errorf takes a formatted template string and template arguments for the error logging level.
 func (l *LogrusNestedLogger) Errorf(format string, args ...interface{}) {
+	if l.Level < logrus.ErrorLevel {
+		return
+	}
 	l.Logger.Errorf(format, args...)
 }
 
 // Debug logs the given arguments at the debug logging level.
 func (l *LogrusNestedLogger) Debug(args ...interface{}) {
+	if l.Level < logrus.DebugLevel {
+		return
+	}
 	l.Logger.Debug(args...)
 }
 
 // Info logs the given arguments at the info logging level.
 func (l *LogrusNestedLogger) Info(args ...interface{}) {
+	if l.Level < logrus.InfoLevel {
+		return
+	}
 	l.Logger.Info(args...)
 }
 
 // Warn logs the given arguments at the warning logging level.
 func (l *LogrusNestedLogger) Warn(args ...interface{}) {
+	if l.Level < logrus.WarnLevel {
+		return
+	}
 	l.Logger.Warn(args...)
 }
 
 // Error logs the given arguments at the error logging level.
 func (l *LogrusNestedLogger) Error(args ...interface{}) {
+	if l.Level < logrus.ErrorLevel {
+		return
+	}
 	l.Logger.Error(args...)
 }
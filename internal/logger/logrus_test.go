@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogrusLogger_Library(t *testing.T) {
+	l := NewLogrusLogger(LogrusConfig{
+		Structured: true,
+		Level:      logrus.WarnLevel,
+		LibraryLevels: map[string]logrus.Level{
+			"syft":        logrus.DebugLevel,
+			"stereoscope": logrus.ErrorLevel,
+		},
+	})
+	var buf bytes.Buffer
+	l.Logger.SetOutput(&buf)
+
+	l.Debug("app debug")
+	l.Warn("app warn")
+	l.Library("syft").Debug("syft debug")
+	l.Library("stereoscope").Warn("stereoscope warn")
+	l.Library("stereoscope").Error("stereoscope error")
+
+	logs := buf.String()
+	assert.NotContains(t, logs, "app debug")
+	assert.Contains(t, logs, "app warn")
+	assert.Contains(t, logs, `"from-lib":"syft","level":"debug","msg":"syft debug"`)
+	assert.NotContains(t, logs, "stereoscope warn")
+	assert.Contains(t, logs, `"from-lib":"stereoscope","level":"error","msg":"stereoscope error"`)
+}
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// rotatingFile is a log file that is rotated when a write would make it exceed the maximum size: the file is renamed
+// to <path>.1 (older files are renamed to <path>.2 and so on, keeping at most maxBackups files) and a new file is
+// started.
+type rotatingFile struct {
+	lock       sync.Mutex
+	path       string
+	maxSize    uint64
+	maxBackups int
+	file       *os.File
+	size       uint64
+
+	// failing rotations are reported once on stderr (until rotating succeeds again), since the logger would report
+	// every write that returns an error
+	stderr       io.Writer
+	rotateFailed bool
+}
+
+func openRotatingFile(path string, appendFile bool, maxSize uint64, maxBackups int) (*rotatingFile, error) {
+	file, err := openLogFile(path, appendFile)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("unable to determine the size of the log file: %w", err)
+	}
+
+	return &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		file:       file,
+		size:       uint64(info.Size()),
+		stderr:     os.Stderr,
+	}, nil
+}
+
+// openLogFile opens the log file for writing, either appending to or truncating an existing file.
+func openLogFile(path string, appendFile bool) (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendFile {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return os.OpenFile(path, flags, defaultLogFilePermissions)
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// a single entry larger than the maximum size is still written (to an empty file)
+	var rotateErr error
+	if f.file == nil || (f.size > 0 && f.size+uint64(len(p)) > f.maxSize) {
+		if err := f.rotate(); err != nil {
+			// the entry is still written to the current file, rotating is tried again on the next write
+			rotateErr = fmt.Errorf("unable to rotate the log file: %w", err)
+			if !f.rotateFailed {
+				fmt.Fprintf(f.stderr, "%v (logging continues to %q)\n", rotateErr, f.path)
+			}
+		}
+		f.rotateFailed = rotateErr != nil
+	}
+	if f.file == nil {
+		return 0, rotateErr
+	}
+
+	n, err := f.file.Write(p)
+	f.size += uint64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	// the file is closed before it is renamed, since open files cannot be renamed on windows
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return f.reopen(err)
+		}
+	}
+
+	// shift the rotated files, where the oldest is overwritten (the current file is simply truncated without backups)
+	for i := f.maxBackups; i > 0; i-- {
+		if err := os.Rename(f.backup(i-1), f.backup(i)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return f.reopen(err)
+		}
+	}
+
+	file, err := openLogFile(f.path, false)
+	if err != nil {
+		return f.reopen(err)
+	}
+	f.file = file
+	f.size = 0
+	return nil
+}
+
+// reopen continues writing to the current log file after rotating it failed, returning the rotation error. When the
+// file cannot be reopened either, there is no file until a later write manages to rotate.
+func (f *rotatingFile) reopen(rotateErr error) error {
+	f.file = nil
+
+	file, err := openLogFile(f.path, true)
+	if err != nil {
+		return fmt.Errorf("%v (unable to reopen the log file: %w)", rotateErr, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("%v (unable to determine the size of the log file: %w)", rotateErr, err)
+	}
+
+	f.file = file
+	f.size = uint64(info.Size())
+	return rotateErr
+}
+
+// backup returns the path of the rotated file with the given number (0 is the current file).
+func (f *rotatingFile) backup(i int) string {
+	if i == 0 {
+		return f.path
+	}
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
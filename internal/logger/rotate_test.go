@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_openLogFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		appendFile bool
+		want       string
+	}{
+		{
+			name: "truncate",
+			want: "new\n",
+		},
+		{
+			name:       "append",
+			appendFile: true,
+			want:       "old\nnew\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sbom.log")
+			require.NoError(t, os.WriteFile(path, []byte("old\n"), 0600))
+
+			file, err := openLogFile(path, test.appendFile)
+			require.NoError(t, err)
+			_, err = file.WriteString("new\n")
+			require.NoError(t, err)
+			require.NoError(t, file.Close())
+
+			assertFileContents(t, path, test.want)
+		})
+	}
+}
+
+func Test_rotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.log")
+	require.NoError(t, os.WriteFile(path, []byte("0000\n"), 0600))
+
+	file, err := openRotatingFile(path, true, 10, 2)
+	require.NoError(t, err)
+	for _, entry := range []string{"1111\n", "2222\n", "3333\n", "4444\n", "5555\n", "6666\n"} {
+		_, err := file.Write([]byte(entry))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.file.Close())
+
+	// the appended file is rotated with the existing entry, and only the newest backups are kept
+	assertFileContents(t, path, "6666\n")
+	assertFileContents(t, path+".1", "4444\n5555\n")
+	assertFileContents(t, path+".2", "2222\n3333\n")
+	assert.NoFileExists(t, path+".3")
+}
+
+func Test_rotatingFile_noBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.log")
+
+	file, err := openRotatingFile(path, false, 4, 0)
+	require.NoError(t, err)
+	// entries larger than the maximum size are not split
+	for _, entry := range []string{"first\n", "second\n"} {
+		_, err := file.Write([]byte(entry))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.file.Close())
+
+	assertFileContents(t, path, "second\n")
+	assert.NoFileExists(t, path+".1")
+}
+
+func Test_rotatingFile_rotateFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.log")
+
+	file, err := openRotatingFile(path, false, 10, 1)
+	require.NoError(t, err)
+	var stderr bytes.Buffer
+	file.stderr = &stderr
+
+	// a (non-empty) directory in place of the backup cannot be replaced by the log file
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "blocked"), 0755))
+
+	_, err = file.Write([]byte("1111\n2222\n"))
+	require.NoError(t, err)
+	for _, entry := range []string{"3333\n", "4444\n"} {
+		// the entry is written to the current file, so the write did not fail
+		n, err := file.Write([]byte(entry))
+		assert.NoError(t, err)
+		assert.Equal(t, len(entry), n)
+	}
+	assertFileContents(t, path, "1111\n2222\n3333\n4444\n")
+	assert.Equal(t, 1, strings.Count(stderr.String(), "unable to rotate the log file"), "the failure should be reported once")
+
+	// once the backup can be written, the next write rotates
+	require.NoError(t, os.RemoveAll(path+".1"))
+	_, err = file.Write([]byte("5555\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.file.Close())
+
+	assertFileContents(t, path, "5555\n")
+	assertFileContents(t, path+".1", "1111\n2222\n3333\n4444\n")
+}
+
+func assertFileContents(t *testing.T, path, want string) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(contents))
+}
@@ -0,0 +1,266 @@
+/*
+Package merge combines several SBOM documents into a single SBOM, deduplicating packages by package URL while keeping
+track of which document each package was found in.
+*/
+package merge
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+const (
+	// SourceProperty is the property attached to each package naming a source the package was found in (one per source).
+	SourceProperty = "docker:sbom:merge:source"
+	// DocumentProperty is the property attached to each source component naming the document it was read from.
+	DocumentProperty = "docker:sbom:merge:document"
+	// DocumentDigestProperty is the property attached to each source component with the digest of the document it was read from.
+	DocumentDigestProperty = "docker:sbom:merge:document-digest"
+	// DocumentFormatProperty is the property attached to each source component with the format of the document it was read from.
+	DocumentFormatProperty = "docker:sbom:merge:document-format"
+)
+
+// Input is a single decoded SBOM document to be merged.
+type Input struct {
+	Path   string        // the path the document was read from
+	Digest string        // the digest of the raw document
+	Format sbom.FormatID // the format the document was decoded from
+	SBOM   sbom.SBOM     // the decoded document
+}
+
+// Source describes a document that contributed to a merged SBOM.
+type Source struct {
+	Name     string        `json:"name" yaml:"name"`                      // the name of what the document described (e.g. the image reference)
+	Type     string        `json:"type" yaml:"type"`                      // the kind of thing the document described (e.g. "image", "directory")
+	Version  string        `json:"version,omitempty" yaml:"version"`      // the version of what the document described (e.g. the image manifest digest)
+	Document string        `json:"document" yaml:"document"`              // the path the document was read from
+	Digest   string        `json:"documentDigest" yaml:"document-digest"` // the digest of the raw document
+	Format   sbom.FormatID `json:"documentFormat" yaml:"document-format"` // the format the document was decoded from
+	Packages []artifact.ID `json:"packages" yaml:"packages"`              // the IDs of the merged packages that were found in this document
+}
+
+// Result is a merged SBOM along with the provenance of each package.
+type Result struct {
+	Name    string
+	SBOM    sbom.SBOM
+	Sources []Source
+}
+
+// SBOMs merges all given documents into a single SBOM describing a product with the given name. Packages with the same
+// package URL are considered to be the same package; packages without a package URL are considered the same only
+// when their package IDs are equal.
+func SBOMs(name string, inputs ...Input) Result {
+	var (
+		order     []string
+		packages  = make(map[string]*pkg.Package)
+		canonical = make(map[artifact.ID]artifact.ID)
+		sources   = make([]Source, len(inputs))
+	)
+
+	for idx, input := range inputs {
+		sources[idx] = newSource(input)
+
+		if input.SBOM.Artifacts.PackageCatalog == nil {
+			continue
+		}
+
+		seen := make(map[artifact.ID]struct{})
+		for _, p := range input.SBOM.Artifacts.PackageCatalog.Sorted() {
+			if p.ID() == "" {
+				p.SetID()
+			}
+
+			key := dedupKey(p)
+			existing, ok := packages[key]
+			if !ok {
+				p := p
+				packages[key] = &p
+				order = append(order, key)
+				existing = &p
+			} else {
+				// the location set of the package is shared with the catalog of the input, which is left as it is
+				existing.Locations = source.NewLocationSet(append(existing.Locations.ToSlice(), p.Locations.ToSlice()...)...)
+				existing.CPEs = mergeCPEs(existing.CPEs, p.CPEs)
+			}
+			canonical[p.ID()] = existing.ID()
+
+			if _, ok := seen[existing.ID()]; !ok {
+				seen[existing.ID()] = struct{}{}
+				sources[idx].Packages = append(sources[idx].Packages, existing.ID())
+			}
+		}
+	}
+
+	catalog := pkg.NewCatalog()
+	for _, key := range order {
+		catalog.Add(*packages[key])
+	}
+
+	return Result{
+		Name: name,
+		SBOM: sbom.SBOM{
+			Artifacts: sbom.Artifacts{
+				PackageCatalog:    catalog,
+				LinuxDistribution: commonDistro(inputs),
+			},
+			Relationships: mergeRelationships(catalog, canonical, inputs),
+			Source: source.Metadata{
+				Scheme: source.FileScheme,
+				Path:   name,
+			},
+		},
+		Sources: sources,
+	}
+}
+
+// Extension describes the merge provenance in terms of document content: the merged product as the described
+// component, each source as a related component containing its packages, and a source property on every package.
+func (r Result) Extension(_ sbom.SBOM) (*formats.Extension, error) {
+	ext := formats.Extension{
+		Describes: &formats.Component{
+			ID:   "DocumentRoot-" + r.Name,
+			Type: "application",
+			Name: r.Name,
+		},
+		PackageProperties: make(map[artifact.ID][]formats.Property),
+	}
+
+	for idx, s := range r.Sources {
+		componentType := "file"
+		if s.Type == string(source.ImageScheme) {
+			componentType = "container"
+		}
+
+		ext.Components = append(ext.Components, formats.Component{
+			ID:      fmt.Sprintf("MergedSource-%d", idx+1),
+			Type:    componentType,
+			Name:    s.Name,
+			Version: s.Version,
+			Properties: []formats.Property{
+				{Name: DocumentProperty, Value: s.Document},
+				{Name: DocumentDigestProperty, Value: s.Digest},
+				{Name: DocumentFormatProperty, Value: string(s.Format)},
+			},
+			Contains: s.Packages,
+		})
+
+		for _, id := range s.Packages {
+			ext.PackageProperties[id] = append(ext.PackageProperties[id], formats.Property{Name: SourceProperty, Value: s.Name})
+		}
+	}
+
+	return &ext, nil
+}
+
+func newSource(input Input) Source {
+	s := Source{
+		Document: input.Path,
+		Digest:   input.Digest,
+		Format:   input.Format,
+		Type:     string(input.SBOM.Source.Scheme),
+	}
+
+	switch input.SBOM.Source.Scheme {
+	case source.ImageScheme:
+		s.Name = input.SBOM.Source.ImageMetadata.UserInput
+		s.Version = input.SBOM.Source.ImageMetadata.ManifestDigest
+		if s.Name == "" && len(input.SBOM.Source.ImageMetadata.Tags) > 0 {
+			s.Name = input.SBOM.Source.ImageMetadata.Tags[0]
+		}
+	case source.DirectoryScheme, source.FileScheme:
+		s.Name = input.SBOM.Source.Path
+	}
+
+	if s.Name == "" {
+		s.Name = filepath.Base(input.Path)
+	}
+	if s.Type == "" {
+		s.Type = "unknown"
+	}
+
+	return s
+}
+
+func dedupKey(p pkg.Package) string {
+	if p.PURL != "" {
+		return "purl:" + p.PURL
+	}
+	return "id:" + string(p.ID())
+}
+
+func mergeCPEs(a, b []pkg.CPE) []pkg.CPE {
+	// a new slice, since appending could otherwise write to the CPEs of an input package
+	result := append([]pkg.CPE(nil), a...)
+	seen := make(map[string]struct{})
+	for _, c := range a {
+		seen[pkg.CPEString(c)] = struct{}{}
+	}
+	for _, c := range b {
+		if _, ok := seen[pkg.CPEString(c)]; ok {
+			continue
+		}
+		seen[pkg.CPEString(c)] = struct{}{}
+		result = append(result, c)
+	}
+	return result
+}
+
+// mergeRelationships keeps all relationships from all inputs, rewriting package references to the merged package
+// each was deduplicated into.
+func mergeRelationships(catalog *pkg.Catalog, canonical map[artifact.ID]artifact.ID, inputs []Input) (result []artifact.Relationship) {
+	resolve := func(i artifact.Identifiable) artifact.Identifiable {
+		if _, ok := i.(pkg.Package); !ok {
+			return i
+		}
+		if id, ok := canonical[i.ID()]; ok {
+			if p := catalog.Package(id); p != nil {
+				return *p
+			}
+		}
+		return i
+	}
+
+	type key struct {
+		from, to artifact.ID
+		kind     artifact.RelationshipType
+	}
+	seen := make(map[key]struct{})
+
+	for _, input := range inputs {
+		for _, r := range input.SBOM.Relationships {
+			r.From = resolve(r.From)
+			r.To = resolve(r.To)
+
+			k := key{from: r.From.ID(), to: r.To.ID(), kind: r.Type}
+			if _, ok := seen[k]; ok || k.from == k.to {
+				continue
+			}
+			seen[k] = struct{}{}
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// commonDistro returns the linux distribution only when every input that reports one agrees on it.
+func commonDistro(inputs []Input) (result *linux.Release) {
+	for _, input := range inputs {
+		d := input.SBOM.Artifacts.LinuxDistribution
+		if d == nil {
+			continue
+		}
+		if result != nil && (result.ID != d.ID || result.VersionID != d.VersionID) {
+			return nil
+		}
+		result = d
+	}
+	return result
+}
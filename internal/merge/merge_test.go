@@ -0,0 +1,154 @@
+package merge
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/docker/sbom-cli-plugin/internal/formats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+func newPackage(name, version, purl string, locations ...string) pkg.Package {
+	p := pkg.Package{
+		Name:    name,
+		Version: version,
+		PURL:    purl,
+		Type:    pkg.NpmPkg,
+	}
+	for _, l := range locations {
+		p.Locations.Add(source.NewLocation(l))
+	}
+	p.SetID()
+	return p
+}
+
+func newInput(path, image string, pkgs ...pkg.Package) Input {
+	return Input{
+		Path: path,
+		SBOM: sbom.SBOM{
+			Artifacts: sbom.Artifacts{
+				PackageCatalog: pkg.NewCatalog(pkgs...),
+			},
+			Source: source.Metadata{
+				Scheme: source.ImageScheme,
+				ImageMetadata: source.ImageMetadata{
+					UserInput: image,
+				},
+			},
+		},
+	}
+}
+
+func TestSBOMs_dedupByPURL(t *testing.T) {
+	lodashA := newPackage("lodash", "4.17.21", "pkg:npm/lodash@4.17.21", "/app/a/package.json")
+	lodashB := newPackage("lodash", "4.17.21", "pkg:npm/lodash@4.17.21", "/srv/b/package.json")
+	express := newPackage("express", "4.18.1", "pkg:npm/express@4.18.1", "/app/a/package.json")
+	noPURL := newPackage("internal-tool", "1.0.0", "", "/opt/tool")
+
+	inputs := []Input{
+		newInput("a.json", "image-a:latest", lodashA, express),
+		newInput("b.json", "image-b:latest", lodashB, noPURL),
+	}
+	result := SBOMs("product", inputs...)
+
+	catalog := result.SBOM.Artifacts.PackageCatalog
+	require.Equal(t, 3, catalog.PackageCount())
+
+	lodash := catalog.PackagesByName("lodash")
+	require.Len(t, lodash, 1)
+	assert.Len(t, lodash[0].Locations.ToSlice(), 2, "locations from both documents should be kept")
+
+	// the input documents are not changed by merging
+	inputLodash := inputs[0].SBOM.Artifacts.PackageCatalog.PackagesByName("lodash")
+	require.Len(t, inputLodash, 1)
+	assert.Len(t, inputLodash[0].Locations.ToSlice(), 1)
+
+	require.Len(t, result.Sources, 2)
+	assert.Equal(t, "image-a:latest", result.Sources[0].Name)
+	assert.Equal(t, "image-b:latest", result.Sources[1].Name)
+	assert.ElementsMatch(t, []artifact.ID{lodash[0].ID(), express.ID()}, result.Sources[0].Packages)
+	assert.ElementsMatch(t, []artifact.ID{lodash[0].ID(), noPURL.ID()}, result.Sources[1].Packages)
+
+	assert.Equal(t, source.FileScheme, result.SBOM.Source.Scheme)
+	assert.Equal(t, "product", result.SBOM.Source.Path)
+}
+
+func TestSBOMs_relationshipsAreRewritten(t *testing.T) {
+	lodashA := newPackage("lodash", "4.17.21", "pkg:npm/lodash@4.17.21", "/app/a/package.json")
+	lodashB := newPackage("lodash", "4.17.21", "pkg:npm/lodash@4.17.21", "/srv/b/package.json")
+	express := newPackage("express", "4.18.1", "pkg:npm/express@4.18.1", "/srv/b/package.json")
+
+	a := newInput("a.json", "image-a:latest", lodashA)
+	b := newInput("b.json", "image-b:latest", lodashB, express)
+	b.SBOM.Relationships = []artifact.Relationship{
+		{From: express, To: lodashB, Type: artifact.DependencyOfRelationship},
+	}
+
+	result := SBOMs("product", a, b)
+
+	require.Len(t, result.SBOM.Relationships, 1)
+	assert.Equal(t, express.ID(), result.SBOM.Relationships[0].From.ID())
+	assert.Equal(t, result.Sources[0].Packages[0], result.SBOM.Relationships[0].To.ID())
+}
+
+func TestResult_Extension(t *testing.T) {
+	lodash := newPackage("lodash", "4.17.21", "pkg:npm/lodash@4.17.21", "/app/package.json")
+
+	result := SBOMs("product",
+		newInput("a.json", "image-a:latest", lodash),
+		newInput("b.json", "image-b:latest", lodash),
+	)
+
+	ext, err := result.Extension(result.SBOM)
+	require.NoError(t, err)
+
+	require.NotNil(t, ext.Describes)
+	assert.Equal(t, "product", ext.Describes.Name)
+
+	require.Len(t, ext.Components, 2)
+	assert.Equal(t, "container", ext.Components[0].Type)
+	assert.Equal(t, "image-a:latest", ext.Components[0].Name)
+	assert.Equal(t, []artifact.ID{lodash.ID()}, ext.Components[0].Contains)
+
+	props := ext.PackageProperties[lodash.ID()]
+	require.Len(t, props, 2)
+	assert.Equal(t, SourceProperty, props[0].Name)
+	assert.Equal(t, "image-a:latest", props[0].Value)
+	assert.Equal(t, "image-b:latest", props[1].Value)
+}
+
+func TestResult_Extension_CycloneDX(t *testing.T) {
+	lodash := newPackage("lodash", "4.17.21", "pkg:npm/lodash@4.17.21", "/app/package.json")
+	express := newPackage("express", "4.18.1", "pkg:npm/express@4.18.1", "/srv/package.json")
+
+	result := SBOMs("product",
+		newInput("a.json", "image-a:latest", lodash),
+		newInput("b.json", "image-b:latest", express),
+	)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, formats.Extend(syft.FormatByID(syft.CycloneDxJSONFormatID), result.Extension).Encode(buf, result.SBOM))
+
+	bom := cyclonedx.BOM{}
+	require.NoError(t, cyclonedx.NewBOMDecoder(buf, cyclonedx.BOMFileFormatJSON).Decode(&bom))
+
+	refs := make(map[string]string)
+	for _, c := range *bom.Components {
+		refs[c.Name] = c.BOMRef
+	}
+
+	// each source contains its packages, and the merged product contains none directly
+	require.NotNil(t, bom.Dependencies)
+	assert.Equal(t, []cyclonedx.Dependency{
+		{Ref: "MergedSource-1", Dependencies: &[]cyclonedx.Dependency{{Ref: refs["lodash"]}}},
+		{Ref: "MergedSource-2", Dependencies: &[]cyclonedx.Dependency{{Ref: refs["express"]}}},
+	}, *bom.Dependencies)
+}
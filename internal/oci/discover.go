@@ -0,0 +1,275 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/docker/sbom-cli-plugin/internal/attest"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// How attached SBOMs were found.
+const (
+	ReferrersSource = "referrers"
+	TagSource       = "tag"
+	BuildKitSource  = "buildkit"
+)
+
+// Annotations on the image index entries of BuildKit attestation manifests.
+const (
+	buildKitReferenceTypeAnnotation   = "vnd.docker.reference.type"
+	buildKitReferenceDigestAnnotation = "vnd.docker.reference.digest"
+	buildKitAttestationManifest       = "attestation-manifest"
+)
+
+// Attached is an SBOM document that is attached to an image.
+type Attached struct {
+	Source    string          // how the document was found (ReferrersSource, TagSource, or BuildKitSource)
+	Manifest  name.Digest     // the artifact (or attestation) manifest that the document was found in
+	MediaType types.MediaType // the media type of the layer the document was found in
+	Format    sbom.FormatID   // the format of the document (when known from the media or predicate type)
+	Document  []byte          // the SBOM document (the predicate for attestations, which are not verified)
+}
+
+// Discover finds the SBOM documents that are attached to the given image: artifacts that refer to the image (with the
+// referrers API, or the tag schema for registries that do not implement it) and BuildKit attestations for the given
+// platform (when the image is a multi-platform index).
+func Discover(ctx context.Context, image name.Digest, platform v1.Platform, auth authn.Authenticator) ([]Attached, error) {
+	options := []remote.Option{remote.WithContext(ctx), remote.WithAuth(auth)}
+
+	desc, err := remote.Get(image, options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the image %q in the registry: %w", image, err)
+	}
+
+	manifests, err := referrers(ctx, image, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.MediaType.IsIndex() {
+		attestations, err := buildKitAttestations(desc, platform)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, attestations...)
+	}
+
+	var result []Attached
+	for _, m := range manifests {
+		attached, err := documents(m, options)
+		if err != nil {
+			// other artifacts may still have a usable document
+			log.Warnf("unable to read artifact %q: %+v", m.ref, err)
+			continue
+		}
+		result = append(result, attached...)
+	}
+	return result, nil
+}
+
+// artifactManifest is an artifact manifest that may contain SBOM documents.
+type artifactManifest struct {
+	source string
+	ref    name.Digest
+}
+
+// referrers lists the artifacts that refer to the image with the referrers API, or with the tag schema when the
+// registry does not implement the referrers API.
+func referrers(ctx context.Context, image name.Digest, auth authn.Authenticator) ([]artifactManifest, error) {
+	repo := image.Context()
+	index, err := referrersIndex(ctx, image, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []artifactManifest
+	if index != nil {
+		for _, d := range index.Manifests {
+			result = append(result, artifactManifest{source: ReferrersSource, ref: repo.Digest(d.Digest.String())})
+		}
+		return result, nil
+	}
+
+	digest, err := v1.NewHash(image.DigestStr())
+	if err != nil {
+		return nil, err
+	}
+	for _, suffix := range []string{sbomTagSuffix, attestationTagSuffix} {
+		tag := repo.Tag(TagFor(digest, suffix))
+		desc, err := remote.Head(tag, remote.WithContext(ctx), remote.WithAuth(auth))
+		if err != nil {
+			log.Debugf("no artifact tagged %q: %+v", tag, err)
+			continue
+		}
+		result = append(result, artifactManifest{source: TagSource, ref: repo.Digest(desc.Digest.String())})
+	}
+	return result, nil
+}
+
+// referrersIndex returns the referrers of the image, or nil when the registry does not implement the referrers API.
+func referrersIndex(ctx context.Context, image name.Digest, auth authn.Authenticator) (*v1.IndexManifest, error) {
+	repo := image.Context()
+	t, err := transport.NewWithContext(ctx, repo.Registry, auth, remote.DefaultTransport, []string{repo.Scope(transport.PullScope)})
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s://%s/v2/%s/referrers/%s", repo.Registry.Scheme(), repo.RegistryStr(), repo.RepositoryStr(), image.DigestStr())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(types.OCIImageIndex))
+
+	resp, err := (&http.Client{Transport: t}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := transport.CheckError(resp, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("unable to list the referrers of %q: %w", image, err)
+	}
+
+	var index v1.IndexManifest
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		// registries without the referrers API may serve other content for unknown paths
+		log.Debugf("unable to decode referrers of %q: %+v", image, err)
+		return nil, nil
+	}
+	return &index, nil
+}
+
+// buildKitAttestations returns the attestation manifests in the image index for the image of the given platform.
+func buildKitAttestations(desc *remote.Descriptor, platform v1.Platform) ([]artifactManifest, error) {
+	index, err := v1.ParseIndexManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the image index %q: %w", desc.Ref, err)
+	}
+
+	var platformDigest string
+	for _, m := range index.Manifests {
+		if m.Platform != nil && platformMatches(*m.Platform, platform) && m.Annotations[buildKitReferenceTypeAnnotation] == "" {
+			platformDigest = m.Digest.String()
+			break
+		}
+	}
+	if platformDigest == "" {
+		log.Debugf("no image for platform %q in the image index %q", platform, desc.Ref)
+		return nil, nil
+	}
+
+	var result []artifactManifest
+	for _, m := range index.Manifests {
+		if m.Annotations[buildKitReferenceTypeAnnotation] == buildKitAttestationManifest && m.Annotations[buildKitReferenceDigestAnnotation] == platformDigest {
+			result = append(result, artifactManifest{source: BuildKitSource, ref: desc.Ref.Context().Digest(m.Digest.String())})
+		}
+	}
+	return result, nil
+}
+
+// platformMatches indicates if the platform of an image satisfies the wanted platform (any variant is acceptable
+// when no variant is wanted).
+func platformMatches(p, want v1.Platform) bool {
+	return p.OS == want.OS && p.Architecture == want.Architecture && (want.Variant == "" || p.Variant == want.Variant)
+}
+
+// documents returns the SBOM documents in the layers of the artifact manifest.
+func documents(m artifactManifest, options []remote.Option) ([]Attached, error) {
+	desc, err := remote.Get(m.ref, options...)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Attached
+	for _, l := range manifest.Layers {
+		id, isSBOM := sbomFormat(l.MediaType)
+		if !isSBOM && l.MediaType != DSSEEnvelopeMediaType && l.MediaType != InTotoArtifactType {
+			continue
+		}
+
+		content, err := readBlob(m.ref.Context().Digest(l.Digest.String()), options)
+		if err != nil {
+			return nil, err
+		}
+
+		attached := Attached{Source: m.source, Manifest: m.ref, MediaType: l.MediaType, Format: id, Document: content}
+		if !isSBOM {
+			statement, err := statementFrom(l.MediaType, content)
+			if err != nil {
+				log.Debugf("ignoring layer %q of %q: %+v", l.Digest, m.ref, err)
+				continue
+			}
+			if attached.Format, err = statement.Format(); err != nil {
+				log.Debugf("ignoring layer %q of %q: %+v", l.Digest, m.ref, err)
+				continue
+			}
+			attached.Document = statement.Predicate
+		}
+		result = append(result, attached)
+	}
+	return result, nil
+}
+
+// statementFrom decodes the in-toto statement in a DSSE envelope (without verifying the signature) or a plain
+// statement (as in BuildKit attestations).
+func statementFrom(mediaType types.MediaType, content []byte) (*attest.Statement, error) {
+	if mediaType == DSSEEnvelopeMediaType {
+		var e attest.Envelope
+		if err := json.Unmarshal(content, &e); err != nil {
+			return nil, err
+		}
+		if e.PayloadType != attest.PayloadType {
+			return nil, fmt.Errorf("unsupported payload type %q", e.PayloadType)
+		}
+		content = e.Payload
+	}
+
+	var s attest.Statement
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func readBlob(ref name.Digest, options []remote.Option) ([]byte, error) {
+	layer, err := remote.Layer(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func sbomFormat(mediaType types.MediaType) (sbom.FormatID, bool) {
+	for id, mt := range sbomMediaTypes {
+		if mt == mediaType {
+			return id, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,149 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal/attest"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+)
+
+var linuxAMD64 = v1.Platform{OS: "linux", Architecture: "amd64"}
+
+func TestDiscover_tagSchema(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image := pushImage(t, host)
+
+	// nothing is attached yet
+	attached, err := Discover(context.Background(), image, linuxAMD64, authn.Anonymous)
+	require.NoError(t, err)
+	assert.Empty(t, attached)
+
+	artifact, err := SBOMArtifact(syft.CycloneDxJSONFormatID, []byte(`{"bomFormat": "CycloneDX"}`))
+	require.NoError(t, err)
+	sbomPushed, err := Push(context.Background(), image, *artifact, authn.Anonymous, time.Now())
+	require.NoError(t, err)
+
+	statement := attest.Statement{Type: attest.StatementType, PredicateType: attest.SPDXPredicateType, Predicate: []byte(`{"spdxVersion": "SPDX-2.2"}`)}
+	payload, err := json.Marshal(statement)
+	require.NoError(t, err)
+	envelope, err := json.Marshal(attest.Envelope{PayloadType: attest.PayloadType, Payload: payload})
+	require.NoError(t, err)
+	attestationPushed, err := Push(context.Background(), image, *AttestationArtifact(attest.SPDXPredicateType, envelope), authn.Anonymous, time.Now())
+	require.NoError(t, err)
+
+	attached, err = Discover(context.Background(), image, linuxAMD64, authn.Anonymous)
+	require.NoError(t, err)
+	require.Len(t, attached, 2)
+
+	assert.Equal(t, TagSource, attached[0].Source)
+	assert.Equal(t, sbomPushed.Manifest, attached[0].Manifest)
+	assert.Equal(t, syft.CycloneDxJSONFormatID, attached[0].Format)
+	assert.JSONEq(t, `{"bomFormat": "CycloneDX"}`, string(attached[0].Document))
+
+	// the predicate is extracted from the attestation
+	assert.Equal(t, TagSource, attached[1].Source)
+	assert.Equal(t, attestationPushed.Manifest, attached[1].Manifest)
+	assert.Equal(t, DSSEEnvelopeMediaType, attached[1].MediaType)
+	assert.Equal(t, syft.SPDXJSONFormatID, attached[1].Format)
+	assert.JSONEq(t, `{"spdxVersion": "SPDX-2.2"}`, string(attached[1].Document))
+}
+
+func TestDiscover_referrers(t *testing.T) {
+	var artifactDigest v1.Hash
+	handler := registry.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/referrers/") {
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			_, _ = fmt.Fprintf(w, `{"schemaVersion": 2, "manifests": [{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": %q, "size": 1}]}`, artifactDigest)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image := pushImage(t, host)
+	artifact, err := SBOMArtifact(syft.JSONFormatID, []byte(`{"schema": {}}`))
+	require.NoError(t, err)
+	pushed, err := Push(context.Background(), image, *artifact, authn.Anonymous, time.Now())
+	require.NoError(t, err)
+	artifactDigest, err = v1.NewHash(pushed.Manifest.DigestStr())
+	require.NoError(t, err)
+
+	attached, err := Discover(context.Background(), image, linuxAMD64, authn.Anonymous)
+	require.NoError(t, err)
+	require.Len(t, attached, 1)
+	assert.Equal(t, ReferrersSource, attached[0].Source)
+	assert.Equal(t, syft.JSONFormatID, attached[0].Format)
+}
+
+func TestDiscover_buildKitAttestations(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	amd64, err := random.Image(64, 1)
+	require.NoError(t, err)
+	arm64, err := random.Image(64, 1)
+	require.NoError(t, err)
+	amd64Digest, err := amd64.Digest()
+	require.NoError(t, err)
+
+	statement, err := json.Marshal(attest.Statement{Type: attest.StatementType, PredicateType: attest.SPDXPredicateType, Predicate: []byte(`{"spdxVersion": "SPDX-2.3"}`)})
+	require.NoError(t, err)
+	attestation, err := mutate.AppendLayers(empty.Image, static.NewLayer(statement, InTotoArtifactType))
+	require.NoError(t, err)
+
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &linuxAMD64}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+		mutate.IndexAddendum{Add: attestation, Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"},
+			Annotations: map[string]string{
+				buildKitReferenceTypeAnnotation:   buildKitAttestationManifest,
+				buildKitReferenceDigestAnnotation: amd64Digest.String(),
+			},
+		}},
+	)
+
+	tag, err := name.NewTag(host + "/app:multi")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(tag, index))
+	indexDigest, err := index.Digest()
+	require.NoError(t, err)
+	image := tag.Context().Digest(indexDigest.String())
+
+	attached, err := Discover(context.Background(), image, linuxAMD64, authn.Anonymous)
+	require.NoError(t, err)
+	require.Len(t, attached, 1)
+	assert.Equal(t, BuildKitSource, attached[0].Source)
+	assert.Equal(t, syft.SPDXJSONFormatID, attached[0].Format)
+	assert.JSONEq(t, `{"spdxVersion": "SPDX-2.3"}`, string(attached[0].Document))
+
+	// there is no attestation for the other platform
+	attached, err = Discover(context.Background(), image, v1.Platform{OS: "linux", Architecture: "arm64"}, authn.Anonymous)
+	require.NoError(t, err)
+	assert.Empty(t, attached)
+}
@@ -0,0 +1,80 @@
+/*
+Package oci attaches SBOM documents (and their attestations) to images in a registry as OCI artifacts. Artifacts refer to
+the image manifest with the "subject" field of the OCI 1.1 image manifest, such that registries that implement the
+referrers API can list them for the image. For registries that do not (yet) implement the referrers API, artifacts are
+also tagged with the tag schema used by cosign ("sha256-<digest>.sbom").
+*/
+package oci
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// Media types for artifacts and their contents.
+const (
+	EmptyConfigMediaType    types.MediaType = "application/vnd.oci.empty.v1+json"
+	SPDXJSONMediaType       types.MediaType = "application/spdx+json"
+	SPDXTagValueMediaType   types.MediaType = "text/spdx"
+	CycloneDXJSONMediaType  types.MediaType = "application/vnd.cyclonedx+json"
+	CycloneDXXMLMediaType   types.MediaType = "application/vnd.cyclonedx+xml"
+	SyftJSONMediaType       types.MediaType = "application/vnd.syft+json"
+	DSSEEnvelopeMediaType   types.MediaType = "application/vnd.dsse.envelope.v1+json"
+	InTotoArtifactType                      = "application/vnd.in-toto+json"
+	predicateTypeAnnotation                 = "in-toto.io/predicate-type"
+	createdAnnotation                       = "org.opencontainers.image.created"
+	sbomTagSuffix                           = "sbom"
+	attestationTagSuffix                    = "att"
+	emptyConfig                             = "{}"
+)
+
+var sbomMediaTypes = map[sbom.FormatID]types.MediaType{
+	syft.SPDXJSONFormatID:      SPDXJSONMediaType,
+	syft.SPDXTagValueFormatID:  SPDXTagValueMediaType,
+	syft.CycloneDxJSONFormatID: CycloneDXJSONMediaType,
+	syft.CycloneDxXMLFormatID:  CycloneDXXMLMediaType,
+	syft.JSONFormatID:          SyftJSONMediaType,
+}
+
+// Artifact is a document to attach to an image.
+type Artifact struct {
+	ArtifactType string            // the type of the artifact (the media type of the document for plain SBOMs)
+	MediaType    types.MediaType   // the media type of the document
+	Document     []byte            // the document itself
+	Annotations  map[string]string // additional annotations for the artifact manifest
+	TagSuffix    string            // the suffix of the tag for registries without the referrers API (e.g. "sbom")
+}
+
+// SBOMArtifact returns an artifact for an SBOM document encoded with the given format (only SBOM formats that have a
+// registered media type can be attached).
+func SBOMArtifact(id sbom.FormatID, document []byte) (*Artifact, error) {
+	mediaType, ok := sbomMediaTypes[id]
+	if !ok {
+		return nil, fmt.Errorf("format %q cannot be attached to an image (options=%v)", id, sbomFormatIDs())
+	}
+	return &Artifact{
+		ArtifactType: string(mediaType),
+		MediaType:    mediaType,
+		Document:     document,
+		TagSuffix:    sbomTagSuffix,
+	}, nil
+}
+
+// AttestationArtifact returns an artifact for an in-toto attestation (a DSSE envelope) with the given predicate type.
+func AttestationArtifact(predicateType string, envelope []byte) *Artifact {
+	return &Artifact{
+		ArtifactType: InTotoArtifactType,
+		MediaType:    DSSEEnvelopeMediaType,
+		Document:     envelope,
+		Annotations:  map[string]string{predicateTypeAnnotation: predicateType},
+		TagSuffix:    attestationTagSuffix,
+	}
+}
+
+func sbomFormatIDs() []sbom.FormatID {
+	return []sbom.FormatID{syft.JSONFormatID, syft.SPDXJSONFormatID, syft.SPDXTagValueFormatID, syft.CycloneDxJSONFormatID, syft.CycloneDxXMLFormatID}
+}
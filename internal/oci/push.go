@@ -0,0 +1,179 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// subjectHeader is returned by registries that implement the referrers API when a manifest with a subject is pushed.
+const subjectHeader = "OCI-Subject"
+
+// manifest is an OCI 1.1 image manifest (the artifact type and subject are not yet part of v1.Manifest).
+type manifest struct {
+	SchemaVersion int64             `json:"schemaVersion"`
+	MediaType     types.MediaType   `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        v1.Descriptor     `json:"config"`
+	Layers        []v1.Descriptor   `json:"layers"`
+	Subject       *v1.Descriptor    `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// rawManifest is a remote.Taggable for an encoded manifest.
+type rawManifest struct {
+	data      []byte
+	mediaType types.MediaType
+}
+
+func (m rawManifest) RawManifest() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m rawManifest) MediaType() (types.MediaType, error) {
+	return m.mediaType, nil
+}
+
+// Pushed describes where an artifact was pushed to.
+type Pushed struct {
+	Manifest name.Digest // the artifact manifest
+	Tag      *name.Tag   // the tag schema fallback (nil when the registry implements the referrers API)
+}
+
+// Reference returns the reference to show for the pushed artifact.
+func (p Pushed) Reference() name.Reference {
+	if p.Tag != nil {
+		return *p.Tag
+	}
+	return p.Manifest
+}
+
+// Push uploads the artifact to the repository of the given image, with the image manifest as the subject of the
+// artifact. When the registry does not indicate support for the referrers API, the artifact is also tagged with the tag
+// schema ("sha256-<digest>.<suffix>") so that it can be discovered from the image digest.
+func Push(ctx context.Context, image name.Digest, artifact Artifact, auth authn.Authenticator, now time.Time) (*Pushed, error) {
+	options := []remote.Option{remote.WithContext(ctx), remote.WithAuth(auth)}
+	repo := image.Context()
+
+	subject, err := remote.Head(image, options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the image %q in the registry: %w", image, err)
+	}
+
+	config := static.NewLayer([]byte(emptyConfig), EmptyConfigMediaType)
+	document := static.NewLayer(artifact.Document, artifact.MediaType)
+	for _, blob := range []v1.Layer{config, document} {
+		if err := remote.WriteLayer(repo, blob, options...); err != nil {
+			return nil, fmt.Errorf("unable to upload the artifact to %q: %w", repo, err)
+		}
+	}
+
+	configDescriptor, err := descriptor(config)
+	if err != nil {
+		return nil, err
+	}
+	documentDescriptor, err := descriptor(document)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{createdAnnotation: now.UTC().Format(time.RFC3339)}
+	for k, v := range artifact.Annotations {
+		annotations[k] = v
+	}
+
+	raw, err := json.Marshal(manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  artifact.ArtifactType,
+		Config:        *configDescriptor,
+		Layers:        []v1.Descriptor{*documentDescriptor},
+		Subject:       &v1.Descriptor{MediaType: subject.MediaType, Size: subject.Size, Digest: subject.Digest},
+		Annotations:   annotations,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the artifact manifest: %w", err)
+	}
+
+	digest, referrers, err := putManifest(ctx, repo, raw, auth)
+	if err != nil {
+		return nil, fmt.Errorf("unable to push the artifact to %q: %w", repo, err)
+	}
+
+	pushed := &Pushed{Manifest: repo.Digest(digest.String())}
+	if referrers {
+		return pushed, nil
+	}
+
+	tag := repo.Tag(TagFor(subject.Digest, artifact.TagSuffix))
+	if err := remote.Tag(tag, rawManifest{data: raw, mediaType: types.OCIManifestSchema1}, options...); err != nil {
+		return nil, fmt.Errorf("unable to tag the artifact as %q: %w", tag, err)
+	}
+	pushed.Tag = &tag
+	return pushed, nil
+}
+
+// TagFor returns the tag schema tag for artifacts of the given kind (e.g. "sbom") that refer to the given digest.
+func TagFor(digest v1.Hash, suffix string) string {
+	return fmt.Sprintf("%s-%s.%s", digest.Algorithm, digest.Hex, suffix)
+}
+
+// putManifest pushes the manifest by digest, and indicates if the registry processed the subject of the manifest
+// (i.e. implements the referrers API).
+func putManifest(ctx context.Context, repo name.Repository, raw []byte, auth authn.Authenticator) (v1.Hash, bool, error) {
+	digest, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return v1.Hash{}, false, err
+	}
+
+	t, err := transport.NewWithContext(ctx, repo.Registry, auth, remote.DefaultTransport, []string{repo.Scope(transport.PushScope)})
+	if err != nil {
+		return v1.Hash{}, false, err
+	}
+
+	u := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", repo.Registry.Scheme(), repo.RegistryStr(), repo.RepositoryStr(), digest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(raw))
+	if err != nil {
+		return v1.Hash{}, false, err
+	}
+	req.Header.Set("Content-Type", string(types.OCIManifestSchema1))
+
+	resp, err := (&http.Client{Transport: t}).Do(req)
+	if err != nil {
+		return v1.Hash{}, false, err
+	}
+	defer resp.Body.Close()
+
+	if err := transport.CheckError(resp, http.StatusOK, http.StatusCreated, http.StatusAccepted); err != nil {
+		return v1.Hash{}, false, err
+	}
+	return digest, strings.TrimSpace(resp.Header.Get(subjectHeader)) != "", nil
+}
+
+func descriptor(l v1.Layer) (*v1.Descriptor, error) {
+	digest, err := l.Digest()
+	if err != nil {
+		return nil, err
+	}
+	size, err := l.Size()
+	if err != nil {
+		return nil, err
+	}
+	mediaType, err := l.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Descriptor{MediaType: mediaType, Size: size, Digest: digest}, nil
+}
@@ -0,0 +1,133 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+)
+
+// pushImage pushes a random image to the registry and returns its digest.
+func pushImage(t *testing.T, host string) name.Digest {
+	t.Helper()
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+
+	tag, err := name.NewTag(host + "/app:1.0")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, img))
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	return tag.Context().Digest(digest.String())
+}
+
+func readManifest(t *testing.T, ref name.Reference) manifest {
+	t.Helper()
+	desc, err := remote.Get(ref)
+	require.NoError(t, err)
+	var m manifest
+	require.NoError(t, json.Unmarshal(desc.Manifest, &m))
+	return m
+}
+
+func TestPush_tagSchema(t *testing.T) {
+	// the registry does not implement the referrers API
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image := pushImage(t, host)
+	artifact, err := SBOMArtifact(syft.SPDXJSONFormatID, []byte(`{"spdxVersion": "SPDX-2.2"}`))
+	require.NoError(t, err)
+
+	pushed, err := Push(context.Background(), image, *artifact, authn.Anonymous, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NotNil(t, pushed.Tag)
+
+	assert.Equal(t, "sha256-"+strings.TrimPrefix(image.DigestStr(), "sha256:")+".sbom", pushed.Tag.TagStr())
+	assert.Equal(t, *pushed.Tag, pushed.Reference())
+
+	m := readManifest(t, *pushed.Tag)
+	assert.Equal(t, string(SPDXJSONMediaType), m.ArtifactType)
+	assert.Equal(t, EmptyConfigMediaType, m.Config.MediaType)
+	require.Len(t, m.Layers, 1)
+	assert.Equal(t, SPDXJSONMediaType, m.Layers[0].MediaType)
+	require.NotNil(t, m.Subject)
+	assert.Equal(t, image.DigestStr(), m.Subject.Digest.String())
+	assert.Equal(t, "2023-01-01T00:00:00Z", m.Annotations[createdAnnotation])
+
+	// the tag and the digest refer to the same manifest
+	assert.Equal(t, m, readManifest(t, pushed.Manifest))
+
+	layer, err := remote.Layer(image.Context().Digest(m.Layers[0].Digest.String()))
+	require.NoError(t, err)
+	rc, err := layer.Compressed()
+	require.NoError(t, err)
+	defer rc.Close()
+	var document map[string]interface{}
+	require.NoError(t, json.NewDecoder(rc).Decode(&document))
+	assert.Equal(t, "SPDX-2.2", document["spdxVersion"])
+}
+
+func TestPush_referrers(t *testing.T) {
+	// a registry that implements the referrers API acknowledges the subject of pushed manifests
+	handler := registry.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/sha256:") {
+			w.Header().Set(subjectHeader, "sha256:...")
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image := pushImage(t, host)
+	artifact := AttestationArtifact("https://spdx.dev/Document", []byte(`{"payloadType": "application/vnd.in-toto+json"}`))
+
+	pushed, err := Push(context.Background(), image, *artifact, authn.Anonymous, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, pushed.Tag)
+	assert.Equal(t, pushed.Manifest, pushed.Reference())
+
+	m := readManifest(t, pushed.Manifest)
+	assert.Equal(t, InTotoArtifactType, m.ArtifactType)
+	assert.Equal(t, DSSEEnvelopeMediaType, m.Layers[0].MediaType)
+	assert.Equal(t, "https://spdx.dev/Document", m.Annotations[predicateTypeAnnotation])
+
+	// there is no tag schema fallback
+	_, err = remote.Head(image.Context().Tag(TagFor(m.Subject.Digest, attestationTagSuffix)))
+	assert.Error(t, err)
+}
+
+func TestPush_imageNotInRegistry(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image, err := name.NewDigest(host + "/app@sha256:0000000000000000000000000000000000000000000000000000000000000000")
+	require.NoError(t, err)
+	artifact, err := SBOMArtifact(syft.JSONFormatID, []byte(`{}`))
+	require.NoError(t, err)
+
+	_, err = Push(context.Background(), image, *artifact, authn.Anonymous, time.Now())
+	assert.Error(t, err)
+}
+
+func TestSBOMArtifact_unsupportedFormat(t *testing.T) {
+	_, err := SBOMArtifact(syft.TableFormatID, []byte("NAME VERSION TYPE"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+/*
+Package policy evaluates SBOMs against user-provided policies (e.g. which licenses are acceptable).
+*/
+package policy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/query"
+	"github.com/docker/sbom-cli-plugin/internal/table"
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+	"gopkg.in/yaml.v2"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// LicensePolicy describes which package licenses are acceptable. Entries in the allow and deny lists are SPDX license
+// identifiers (optionally with "*" wildcards, e.g. "AGPL-*"), or SPDX license expressions. Packages that do not declare
+// a license have the license "NOASSERTION".
+type LicensePolicy struct {
+	Allow      []string           `yaml:"allow"`      // when given, every package license must be allowed
+	Deny       []string           `yaml:"deny"`       // package licenses that are never acceptable
+	Exceptions []LicenseException `yaml:"exceptions"` // packages that the allow and deny lists do not (fully) apply to
+
+	allow licenseMatcher
+	deny  licenseMatcher
+}
+
+// LicenseException exempts a package (optionally only specific versions and licenses of the package) from the policy.
+type LicenseException struct {
+	Package  string   `yaml:"package"`  // the package name or package URL prefix
+	Version  string   `yaml:"version"`  // an optional version constraint (e.g. "<2.0.0")
+	Licenses []string `yaml:"licenses"` // the licenses to exempt for the package (all licenses when empty)
+	Reason   string   `yaml:"reason"`   // why the exception was made
+
+	matcher  query.Matcher
+	licenses licenseMatcher
+}
+
+// LicenseViolation is a package with a license that does not meet the policy.
+type LicenseViolation struct {
+	Package pkg.Package
+	License string // the (normalized) license expression of the package
+	Reason  string // why the license does not meet the policy
+}
+
+// ReadLicensePolicy reads a license policy from the given YAML file.
+func ReadLicensePolicy(path string) (*LicensePolicy, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read license policy: %w", err)
+	}
+
+	var p LicensePolicy
+	if err := yaml.UnmarshalStrict(by, &p); err != nil {
+		return nil, fmt.Errorf("unable to parse license policy %q: %w", path, err)
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("invalid license policy %q: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *LicensePolicy) parse() (err error) {
+	if len(p.Allow) == 0 && len(p.Deny) == 0 {
+		return fmt.Errorf("at least one allowed or denied license is required")
+	}
+
+	if p.allow, err = newLicenseMatcher(p.Allow); err != nil {
+		return fmt.Errorf("bad allowed license: %w", err)
+	}
+
+	if p.deny, err = newLicenseMatcher(p.Deny); err != nil {
+		return fmt.Errorf("bad denied license: %w", err)
+	}
+
+	for i := range p.Exceptions {
+		e := &p.Exceptions[i]
+		if e.Package == "" {
+			return fmt.Errorf("exception %d: a package is required", i+1)
+		}
+
+		constraint, err := versions.ParseConstraint(e.Version)
+		if err != nil {
+			return fmt.Errorf("exception %d: %w", i+1, err)
+		}
+		e.matcher = query.Matcher{Package: e.Package, Constraint: constraint}
+
+		if e.licenses, err = newLicenseMatcher(e.Licenses); err != nil {
+			return fmt.Errorf("exception %d: bad license: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns all packages within the SBOM with licenses that do not meet the policy.
+func (p LicensePolicy) Evaluate(s sbom.SBOM) (violations []LicenseViolation) {
+	if s.Artifacts.PackageCatalog == nil {
+		return nil
+	}
+
+	for _, pk := range s.Artifacts.PackageCatalog.Sorted() {
+		exempt, skip := p.exemptions(pk)
+		if skip {
+			continue
+		}
+
+		expression := packageLicenseExpression(pk.Licenses)
+
+		if denied := p.denied(expression, exempt); len(denied) > 0 {
+			violations = append(violations, LicenseViolation{
+				Package: pk,
+				License: expression.String(),
+				Reason:  "denied license: " + strings.Join(denied, ", "),
+			})
+			continue
+		}
+
+		if len(p.allow) > 0 && !p.allowed(expression, exempt) {
+			violations = append(violations, LicenseViolation{
+				Package: pk,
+				License: expression.String(),
+				Reason:  "license not allowed",
+			})
+		}
+	}
+	return violations
+}
+
+// exemptions returns the licenses exempt from the policy for the given package, or if the package is exempt entirely.
+func (p LicensePolicy) exemptions(pk pkg.Package) (exempt licenseMatcher, all bool) {
+	for _, e := range p.Exceptions {
+		if !e.matcher.Matches(pk) {
+			continue
+		}
+		if len(e.licenses) == 0 {
+			return nil, true
+		}
+		exempt = append(exempt, e.licenses...)
+	}
+	return exempt, false
+}
+
+// denied returns the denied (sub-)expressions that make the expression unacceptable: for a disjunction (OR) every
+// alternative must be denied, for a conjunction (AND) any denied operand makes the expression denied.
+func (p LicensePolicy) denied(e *licenseExpression, exempt licenseMatcher) []string {
+	if exempt.matches(e) {
+		return nil
+	}
+	if p.deny.matches(e) {
+		return []string{e.String()}
+	}
+	if e.isLeaf() {
+		return nil
+	}
+
+	var denied []string
+	for _, o := range e.operands {
+		d := p.denied(o, exempt)
+		if e.operator == licenseOr && len(d) == 0 {
+			// at least one alternative is not denied
+			return nil
+		}
+		denied = append(denied, d...)
+	}
+	return denied
+}
+
+// allowed indicates if the expression is acceptable: for a disjunction (OR) any alternative must be allowed, for a
+// conjunction (AND) all operands must be allowed.
+func (p LicensePolicy) allowed(e *licenseExpression, exempt licenseMatcher) bool {
+	if exempt.matches(e) || p.allow.matches(e) {
+		return true
+	}
+	if e.isLeaf() {
+		return false
+	}
+
+	for _, o := range e.operands {
+		allowed := p.allowed(o, exempt)
+		switch {
+		case e.operator == licenseOr && allowed:
+			return true
+		case e.operator == licenseAnd && !allowed:
+			return false
+		}
+	}
+	return e.operator == licenseAnd
+}
+
+// WriteLicenseViolations writes a human-readable report of the given violations.
+func WriteLicenseViolations(w io.Writer, violations []LicenseViolation) error {
+	rows := make([][]string, 0, len(violations))
+	for _, v := range violations {
+		rows = append(rows, []string{v.Package.Name, v.Package.Version, string(v.Package.Type), v.License, v.Reason})
+	}
+
+	if _, err := fmt.Fprintf(w, "License policy violations (%d):\n", len(violations)); err != nil {
+		return err
+	}
+	return table.Write(w, []string{"Name", "Version", "Type", "License", "Reason"}, rows)
+}
@@ -0,0 +1,244 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// noAssertion is the license identifier for packages that do not declare any license.
+const noAssertion = "NOASSERTION"
+
+type licenseOperator string
+
+const (
+	licenseAnd  licenseOperator = "AND"
+	licenseOr   licenseOperator = "OR"
+	licenseWith licenseOperator = "WITH"
+)
+
+// licenseExpression is a parsed SPDX license expression: either a single license identifier (optionally with an
+// exception) or a conjunction/disjunction of sub-expressions.
+type licenseExpression struct {
+	id        string // the license identifier (set for leaf expressions only)
+	exception string // the license exception identifier (for "<id> WITH <exception>" leaf expressions)
+	operator  licenseOperator
+	operands  []*licenseExpression
+}
+
+func (e *licenseExpression) isLeaf() bool {
+	return e.operator == ""
+}
+
+// String renders the expression in a normalized form (used to compare expressions).
+func (e *licenseExpression) String() string {
+	if e.isLeaf() {
+		if e.exception != "" {
+			return e.id + " WITH " + e.exception
+		}
+		return e.id
+	}
+
+	parts := make([]string, 0, len(e.operands))
+	for _, o := range e.operands {
+		if o.isLeaf() {
+			parts = append(parts, o.String())
+			continue
+		}
+		parts = append(parts, "("+o.String()+")")
+	}
+	return strings.Join(parts, " "+string(e.operator)+" ")
+}
+
+// parseLicenseExpression parses an SPDX license expression (e.g. "(MIT OR Apache-2.0) AND BSD-3-Clause").
+func parseLicenseExpression(expression string) (*licenseExpression, error) {
+	p := &licenseParser{tokens: tokenizeLicenseExpression(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %w", expression, err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", expression, p.peek())
+	}
+	return e, nil
+}
+
+// packageLicenseExpression combines all declared licenses of a package into a single expression (where all declared
+// licenses apply). Declared licenses that are not valid SPDX expressions are treated as a single license identifier,
+// and packages that do not declare any license have the license "NOASSERTION".
+func packageLicenseExpression(licenses []string) *licenseExpression {
+	var operands []*licenseExpression
+	for _, l := range licenses {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		e, err := parseLicenseExpression(l)
+		if err != nil {
+			e = &licenseExpression{id: l}
+		}
+		operands = append(operands, e)
+	}
+
+	switch len(operands) {
+	case 0:
+		return &licenseExpression{id: noAssertion}
+	case 1:
+		return operands[0]
+	}
+	return &licenseExpression{operator: licenseAnd, operands: operands}
+}
+
+func tokenizeLicenseExpression(expression string) (tokens []string) {
+	expression = strings.ReplaceAll(expression, "(", " ( ")
+	expression = strings.ReplaceAll(expression, ")", " ) ")
+	return strings.Fields(expression)
+}
+
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *licenseParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *licenseParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *licenseParser) peekOperator(op licenseOperator) bool {
+	return strings.EqualFold(p.peek(), string(op))
+}
+
+func (p *licenseParser) parseOr() (*licenseExpression, error) {
+	return p.parseBinary(licenseOr, p.parseAnd)
+}
+
+func (p *licenseParser) parseAnd() (*licenseExpression, error) {
+	return p.parseBinary(licenseAnd, p.parseWith)
+}
+
+func (p *licenseParser) parseBinary(op licenseOperator, operand func() (*licenseExpression, error)) (*licenseExpression, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*licenseExpression{first}
+	for p.peekOperator(op) {
+		p.next()
+		o, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, o)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &licenseExpression{operator: op, operands: operands}, nil
+}
+
+func (p *licenseParser) parseWith() (*licenseExpression, error) {
+	e, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peekOperator(licenseWith) {
+		p.next()
+		if !e.isLeaf() || e.exception != "" {
+			return nil, fmt.Errorf("%s must follow a license identifier", licenseWith)
+		}
+		exception := p.next()
+		if !isLicenseIdentifier(exception) {
+			return nil, fmt.Errorf("expected a license exception identifier after %s", licenseWith)
+		}
+		e.exception = exception
+	}
+	return e, nil
+}
+
+func (p *licenseParser) parseAtom() (*licenseExpression, error) {
+	t := p.next()
+	switch {
+	case t == "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return e, nil
+	case isLicenseIdentifier(t):
+		return &licenseExpression{id: t}, nil
+	case t == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q", t)
+}
+
+func isLicenseIdentifier(t string) bool {
+	if t == "" || t == "(" || t == ")" {
+		return false
+	}
+	for _, op := range []licenseOperator{licenseAnd, licenseOr, licenseWith} {
+		if strings.EqualFold(t, string(op)) {
+			return false
+		}
+	}
+	return true
+}
+
+// licenseMatcher matches license expressions against a list of license identifiers, identifier patterns (e.g.
+// "AGPL-*"), or whole expressions.
+type licenseMatcher []*licenseExpression
+
+func newLicenseMatcher(entries []string) (licenseMatcher, error) {
+	var m licenseMatcher
+	for _, entry := range entries {
+		e, err := parseLicenseExpression(entry)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, e)
+	}
+	return m, nil
+}
+
+// matches indicates if the given expression is matched as a whole by any entry. Leaf expressions with an exception
+// are also matched by entries for the license identifier alone.
+func (m licenseMatcher) matches(e *licenseExpression) bool {
+	for _, entry := range m {
+		if strings.EqualFold(entry.String(), e.String()) {
+			return true
+		}
+		if !entry.isLeaf() || !e.isLeaf() {
+			continue
+		}
+		if entry.exception != "" && !strings.EqualFold(entry.exception, e.exception) {
+			continue
+		}
+		if matched, err := path.Match(strings.ToLower(entry.id), strings.ToLower(e.id)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
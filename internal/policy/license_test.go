@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+		wantErr    bool
+	}{
+		{expression: "MIT", want: "MIT"},
+		{expression: "MIT OR Apache-2.0", want: "MIT OR Apache-2.0"},
+		{expression: "(MIT or Apache-2.0) and BSD-3-Clause", want: "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{expression: "MIT OR Apache-2.0 AND BSD-3-Clause", want: "MIT OR (Apache-2.0 AND BSD-3-Clause)"},
+		{expression: "GPL-2.0-only WITH Classpath-exception-2.0", want: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{expression: "MIT AND", wantErr: true},
+		{expression: "(MIT", wantErr: true},
+		{expression: "MIT Apache-2.0", wantErr: true},
+		{expression: "WITH MIT", wantErr: true},
+		{expression: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			e, err := parseLicenseExpression(tt.expression)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.String())
+		})
+	}
+}
+
+func newLicensePolicy(t *testing.T, contents string) *LicensePolicy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+
+	p, err := ReadLicensePolicy(path)
+	require.NoError(t, err)
+	return p
+}
+
+func newSBOM(pkgs ...pkg.Package) sbom.SBOM {
+	for i := range pkgs {
+		pkgs[i].SetID()
+	}
+	return sbom.SBOM{Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(pkgs...)}}
+}
+
+func TestLicensePolicy_Evaluate(t *testing.T) {
+	s := newSBOM(
+		pkg.Package{Name: "agpl-lib", Version: "1.0.0", Type: pkg.NpmPkg, Licenses: []string{"AGPL-3.0-only"}},
+		pkg.Package{Name: "dual-lib", Version: "1.0.0", Type: pkg.NpmPkg, Licenses: []string{"AGPL-3.0-or-later OR MIT"}},
+		pkg.Package{Name: "mit-lib", Version: "1.0.0", Type: pkg.NpmPkg, Licenses: []string{"MIT"}},
+		pkg.Package{Name: "mixed-lib", Version: "1.0.0", Type: pkg.NpmPkg, Licenses: []string{"MIT", "AGPL-3.0-only"}},
+		pkg.Package{Name: "unknown-lib", Version: "1.0.0", Type: pkg.NpmPkg},
+		pkg.Package{Name: "zlib", Version: "1.2.11", Type: pkg.DebPkg, Licenses: []string{"Zlib"}},
+	)
+
+	tests := []struct {
+		name   string
+		policy string
+		want   map[string]string
+	}{
+		{
+			name:   "deny list",
+			policy: "deny: [AGPL-*, NOASSERTION]\n",
+			want: map[string]string{
+				"agpl-lib":    "denied license: AGPL-3.0-only",
+				"mixed-lib":   "denied license: AGPL-3.0-only",
+				"unknown-lib": "denied license: NOASSERTION",
+			},
+		},
+		{
+			name:   "allow list",
+			policy: "allow: [MIT, Zlib]\n",
+			want: map[string]string{
+				"agpl-lib":    "license not allowed",
+				"mixed-lib":   "license not allowed",
+				"unknown-lib": "license not allowed",
+			},
+		},
+		{
+			name: "exceptions",
+			policy: `
+deny: [AGPL-*, NOASSERTION]
+exceptions:
+  - package: agpl-lib
+    version: "<2.0.0"
+    reason: approved by legal
+  - package: mixed-lib
+    licenses: [AGPL-3.0-only]
+  - package: unknown-lib
+    version: ">=2.0.0"
+`,
+			want: map[string]string{
+				"unknown-lib": "denied license: NOASSERTION",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := newLicensePolicy(t, tt.policy).Evaluate(s)
+
+			got := make(map[string]string)
+			for _, v := range violations {
+				got[v.Package.Name] = v.Reason
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadLicensePolicy_invalid(t *testing.T) {
+	for name, contents := range map[string]string{
+		"empty":             "",
+		"unknown field":     "deny: [MIT]\nblock: [GPL-3.0-only]\n",
+		"bad expression":    "deny: [MIT OR]\n",
+		"bad exception":     "deny: [MIT]\nexceptions:\n  - version: <1.0\n",
+		"bad version range": "deny: [MIT]\nexceptions:\n  - package: foo\n    version: '<'\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+
+			_, err := ReadLicensePolicy(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWriteLicenseViolations(t *testing.T) {
+	violations := newLicensePolicy(t, "deny: [AGPL-3.0-only]\n").Evaluate(newSBOM(
+		pkg.Package{Name: "agpl-lib", Version: "1.0.0", Type: pkg.NpmPkg, Licenses: []string{"AGPL-3.0-only"}},
+	))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteLicenseViolations(&buf, violations))
+
+	// note: the table pads every column (including the last)
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	assert.Equal(t, `License policy violations (1):
+NAME      VERSION  TYPE  LICENSE        REASON
+agpl-lib  1.0.0    npm   AGPL-3.0-only  denied license: AGPL-3.0-only
+`, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,225 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/query"
+	"github.com/docker/sbom-cli-plugin/internal/sarif"
+	"github.com/docker/sbom-cli-plugin/internal/table"
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+	"gopkg.in/yaml.v2"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// PackagePolicy describes packages (and package versions) that must not be present.
+type PackagePolicy struct {
+	Deny []PackageRule `yaml:"deny"`
+}
+
+// PackageRule is a single denied package.
+type PackageRule struct {
+	ID      string `yaml:"id"`      // a stable identifier for the rule (defaults to "package-policy-<n>")
+	Package string `yaml:"package"` // the package name or package URL prefix, where "*" matches any sequence of characters
+	Version string `yaml:"version"` // an optional version constraint (e.g. ">=7.0.0, <7.68.0"), all versions are denied when empty
+	Reason  string `yaml:"reason"`  // why the package is denied
+	// Level is the SARIF level for violations: "error" (the default), "warning", or "note". Only violations of rules
+	// with the "error" level fail the policy.
+	Level string `yaml:"level"`
+
+	matcher query.Matcher
+}
+
+// PackageViolation is a package that is denied by a rule of the policy.
+type PackageViolation struct {
+	Rule    PackageRule
+	Package pkg.Package
+}
+
+// ReadPackagePolicy reads a package policy from the given YAML file.
+func ReadPackagePolicy(path string) (*PackagePolicy, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read package policy: %w", err)
+	}
+
+	var p PackagePolicy
+	if err := yaml.UnmarshalStrict(by, &p); err != nil {
+		return nil, fmt.Errorf("unable to parse package policy %q: %w", path, err)
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("invalid package policy %q: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *PackagePolicy) parse() error {
+	if len(p.Deny) == 0 {
+		return fmt.Errorf("at least one denied package is required")
+	}
+
+	ids := make(map[string]struct{})
+	for i := range p.Deny {
+		r := &p.Deny[i]
+		if r.Package == "" {
+			return fmt.Errorf("rule %d: a package is required", i+1)
+		}
+
+		if r.ID == "" {
+			r.ID = fmt.Sprintf("package-policy-%d", i+1)
+		}
+		if _, ok := ids[r.ID]; ok {
+			return fmt.Errorf("rule %d: duplicate rule ID %q", i+1, r.ID)
+		}
+		ids[r.ID] = struct{}{}
+
+		switch strings.ToLower(r.Level) {
+		case "":
+			r.Level = sarif.ErrorLevel
+		case sarif.ErrorLevel, sarif.WarningLevel, sarif.NoteLevel:
+			r.Level = strings.ToLower(r.Level)
+		default:
+			return fmt.Errorf("rule %q: bad level %q (options=%v)", r.ID, r.Level, []string{sarif.ErrorLevel, sarif.WarningLevel, sarif.NoteLevel})
+		}
+
+		constraint, err := versions.ParseConstraint(r.Version)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", r.ID, err)
+		}
+		r.matcher = query.Matcher{Package: r.Package, Constraint: constraint}
+	}
+	return nil
+}
+
+// Evaluate returns all packages within the SBOM that are denied by the policy.
+func (p PackagePolicy) Evaluate(s sbom.SBOM) (violations []PackageViolation) {
+	if s.Artifacts.PackageCatalog == nil {
+		return nil
+	}
+
+	for _, pk := range s.Artifacts.PackageCatalog.Sorted() {
+		for _, r := range p.Deny {
+			if r.matcher.Matches(pk) {
+				violations = append(violations, PackageViolation{Rule: r, Package: pk})
+			}
+		}
+	}
+	return violations
+}
+
+// Fails indicates if the violation fails the policy (i.e. the violated rule has the "error" level).
+func (v PackageViolation) Fails() bool {
+	return v.Rule.Level == sarif.ErrorLevel
+}
+
+func (r PackageRule) description() string {
+	d := "package " + r.Package
+	if r.Version != "" {
+		d += " (" + r.Version + ")"
+	}
+	return d + " is denied"
+}
+
+// WritePackageViolations writes a human-readable report of the given violations.
+func WritePackageViolations(w io.Writer, violations []PackageViolation) error {
+	rows := make([][]string, 0, len(violations))
+	for _, v := range violations {
+		rows = append(rows, []string{v.Package.Name, v.Package.Version, string(v.Package.Type), v.Rule.ID, v.Rule.Level, v.Rule.Reason})
+	}
+
+	if _, err := fmt.Fprintf(w, "Package policy violations (%d):\n", len(violations)); err != nil {
+		return err
+	}
+	return table.Write(w, []string{"Name", "Version", "Type", "Rule", "Level", "Reason"}, rows)
+}
+
+// SARIF creates a SARIF report for the given violations of the policy, where result locations are
+// the paths within the image (or other source) that each denied package was found by.
+func (p PackagePolicy) SARIF(violations []PackageViolation, s sbom.SBOM, tool sarif.ToolComponent) sarif.Report {
+	ruleIndex := make(map[string]int)
+	for i, r := range p.Deny {
+		ruleIndex[r.ID] = i
+
+		help := r.Reason
+		if help == "" {
+			help = r.description()
+		}
+		tool.Rules = append(tool.Rules, sarif.ReportingDescriptor{
+			ID:                   r.ID,
+			Name:                 "DeniedPackage",
+			ShortDescription:     &sarif.Message{Text: r.description()},
+			Help:                 &sarif.Message{Text: help},
+			DefaultConfiguration: &sarif.ReportingConfiguration{Level: r.Level},
+			Properties: map[string]interface{}{
+				"package": r.Package,
+				"version": r.Version,
+			},
+		})
+	}
+
+	sourceName := query.SourceName(s.Source)
+
+	results := make([]sarif.Result, 0, len(violations))
+	for _, v := range violations {
+		message := fmt.Sprintf("%s %s is denied by the package policy", v.Package.Name, v.Package.Version)
+		if v.Rule.Reason != "" {
+			message += ": " + v.Rule.Reason
+		}
+
+		var locations []sarif.Location
+		for _, l := range v.Package.Locations.ToSlice() {
+			locations = append(locations, sarif.Location{
+				PhysicalLocation: &sarif.PhysicalLocation{
+					ArtifactLocation: sarif.ArtifactLocation{
+						// paths are relative to the image root
+						URI:       strings.TrimPrefix(l.RealPath, "/"),
+						URIBaseID: sarif.ImageRootBaseID,
+					},
+				},
+				LogicalLocations: []sarif.LogicalLocation{{
+					Name:               v.Package.Name,
+					FullyQualifiedName: v.Package.PURL,
+					Kind:               "package",
+				}},
+			})
+		}
+
+		results = append(results, sarif.Result{
+			RuleID:    v.Rule.ID,
+			RuleIndex: ruleIndex[v.Rule.ID],
+			Level:     v.Rule.Level,
+			Message:   sarif.Message{Text: message},
+			Locations: locations,
+			PartialFingerprints: map[string]string{
+				// the same package in the same image should be considered the same finding across runs
+				"packageId": string(v.Package.ID()),
+			},
+			Properties: map[string]interface{}{
+				"name":    v.Package.Name,
+				"version": v.Package.Version,
+				"type":    string(v.Package.Type),
+				"purl":    v.Package.PURL,
+			},
+		})
+	}
+
+	root := sarif.ArtifactLocation{URI: "file:///"}
+	run := sarif.Run{
+		Tool:               sarif.Tool{Driver: tool},
+		OriginalURIBaseIDs: map[string]sarif.ArtifactLocation{sarif.ImageRootBaseID: root},
+		Results:            results,
+	}
+	if sourceName != "" {
+		root.Description = &sarif.Message{Text: "the root filesystem of " + sourceName}
+		run.OriginalURIBaseIDs[sarif.ImageRootBaseID] = root
+		// distinguishes the results for each image when uploaded to a code-scanning dashboard
+		run.AutomationDetails = &sarif.RunAutomationDetails{ID: "package-policy/" + sourceName + "/"}
+	}
+
+	return sarif.NewReport(run)
+}
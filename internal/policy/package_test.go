@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/sbom-cli-plugin/internal/sarif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/source"
+)
+
+const testPackagePolicy = `
+deny:
+  - id: vulnerable-curl
+    package: curl
+    version: ">=7.64.0, <7.64.0-4+deb10u2"
+    reason: CVE-2021-22876
+  - package: telnet*
+    reason: telnet servers are not allowed
+  - package: pkg:npm/left-pad
+    level: warning
+`
+
+func newPackagePolicy(t *testing.T, contents string) *PackagePolicy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+
+	p, err := ReadPackagePolicy(path)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPackagePolicy_Evaluate(t *testing.T) {
+	s := newSBOM(
+		pkg.Package{Name: "curl", Version: "7.64.0-4+deb10u1", Type: pkg.DebPkg},
+		pkg.Package{Name: "curl", Version: "7.64.0-4+deb10u2", Type: pkg.DebPkg, PURL: "pkg:deb/debian/curl@7.64.0-4+deb10u2"},
+		pkg.Package{Name: "telnetd", Version: "0.17-41.2", Type: pkg.DebPkg},
+		pkg.Package{Name: "left-pad", Version: "1.3.0", Type: pkg.NpmPkg, PURL: "pkg:npm/left-pad@1.3.0"},
+		pkg.Package{Name: "openssl", Version: "1.1.1n-0+deb10u1", Type: pkg.DebPkg},
+	)
+
+	violations := newPackagePolicy(t, testPackagePolicy).Evaluate(s)
+
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Rule.ID+" "+v.Package.Name+" "+v.Package.Version+" "+v.Rule.Level)
+	}
+	assert.ElementsMatch(t, []string{
+		"vulnerable-curl curl 7.64.0-4+deb10u1 error",
+		"package-policy-2 telnetd 0.17-41.2 error",
+		"package-policy-3 left-pad 1.3.0 warning",
+	}, got)
+
+	for _, v := range violations {
+		assert.Equal(t, v.Rule.Level == sarif.ErrorLevel, v.Fails())
+	}
+}
+
+func TestReadPackagePolicy_invalid(t *testing.T) {
+	for name, contents := range map[string]string{
+		"empty":           "",
+		"missing package": "deny:\n  - version: <1.0\n",
+		"bad level":       "deny:\n  - package: curl\n    level: fatal\n",
+		"duplicate id":    "deny:\n  - package: curl\n    id: a\n  - package: wget\n    id: a\n",
+		"bad version":     "deny:\n  - package: curl\n    version: '>'\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+
+			_, err := ReadPackagePolicy(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPackagePolicy_SARIF(t *testing.T) {
+	telnetd := pkg.Package{
+		Name:      "telnetd",
+		Version:   "0.17-41.2",
+		Type:      pkg.DebPkg,
+		PURL:      "pkg:deb/debian/telnetd@0.17-41.2",
+		Locations: source.NewLocationSet(source.NewLocation("/var/lib/dpkg/status")),
+	}
+	s := newSBOM(telnetd)
+	s.Source = source.Metadata{Scheme: source.ImageScheme, ImageMetadata: source.ImageMetadata{UserInput: "debian:10"}}
+
+	p := newPackagePolicy(t, testPackagePolicy)
+	report := p.SARIF(p.Evaluate(s), s, sarif.ToolComponent{Name: "docker-sbom", Version: "0.0.0"})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "2.1.0", doc["version"])
+
+	require.Len(t, report.Runs, 1)
+	run := report.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, 3)
+	assert.Equal(t, "package-policy/debian:10/", run.AutomationDetails.ID)
+
+	require.Len(t, run.Results, 1)
+	result := run.Results[0]
+	assert.Equal(t, "package-policy-2", result.RuleID)
+	assert.Equal(t, 1, result.RuleIndex)
+	assert.Equal(t, sarif.ErrorLevel, result.Level)
+	assert.Equal(t, "telnetd 0.17-41.2 is denied by the package policy: telnet servers are not allowed", result.Message.Text)
+
+	require.Len(t, result.Locations, 1)
+	assert.Equal(t, sarif.ArtifactLocation{URI: "var/lib/dpkg/status", URIBaseID: sarif.ImageRootBaseID}, result.Locations[0].PhysicalLocation.ArtifactLocation)
+}
@@ -0,0 +1,283 @@
+/*
+Package query searches previously generated SBOM documents for packages by name and version constraint.
+*/
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+// documentExtensions are the file extensions considered to be SBOM documents when searching a directory (files
+// given explicitly are always considered).
+var documentExtensions = map[string]struct{}{
+	".json": {},
+	".xml":  {},
+	".spdx": {},
+	".cdx":  {},
+	".sbom": {},
+}
+
+// Matcher selects packages by name (or package URL) and version constraint.
+type Matcher struct {
+	// Package is the package name (case insensitive) or a package URL prefix (e.g. "pkg:maven/org.apache.logging.log4j/log4j-core"),
+	// where the prefix must end at a boundary of the package URL (the end, or before "@", "?", "#", or "/"), so that
+	// "pkg:npm/lodash" does not select "pkg:npm/lodash.merge".
+	// Either may be a pattern where "*" matches any sequence of characters (e.g. "telnet*" or "pkg:deb/*/telnetd*").
+	Package    string
+	Constraint versions.Constraint // the version constraint that the package version must satisfy
+}
+
+// Matches indicates if the given package is selected by the matcher.
+func (m Matcher) Matches(p pkg.Package) bool {
+	switch {
+	case strings.HasPrefix(m.Package, "pkg:"):
+		if !matchPURL(m.Package, p.PURL) {
+			return false
+		}
+	case strings.Contains(m.Package, "*"):
+		if !matchPattern(m.Package, p.Name, true) {
+			return false
+		}
+	default:
+		if !strings.EqualFold(p.Name, m.Package) {
+			return false
+		}
+	}
+
+	return m.Constraint.Satisfied(versions.FormatForType(p.Type), p.Version)
+}
+
+// purlBoundaries are the characters that a package URL prefix may end before (besides the end of the package URL):
+// the version, qualifiers, subpath, and the name after a namespace.
+const purlBoundaries = "@?#/"
+
+// matchPURL matches a package URL against a prefix (which may be a pattern, see matchPattern) that ends at a boundary.
+func matchPURL(prefix, purl string) bool {
+	if strings.ContainsAny(prefix[len(prefix)-1:], purlBoundaries) {
+		return matchPattern(prefix+"*", purl, false)
+	}
+	if matchPattern(prefix, purl, false) {
+		return true
+	}
+	for _, boundary := range purlBoundaries {
+		if matchPattern(prefix+string(boundary)+"*", purl, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches a value against a pattern where "*" matches any sequence of characters.
+func matchPattern(pattern, value string, ignoreCase bool) bool {
+	if ignoreCase {
+		pattern = strings.ToLower(pattern)
+		value = strings.ToLower(value)
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 {
+			// the last part must match the end of the value
+			return strings.HasSuffix(value, part)
+		}
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return value == ""
+}
+
+// Match is a single package found within an SBOM document.
+type Match struct {
+	Image     string   `json:"image"`     // the image (or other source) that the SBOM document describes
+	Package   string   `json:"package"`   // the package name
+	Version   string   `json:"version"`   // the package version
+	Type      pkg.Type `json:"type"`      // the package type
+	PURL      string   `json:"purl"`      // the package URL
+	Locations []string `json:"locations"` // the paths within the image that the package was found by
+	Document  string   `json:"document"`  // the path of the SBOM document the package was found in
+}
+
+// Search decodes all SBOM documents found at the given paths (files, or directories searched recursively) and
+// returns every package selected by the matcher.
+func Search(paths []string, m Matcher) ([]Match, error) {
+	var matches []Match
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("unable to search %q: %w", root, err)
+		}
+
+		if !info.IsDir() {
+			doc, err := decode(root)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, search(*doc, m)...)
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if _, ok := documentExtensions[strings.ToLower(filepath.Ext(path))]; !ok {
+				return nil
+			}
+
+			doc, err := decode(path)
+			if err != nil {
+				// directories may contain other files that happen to share an extension with an SBOM document
+				log.Debugf("skipping %q: %+v", path, err)
+				return nil
+			}
+			matches = append(matches, search(*doc, m)...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to search %q: %w", root, err)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.Image != b.Image:
+			return a.Image < b.Image
+		case a.Package != b.Package:
+			return a.Package < b.Package
+		case a.Version != b.Version:
+			return versions.Compare(versions.FormatForType(a.Type), a.Version, b.Version) < 0
+		}
+		return a.Document < b.Document
+	})
+
+	return matches, nil
+}
+
+type document struct {
+	path string
+	name string
+	sbom sbom.SBOM
+}
+
+func decode(path string) (*document, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read SBOM %q: %w", path, err)
+	}
+
+	s, _, err := syft.Decode(bytes.NewReader(by))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode SBOM %q: %w", path, err)
+	}
+
+	name := SourceName(s.Source)
+	if name == "" {
+		// SPDX documents do not retain the source details when decoded, however the document name describes the source
+		name = spdxDocumentName(by)
+	}
+	if name == "" {
+		name = path
+	}
+
+	return &document{path: path, name: name, sbom: *s}, nil
+}
+
+var spdxTagValueNamePattern = regexp.MustCompile(`(?m)^DocumentName:\s*(.+?)\s*$`)
+
+func spdxDocumentName(by []byte) string {
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		Name        string `json:"name"`
+	}
+	if err := json.Unmarshal(by, &doc); err == nil {
+		if doc.SPDXVersion != "" {
+			return doc.Name
+		}
+		return ""
+	}
+
+	if match := spdxTagValueNamePattern.FindSubmatch(by); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+func search(doc document, m Matcher) (matches []Match) {
+	s := doc.sbom
+	if s.Artifacts.PackageCatalog == nil {
+		return nil
+	}
+
+	for _, p := range s.Artifacts.PackageCatalog.Sorted() {
+		if !m.Matches(p) {
+			continue
+		}
+
+		var locations []string
+		for _, l := range p.Locations.ToSlice() {
+			if l.VirtualPath != "" {
+				locations = append(locations, l.VirtualPath)
+				continue
+			}
+			locations = append(locations, l.RealPath)
+		}
+
+		matches = append(matches, Match{
+			Image:     doc.name,
+			Package:   p.Name,
+			Version:   p.Version,
+			Type:      p.Type,
+			PURL:      p.PURL,
+			Locations: locations,
+			Document:  doc.path,
+		})
+	}
+	return matches
+}
+
+// SourceName returns a human-friendly name for what an SBOM describes (e.g. the image reference).
+func SourceName(src source.Metadata) string {
+	switch src.Scheme {
+	case source.ImageScheme:
+		switch {
+		case src.ImageMetadata.UserInput != "":
+			return src.ImageMetadata.UserInput
+		case len(src.ImageMetadata.Tags) > 0:
+			return src.ImageMetadata.Tags[0]
+		case len(src.ImageMetadata.RepoDigests) > 0:
+			return src.ImageMetadata.RepoDigests[0]
+		}
+		return src.ImageMetadata.ID
+	case source.DirectoryScheme, source.FileScheme:
+		return src.Path
+	}
+	return ""
+}
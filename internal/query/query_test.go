@@ -0,0 +1,138 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+func writeSBOM(t *testing.T, path string, format sbom.FormatID, image string, pkgs ...pkg.Package) {
+	t.Helper()
+
+	catalog := pkg.NewCatalog()
+	for _, p := range pkgs {
+		catalog.Add(p)
+	}
+
+	by, err := syft.Encode(sbom.SBOM{
+		Artifacts: sbom.Artifacts{PackageCatalog: catalog},
+		Source: source.Metadata{
+			Scheme:        source.ImageScheme,
+			ImageMetadata: source.ImageMetadata{UserInput: image},
+		},
+	}, syft.FormatByID(format))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, by, 0600))
+}
+
+func newPackage(name, version string, ty pkg.Type, purl string) pkg.Package {
+	p := pkg.Package{
+		Name:      name,
+		Version:   version,
+		Type:      ty,
+		PURL:      purl,
+		Locations: source.NewLocationSet(source.NewLocation("/app/" + name + ".jar")),
+	}
+	p.SetID()
+	return p
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+
+	log4jOld := newPackage("log4j-core", "2.14.1", pkg.JavaPkg, "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1")
+	log4jNew := newPackage("log4j-core", "2.17.1", pkg.JavaPkg, "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1")
+	curl := newPackage("curl", "7.64.0-4+deb10u1", pkg.DebPkg, "pkg:deb/debian/curl@7.64.0-4+deb10u1")
+
+	writeSBOM(t, filepath.Join(dir, "a.json"), syft.JSONFormatID, "app:1.0", log4jOld, curl)
+	writeSBOM(t, filepath.Join(dir, "b.spdx.json"), syft.SPDXJSONFormatID, "app:2.0", log4jNew)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0700))
+	writeSBOM(t, filepath.Join(dir, "nested", "c.cdx.json"), syft.CycloneDxJSONFormatID, "legacy:0.1", log4jOld)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.json"), []byte(`{"not": "an sbom"}`), 0600))
+
+	// note: SPDX documents are named after the image, however the name is sanitized ("app:2.0" becomes "app-2.0")
+	tests := []struct {
+		name       string
+		pkg        string
+		constraint string
+		want       []string
+	}{
+		{name: "any version", pkg: "log4j-core", want: []string{"app-2.0 2.17.1", "app:1.0 2.14.1", "legacy:0.1 2.14.1"}},
+		{name: "version range", pkg: "Log4j-Core", constraint: "<2.17.0", want: []string{"app:1.0 2.14.1", "legacy:0.1 2.14.1"}},
+		{name: "purl prefix", pkg: "pkg:maven/org.apache.logging.log4j/", constraint: ">=2.17.0", want: []string{"app-2.0 2.17.1"}},
+		{name: "distro version", pkg: "curl", constraint: "<7.64.0-4+deb10u2", want: []string{"app:1.0 7.64.0-4+deb10u1"}},
+		{name: "no match", pkg: "openssl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := versions.ParseConstraint(tt.constraint)
+			require.NoError(t, err)
+
+			matches, err := Search([]string{dir}, Matcher{Package: tt.pkg, Constraint: constraint})
+			require.NoError(t, err)
+
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Image+" "+m.Version)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSearch_invalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"not": "an sbom"}`), 0600))
+
+	_, err := Search([]string{path}, Matcher{Package: "curl"})
+	assert.Error(t, err)
+}
+
+func TestMatcher_Matches(t *testing.T) {
+	telnetd := newPackage("telnetd", "0.17-41.2", pkg.DebPkg, "pkg:deb/debian/telnetd@0.17-41.2?arch=amd64")
+	curl := newPackage("curl", "7.64.0-4+deb10u1", pkg.DebPkg, "pkg:deb/debian/curl@7.64.0-4+deb10u1?arch=amd64")
+	curlDev := newPackage("curl-dev", "7.64.0-4+deb10u1", pkg.DebPkg, "pkg:deb/debian/curl-dev@7.64.0-4+deb10u1?arch=amd64")
+	lodashMerge := newPackage("lodash.merge", "4.6.2", pkg.NpmPkg, "pkg:npm/lodash.merge@4.6.2")
+	log4j := newPackage("log4j-core", "2.14.1", pkg.JavaPkg, "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1")
+
+	tests := []struct {
+		pattern string
+		p       pkg.Package
+		want    bool
+	}{
+		{pattern: "telnetd", p: telnetd, want: true},
+		{pattern: "TELNETD", p: telnetd, want: true},
+		{pattern: "telnet", p: telnetd, want: false},
+		{pattern: "telnet*", p: telnetd, want: true},
+		{pattern: "*net*", p: telnetd, want: true},
+		{pattern: "*net", p: telnetd, want: false},
+		{pattern: "t*l*d", p: telnetd, want: true},
+		{pattern: "telnet*", p: curl, want: false},
+		{pattern: "pkg:deb/debian/curl", p: curl, want: true},
+		{pattern: "pkg:deb/*/telnetd", p: telnetd, want: true},
+		{pattern: "pkg:deb/*/telnetd", p: curl, want: false},
+		{pattern: "pkg:*/curl@", p: curl, want: true},
+		{pattern: "pkg:deb/debian/curl", p: curlDev, want: false},
+		{pattern: "pkg:deb/*/curl", p: curlDev, want: false},
+		{pattern: "pkg:deb/debian/curl*", p: curlDev, want: true},
+		{pattern: "pkg:npm/lodash", p: lodashMerge, want: false},
+		{pattern: "pkg:npm/lodash.merge", p: lodashMerge, want: true},
+		{pattern: "pkg:maven/org.apache.logging.log4j", p: log4j, want: true},
+		{pattern: "pkg:maven/org.apache.logging", p: log4j, want: false},
+		{pattern: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", p: log4j, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.p.Name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Matcher{Package: tt.pattern}.Matches(tt.p))
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Package sarif provides the subset of the SARIF 2.1.0 (Static Analysis Results Interchange Format) object model needed
+to report findings within container images to code-scanning tools.
+*/
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// ImageRootBaseID is the base URI ID for paths within a container image.
+	ImageRootBaseID = "IMAGE_ROOT"
+)
+
+// Levels of a result (see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html#_Toc34317648).
+const (
+	ErrorLevel   = "error"
+	WarningLevel = "warning"
+	NoteLevel    = "note"
+)
+
+type Report struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool               Tool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]ArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []Result                    `json:"results"`
+	AutomationDetails  *RunAutomationDetails       `json:"automationDetails,omitempty"`
+	Properties         map[string]interface{}      `json:"properties,omitempty"`
+}
+
+type RunAutomationDetails struct {
+	ID string `json:"id,omitempty"`
+}
+
+type Tool struct {
+	Driver ToolComponent `json:"driver"`
+}
+
+type ToolComponent struct {
+	Name           string                `json:"name"`
+	Version        string                `json:"version,omitempty"`
+	InformationURI string                `json:"informationUri,omitempty"`
+	Rules          []ReportingDescriptor `json:"rules,omitempty"`
+}
+
+type ReportingDescriptor struct {
+	ID                   string                  `json:"id"`
+	Name                 string                  `json:"name,omitempty"`
+	ShortDescription     *Message                `json:"shortDescription,omitempty"`
+	FullDescription      *Message                `json:"fullDescription,omitempty"`
+	Help                 *Message                `json:"help,omitempty"`
+	DefaultConfiguration *ReportingConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{}  `json:"properties,omitempty"`
+}
+
+type ReportingConfiguration struct {
+	Level string `json:"level,omitempty"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Result struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level,omitempty"`
+	Message             Message                `json:"message"`
+	Locations           []Location             `json:"locations,omitempty"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []LogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+}
+
+type ArtifactLocation struct {
+	URI         string   `json:"uri,omitempty"`
+	URIBaseID   string   `json:"uriBaseId,omitempty"`
+	Description *Message `json:"description,omitempty"`
+}
+
+type LogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// NewReport creates a report with a single run.
+func NewReport(run Run) Report {
+	return Report{
+		Schema:  Schema,
+		Version: Version,
+		Runs:    []Run{run},
+	}
+}
+
+// Write encodes the report as JSON.
+func (r Report) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
@@ -0,0 +1,30 @@
+/*
+Package table renders rows of values as a plain-text table (matching the syft table output format).
+*/
+package table
+
+import (
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Write renders the given rows with the given column headers.
+func Write(w io.Writer, header []string, rows [][]string) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoFormatHeaders(true)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("  ")
+	table.SetNoWhiteSpace(true)
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
@@ -10,7 +10,9 @@ import (
 	"io"
 	"os"
 	"sync"
+	"time"
 
+	sbomEvent "github.com/docker/sbom-cli-plugin/internal/event"
 	"github.com/docker/sbom-cli-plugin/internal/log"
 	"github.com/docker/sbom-cli-plugin/internal/logger"
 	"github.com/docker/sbom-cli-plugin/internal/version"
@@ -18,6 +20,7 @@ import (
 	"github.com/wagoodman/go-partybus"
 	"github.com/wagoodman/jotframe/pkg/frame"
 
+	stereoscopeEvent "github.com/anchore/stereoscope/pkg/event"
 	syftEvent "github.com/anchore/syft/syft/event"
 	"github.com/anchore/syft/ui"
 )
@@ -38,13 +41,19 @@ import (
 // convention, each new event that the UI should respond to should be added either in this package as a handler function,
 // or in the shared ui package as a function on the main handler object. All handler functions should be completed
 // processing an event before the ETUI exits (coordinated with a sync.WaitGroup)
+//
+// Once the UI is torn down (after the report is written) a summary of the packages found is left on the screen.
 type ephemeralTerminalUI struct {
-	unsubscribe func() error
-	handler     *ui.Handler
-	waitGroup   *sync.WaitGroup
-	frame       *frame.Frame
-	logBuffer   *bytes.Buffer
-	uiOutput    *os.File
+	unsubscribe  func() error
+	handler      *ui.Handler
+	waitGroup    *sync.WaitGroup
+	frame        *frame.Frame
+	logBuffer    *bytes.Buffer
+	uiOutput     *os.File
+	start        time.Time
+	stopHeader   chan struct{}
+	headerDone   chan struct{}
+	packageTypes map[string]int64
 }
 
 // NewEphemeralTerminalUI writes all events to a TUI and writes the final report to the given writer.
@@ -58,6 +67,7 @@ func NewEphemeralTerminalUI() UI {
 
 func (h *ephemeralTerminalUI) Setup(unsubscribe func() error) error {
 	h.unsubscribe = unsubscribe
+	h.start = time.Now()
 	hideCursor(h.uiOutput)
 
 	// prep the logger to not clobber the screen from now on (logrus only)
@@ -73,6 +83,24 @@ func (h *ephemeralTerminalUI) Setup(unsubscribe func() error) error {
 func (h *ephemeralTerminalUI) Handle(event partybus.Event) error {
 	ctx := context.Background()
 	switch {
+	case event.Type == stereoscopeEvent.FetchImage:
+		if err := fetchImageHandler(ctx, h.frame, event, h.waitGroup); err != nil {
+			log.Errorf("unable to show %s event: %+v", event.Type, err)
+		}
+
+	case event.Type == sbomEvent.CatalogerStarted:
+		if err := catalogerHandler(ctx, h.frame, event, h.waitGroup); err != nil {
+			log.Errorf("unable to show %s event: %+v", event.Type, err)
+		}
+
+	case event.Type == sbomEvent.SBOMReady:
+		s, err := sbomEvent.ParseSBOMReady(event)
+		if err != nil {
+			log.Errorf("unable to show %s event: %+v", event.Type, err)
+			return nil
+		}
+		h.packageTypes = packageTypes(*s)
+
 	case h.handler.RespondsTo(event):
 		if err := h.handler.Handle(ctx, h.frame, event, h.waitGroup); err != nil {
 			log.Errorf("unable to show %s event: %+v", event.Type, err)
@@ -111,9 +139,34 @@ func (h *ephemeralTerminalUI) openScreen() error {
 		return err
 	}
 
-	content := color.Bold.Sprint("Syft") + " " + color.HEX("#777777").Sprint(version.FromBuild().SyftVersion)
-	_, err = header.Write([]byte(content))
-	return err
+	// the header shows the elapsed time until the screen is closed
+	h.stopHeader = make(chan struct{})
+	h.headerDone = make(chan struct{})
+	go func() {
+		defer close(h.headerDone)
+		ticker := time.NewTicker(etuiInterval)
+		defer ticker.Stop()
+		for {
+			h.writeHeader(header)
+			select {
+			case <-h.stopHeader:
+				h.writeHeader(header)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (h *ephemeralTerminalUI) writeHeader(header *frame.Line) {
+	content := color.Bold.Sprint("Syft") + " " + color.HEX("#777777").Sprint(version.FromBuild().SyftVersion) +
+		" " + color.HEX("#777777").Sprintf("[%s]", h.elapsed())
+	_, _ = header.Write([]byte(content))
+}
+
+func (h *ephemeralTerminalUI) elapsed() time.Duration {
+	return time.Since(h.start).Round(100 * time.Millisecond)
 }
 
 func (h *ephemeralTerminalUI) closeScreen(force bool) {
@@ -123,6 +176,8 @@ func (h *ephemeralTerminalUI) closeScreen(force bool) {
 		if !force {
 			h.waitGroup.Wait()
 		}
+		close(h.stopHeader)
+		<-h.headerDone
 		h.frame.Close()
 		// TODO: there is a race condition within frame.Close() that sometimes leads to an extra blank line being output
 		frame.Close()
@@ -146,6 +201,11 @@ func (h *ephemeralTerminalUI) flushLog() {
 func (h *ephemeralTerminalUI) Teardown(force bool) error {
 	h.closeScreen(force)
 	showCursor(h.uiOutput)
+
+	// the summary is written after the report (which is written to stdout when the screen is closed)
+	if !force && h.packageTypes != nil {
+		fmt.Fprintf(h.uiOutput, "%s in %s\n", summarize(h.packageTypes), time.Since(h.start).Round(time.Second))
+	}
 	return nil
 }
 
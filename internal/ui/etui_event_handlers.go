@@ -0,0 +1,138 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal/event"
+	"github.com/dustin/go-humanize"
+	"github.com/gookit/color"
+	"github.com/wagoodman/go-partybus"
+	"github.com/wagoodman/go-progress"
+	"github.com/wagoodman/jotframe/pkg/frame"
+
+	stereoscopeEventParsers "github.com/anchore/stereoscope/pkg/event/parsers"
+)
+
+const etuiInterval = 150 * time.Millisecond
+
+// statusTitleColumn matches the column of the status text of the syft UI rows.
+const statusTitleColumn = 31
+
+var (
+	titleFormat         = color.Bold
+	auxInfoFormat       = color.HEX("#777777")
+	statusTitleTemplate = fmt.Sprintf(" %%s %%-%ds ", statusTitleColumn)
+	spinnerFrames       = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	completedStatus     = color.Green.Sprint("✔")
+)
+
+type spinner struct {
+	index int
+}
+
+func (s *spinner) next() string {
+	frame := spinnerFrames[s.index%len(spinnerFrames)]
+	s.index++
+	return color.Magenta.Sprint(frame)
+}
+
+func writeRow(line *frame.Line, status, title, auxInfo string) {
+	_, _ = io.WriteString(line, fmt.Sprintf(statusTitleTemplate+"%s", status, titleFormat.Sprint(title), auxInfoFormat.Sprint(auxInfo)))
+}
+
+// fetchImageHandler shows the progress of saving the image from the daemon, with the image size and the throughput
+// (in place of the syft handler, which only shows a progress bar).
+func fetchImageHandler(ctx context.Context, fr *frame.Frame, e partybus.Event, wg *sync.WaitGroup) error {
+	_, prog, err := stereoscopeEventParsers.ParseFetchImage(e)
+	if err != nil {
+		return fmt.Errorf("bad %s event: %w", e.Type, err)
+	}
+
+	line, err := fr.Append()
+	if err != nil {
+		return err
+	}
+	wg.Add(1)
+
+	start := time.Now()
+	stream := progress.Stream(ctx, prog, etuiInterval)
+	s := &spinner{}
+
+	go func() {
+		defer wg.Done()
+
+		writeRow(line, s.next(), "Loading image", fmt.Sprintf("[%s]", prog.Stage()))
+		for p := range stream {
+			writeRow(line, s.next(), "Loading image", fmt.Sprintf("[%s] %s", prog.Stage(), transfer(p.Current(), p.Size(), time.Since(start))))
+		}
+		writeRow(line, completedStatus, "Loaded image", fmt.Sprintf("[%s]", transfer(prog.Current(), prog.Size(), time.Since(start))))
+	}()
+	return nil
+}
+
+// catalogerHandler shows a row for a cataloger with the files read and packages found, once the cataloger has read a
+// file (most catalogers do not find anything in an image).
+func catalogerHandler(ctx context.Context, fr *frame.Frame, e partybus.Event, wg *sync.WaitGroup) error {
+	monitor, err := event.ParseCatalogerStarted(e)
+	if err != nil {
+		return fmt.Errorf("bad %s event: %w", e.Type, err)
+	}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(etuiInterval)
+		defer ticker.Stop()
+
+		s := &spinner{}
+		var line *frame.Line
+		for {
+			files, packages := monitor.Files.Current(), monitor.Packages.Current()
+			done := progress.IsErrCompleted(monitor.Files.Error()) && progress.IsErrCompleted(monitor.Packages.Error())
+
+			if line == nil && (files > 0 || packages > 0) {
+				l, err := fr.Append()
+				if err != nil {
+					return
+				}
+				line = l
+			}
+			if line != nil {
+				status := s.next()
+				if done {
+					status = completedStatus
+				}
+				writeRow(line, status, "  "+monitor.Name, fmt.Sprintf("[%d files, %d packages]", files, packages))
+			}
+			if done {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// transfer describes the bytes transferred so far and the throughput, e.g. "12 MB / 30 MB (4.1 MB/s)".
+func transfer(current, size int64, elapsed time.Duration) string {
+	amount := humanize.Bytes(uint64(current))
+	if size > 0 && current < size {
+		amount += " / " + humanize.Bytes(uint64(size))
+	}
+	if elapsed < time.Second || current == 0 {
+		return amount
+	}
+	return fmt.Sprintf("%s (%s/s)", amount, humanize.Bytes(uint64(float64(current)/elapsed.Seconds())))
+}
@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+const jsonUpdateInterval = 500 * time.Millisecond
+
+// NewJSONUI writes all progress events as newline-delimited JSON records to the given writer (the final report is still
+// written to stdout).
+func NewJSONUI(out io.Writer) UI {
+	return newProgressUI(out, jsonUpdateInterval, encodeJSON)
+}
+
+func encodeJSON(r Record) ([]byte, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wagoodman/go-partybus"
+	"github.com/wagoodman/go-progress"
+
+	stereoscopeEvent "github.com/anchore/stereoscope/pkg/event"
+	"github.com/anchore/stereoscope/pkg/image"
+	syftEvent "github.com/anchore/syft/syft/event"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/pkg/cataloger"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+func readRecords(t *testing.T, buf *bytes.Buffer) []Record {
+	var records []Record
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r), "not a JSON record: %s", scanner.Text())
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestJSONUI(t *testing.T) {
+	var buf bytes.Buffer
+	u := NewJSONUI(&buf).(*progressUI)
+	u.interval = time.Millisecond
+
+	unsubscribed := false
+	require.NoError(t, u.Setup(func() error {
+		unsubscribed = true
+		return nil
+	}))
+
+	// progress.Manual is not safe for concurrent use, so the progress is completed before it is published
+	layer := &progress.Manual{N: 12}
+	layer.SetCompleted()
+	files, packages := &progress.Manual{N: 30}, &progress.Manual{N: 5}
+	files.SetCompleted()
+	packages.SetCompleted()
+
+	require.NoError(t, u.Handle(partybus.Event{
+		Type:   stereoscopeEvent.ReadLayer,
+		Source: image.LayerMetadata{Digest: "sha256:abc", Size: 1024},
+		Value:  progress.Monitorable(layer),
+	}))
+	catalogerFiles, catalogerPackages := &event.Counter{}, &event.Counter{}
+	catalogerFiles.Add(30)
+	catalogerFiles.SetCompleted()
+	catalogerPackages.Set(5)
+	catalogerPackages.SetCompleted()
+	require.NoError(t, u.Handle(partybus.Event{
+		Type:  event.CatalogerStarted,
+		Value: event.CatalogerMonitor{Name: "dpkgdb-cataloger", Files: catalogerFiles, Packages: catalogerPackages},
+	}))
+	require.NoError(t, u.Handle(partybus.Event{
+		Type:  syftEvent.PackageCatalogerStarted,
+		Value: cataloger.Monitor{FilesProcessed: files, PackagesDiscovered: packages},
+	}))
+
+	require.NoError(t, u.Handle(partybus.Event{
+		Type:  event.SBOMReady,
+		Value: sbom.SBOM{Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(pkg.Package{Name: "curl", Type: pkg.DebPkg})}},
+	}))
+
+	reported := false
+	require.NoError(t, u.Handle(partybus.Event{
+		Type: syftEvent.Exit,
+		Value: func() error {
+			reported = true
+			return nil
+		},
+	}))
+	require.NoError(t, u.Teardown(false))
+	assert.True(t, reported)
+	assert.True(t, unsubscribed)
+
+	records := readRecords(t, &buf)
+	require.NotEmpty(t, records)
+
+	last := map[string]Record{}
+	for _, r := range records {
+		last[r.Type] = r
+	}
+
+	assert.Equal(t, "sha256:abc", last[ReadLayerRecord].Layer)
+	assert.Equal(t, int64(12), *last[ReadLayerRecord].Current)
+	assert.Equal(t, int64(1024), *last[ReadLayerRecord].Size)
+	assert.True(t, last[ReadLayerRecord].Done)
+
+	assert.Equal(t, "dpkgdb-cataloger", last[CatalogerRecord].Cataloger)
+	assert.Equal(t, int64(30), *last[CatalogerRecord].Files)
+	assert.Equal(t, int64(5), *last[CatalogerRecord].Packages)
+	assert.True(t, last[CatalogerRecord].Done)
+
+	assert.Nil(t, last[CatalogPackagesRecord].Files)
+	assert.Equal(t, int64(5), *last[CatalogPackagesRecord].Packages)
+	assert.True(t, last[CatalogPackagesRecord].Done)
+
+	// the result is the last record
+	result := records[len(records)-1]
+	assert.Equal(t, ResultRecord, result.Type)
+	assert.Equal(t, SuccessStatus, result.Status)
+	assert.Equal(t, int64(1), *result.Packages)
+	assert.Equal(t, map[string]int64{"deb": 1}, result.PackageTypes)
+}
+
+func TestJSONUI_failure(t *testing.T) {
+	var buf bytes.Buffer
+	u := NewJSONUI(&buf)
+	require.NoError(t, u.Setup(func() error { return nil }))
+
+	// the progress never completes since the run fails
+	require.NoError(t, u.Handle(partybus.Event{
+		Type:   stereoscopeEvent.ReadLayer,
+		Source: image.LayerMetadata{Digest: "sha256:abc"},
+		Value:  progress.Monitorable(&progress.Manual{}),
+	}))
+	require.NoError(t, u.Teardown(true))
+
+	records := readRecords(t, &buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, ReadLayerRecord, records[0].Type)
+	assert.False(t, records[0].Done)
+	assert.Equal(t, Record{Type: ResultRecord, Elapsed: records[1].Elapsed, Status: FailureStatus, Done: true}, records[1])
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+const plainUpdateInterval = 5 * time.Second
+
+// NewPlainUI writes the progress of each stage to the given writer as single lines, at most once per stage every few
+// seconds (for logs of non-interactive runs, such as CI jobs).
+func NewPlainUI(out io.Writer) UI {
+	return newProgressUI(out, plainUpdateInterval, encodePlain)
+}
+
+func encodePlain(r Record) ([]byte, error) {
+	var line string
+	switch r.Type {
+	case FetchImageRecord:
+		line = fmt.Sprintf("fetching image %s: %s (%s)", r.Image, r.Stage, bytesOf(r.Current, r.Size))
+	case ReadImageRecord:
+		line = fmt.Sprintf("reading image %s: %s layers", shortDigest(r.Image), countOf(r.Current, r.Size))
+	case ReadLayerRecord:
+		if !r.Done && valueOf(r.Current) == 0 {
+			return nil, nil
+		}
+		line = fmt.Sprintf("reading layer %s: %d files", shortDigest(r.Layer), valueOf(r.Current))
+	case CatalogerRecord:
+		// most catalogers do not find anything in an image
+		if valueOf(r.Files) == 0 && valueOf(r.Packages) == 0 {
+			return nil, nil
+		}
+		line = fmt.Sprintf("cataloging with %s: %d files read, %d packages found", r.Cataloger, valueOf(r.Files), valueOf(r.Packages))
+	case CatalogPackagesRecord:
+		line = fmt.Sprintf("cataloging packages: %d packages found", valueOf(r.Packages))
+	case ResultRecord:
+		line = map[string]string{SuccessStatus: "done", FailureStatus: "failed"}[r.Status]
+		if r.Packages != nil {
+			line += ": " + summarize(r.PackageTypes)
+		}
+	default:
+		return nil, nil
+	}
+
+	if r.Done && r.Type != ResultRecord {
+		line += ", done"
+	}
+	return []byte(fmt.Sprintf("[%6.1fs] %s\n", r.Elapsed, line)), nil
+}
+
+func valueOf(n *int64) int64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+func countOf(current, size *int64) string {
+	if size == nil {
+		return fmt.Sprint(valueOf(current))
+	}
+	return fmt.Sprintf("%d/%d", valueOf(current), *size)
+}
+
+func bytesOf(current, size *int64) string {
+	if size == nil {
+		return humanize.Bytes(uint64(valueOf(current)))
+	}
+	return fmt.Sprintf("%s / %s", humanize.Bytes(uint64(valueOf(current))), humanize.Bytes(uint64(*size)))
+}
+
+func shortDigest(digest string) string {
+	algorithm, hex, found := strings.Cut(digest, ":")
+	if !found || len(hex) <= 12 {
+		return digest
+	}
+	return algorithm + ":" + hex[:12]
+}
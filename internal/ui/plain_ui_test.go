@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_encodePlain(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   Record
+		expected string
+	}{
+		{
+			name:     "fetch image",
+			record:   Record{Type: FetchImageRecord, Elapsed: 1.25, Image: "alpine:latest", Stage: "saving image", Current: count(2_000_000), Size: size(5_600_000)},
+			expected: "[   1.2s] fetching image alpine:latest: saving image (2.0 MB / 5.6 MB)\n",
+		},
+		{
+			name:     "read image",
+			record:   Record{Type: ReadImageRecord, Elapsed: 2, Image: "sha256:4a8d3e5c9a9b1d2e3f4a5b6c", Current: count(5), Size: size(5), Done: true},
+			expected: "[   2.0s] reading image sha256:4a8d3e5c9a9b: 5/5 layers, done\n",
+		},
+		{
+			name:   "layer without progress",
+			record: Record{Type: ReadLayerRecord, Layer: "sha256:abc", Current: count(0)},
+		},
+		{
+			name:     "cataloger",
+			record:   Record{Type: CatalogerRecord, Elapsed: 10, Cataloger: "dpkgdb-cataloger", Files: count(45), Packages: count(12), Done: true},
+			expected: "[  10.0s] cataloging with dpkgdb-cataloger: 45 files read, 12 packages found, done\n",
+		},
+		{
+			name:   "cataloger without results",
+			record: Record{Type: CatalogerRecord, Cataloger: "rpmdb-cataloger", Files: count(0), Packages: count(0), Done: true},
+		},
+		{
+			name:     "result with packages",
+			record:   Record{Type: ResultRecord, Elapsed: 38, Status: SuccessStatus, Done: true, Packages: count(412), PackageTypes: map[string]int64{"deb": 180, "npm": 232}},
+			expected: "[  38.0s] done: 412 packages (deb 180, npm 232)\n",
+		},
+		{
+			name:     "result",
+			record:   Record{Type: ResultRecord, Elapsed: 38, Status: FailureStatus, Done: true},
+			expected: "[  38.0s] failed\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line, err := encodePlain(test.record)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(line))
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal/event"
+	"github.com/docker/sbom-cli-plugin/internal/log"
+	"github.com/wagoodman/go-partybus"
+	"github.com/wagoodman/go-progress"
+
+	stereoscopeEvent "github.com/anchore/stereoscope/pkg/event"
+	stereoscopeEventParsers "github.com/anchore/stereoscope/pkg/event/parsers"
+	syftEvent "github.com/anchore/syft/syft/event"
+	syftEventParsers "github.com/anchore/syft/syft/event/parsers"
+)
+
+// Types of the progress records.
+const (
+	FetchImageRecord      = "fetch-image"
+	ReadImageRecord       = "read-image"
+	ReadLayerRecord       = "read-layer"
+	CatalogerRecord       = "cataloger"
+	CatalogPackagesRecord = "catalog-packages"
+	ResultRecord          = "result"
+)
+
+// Statuses of the result record.
+const (
+	SuccessStatus = "success"
+	FailureStatus = "failure"
+)
+
+// Record is the progress of a stage (or the final result) at a point in time. Progress records are written whenever
+// the progress changes (at most once per update interval) and once more when done, the result record is the last record.
+type Record struct {
+	Type      string  `json:"type"`
+	Elapsed   float64 `json:"elapsed"` // seconds since the start of the run
+	Image     string  `json:"image,omitempty"`
+	Layer     string  `json:"layer,omitempty"`
+	Cataloger string  `json:"cataloger,omitempty"`
+	Stage     string  `json:"stage,omitempty"`
+	Current   *int64  `json:"current,omitempty"`  // bytes (fetch-image), layers (read-image), or files (read-layer)
+	Size      *int64  `json:"size,omitempty"`     // the expected total of current (when known)
+	Files     *int64  `json:"files,omitempty"`    // files read by the cataloger(s)
+	Packages  *int64  `json:"packages,omitempty"` // packages discovered by the cataloger(s)
+	Done      bool    `json:"done,omitempty"`
+	Status    string  `json:"status,omitempty"` // the result status
+
+	// the packages in the SBOM by package type (result)
+	PackageTypes map[string]int64 `json:"packageTypes,omitempty"`
+}
+
+// progressUI writes the progress of the bus events as a stream of records, leaving the terminal untouched.
+type progressUI struct {
+	unsubscribe func() error
+	out         io.Writer
+	encode      func(Record) ([]byte, error)
+	interval    time.Duration
+	start       time.Time
+	lock        *sync.Mutex
+	waitGroup   *sync.WaitGroup
+	stop        chan struct{}
+	stopOnce    *sync.Once
+	finished    bool
+	types       map[string]int64
+}
+
+func newProgressUI(out io.Writer, interval time.Duration, encode func(Record) ([]byte, error)) *progressUI {
+	return &progressUI{
+		out:       out,
+		encode:    encode,
+		interval:  interval,
+		lock:      &sync.Mutex{},
+		waitGroup: &sync.WaitGroup{},
+		stop:      make(chan struct{}),
+		stopOnce:  &sync.Once{},
+	}
+}
+
+func (u *progressUI) Setup(unsubscribe func() error) error {
+	u.unsubscribe = unsubscribe
+	u.start = time.Now()
+	return nil
+}
+
+func (u *progressUI) Handle(e partybus.Event) error {
+	switch e.Type {
+	case stereoscopeEvent.FetchImage:
+		imgName, prog, err := stereoscopeEventParsers.ParseFetchImage(e)
+		if err != nil {
+			log.Warnf("unable to parse %s event: %+v", e.Type, err)
+			return nil
+		}
+		u.watch(func() (Record, bool) {
+			done := progress.IsCompleted(prog)
+			return Record{Type: FetchImageRecord, Image: imgName, Stage: prog.Stage(), Current: count(prog.Current()), Size: size(prog.Size()), Done: done}, done
+		})
+
+	case stereoscopeEvent.ReadImage:
+		metadata, prog, err := stereoscopeEventParsers.ParseReadImage(e)
+		if err != nil {
+			log.Warnf("unable to parse %s event: %+v", e.Type, err)
+			return nil
+		}
+		u.watch(func() (Record, bool) {
+			done := progress.IsCompleted(prog)
+			return Record{Type: ReadImageRecord, Image: metadata.ID, Current: count(prog.Current()), Size: size(prog.Size()), Done: done}, done
+		})
+
+	case stereoscopeEvent.ReadLayer:
+		metadata, prog, err := stereoscopeEventParsers.ParseReadLayer(e)
+		if err != nil {
+			log.Warnf("unable to parse %s event: %+v", e.Type, err)
+			return nil
+		}
+		u.watch(func() (Record, bool) {
+			done := progress.IsErrCompleted(prog.Error())
+			return Record{Type: ReadLayerRecord, Layer: metadata.Digest, Current: count(prog.Current()), Size: size(metadata.Size), Done: done}, done
+		})
+
+	case event.CatalogerStarted:
+		monitor, err := event.ParseCatalogerStarted(e)
+		if err != nil {
+			log.Warnf("unable to parse %s event: %+v", e.Type, err)
+			return nil
+		}
+		u.watch(func() (Record, bool) {
+			done := progress.IsErrCompleted(monitor.Files.Error()) && progress.IsErrCompleted(monitor.Packages.Error())
+			return Record{Type: CatalogerRecord, Cataloger: monitor.Name, Files: count(monitor.Files.Current()), Packages: count(monitor.Packages.Current()), Done: done}, done
+		})
+
+	case syftEvent.PackageCatalogerStarted:
+		monitor, err := syftEventParsers.ParsePackageCatalogerStarted(e)
+		if err != nil {
+			log.Warnf("unable to parse %s event: %+v", e.Type, err)
+			return nil
+		}
+		u.watch(func() (Record, bool) {
+			// the files are counted per cataloger (see CatalogerRecord)
+			done := progress.IsErrCompleted(monitor.PackagesDiscovered.Error())
+			return Record{Type: CatalogPackagesRecord, Packages: count(monitor.PackagesDiscovered.Current()), Done: done}, done
+		})
+
+	case event.SBOMReady:
+		s, err := event.ParseSBOMReady(e)
+		if err != nil {
+			log.Warnf("unable to parse %s event: %+v", e.Type, err)
+			return nil
+		}
+		u.lock.Lock()
+		u.types = packageTypes(*s)
+		u.lock.Unlock()
+
+	case syftEvent.Exit:
+		// write the latest progress before the result
+		u.stopWatching()
+		u.waitGroup.Wait()
+
+		status := SuccessStatus
+		if err := handleExit(e); err != nil {
+			log.Errorf("unable to show %s event: %+v", e.Type, err)
+			status = FailureStatus
+		}
+		u.finish(status)
+
+		// this is the last expected event, stop listening to events
+		return u.unsubscribe()
+	}
+	return nil
+}
+
+func (u *progressUI) Teardown(_ bool) error {
+	u.stopWatching()
+	u.waitGroup.Wait()
+	// the result has not been written when the run failed before the report
+	u.finish(FailureStatus)
+	return nil
+}
+
+// watch writes a record for the progress whenever it changes until it is done (or watching is stopped).
+func (u *progressUI) watch(snapshot func() (Record, bool)) {
+	u.waitGroup.Add(1)
+	go func() {
+		defer u.waitGroup.Done()
+		ticker := time.NewTicker(u.interval)
+		defer ticker.Stop()
+
+		var last *Record
+		for {
+			record, done := snapshot()
+			if last == nil || !reflect.DeepEqual(record, *last) {
+				u.write(record)
+				last = &record
+			}
+			if done {
+				return
+			}
+
+			select {
+			case <-ticker.C:
+			case <-u.stop:
+				// a last snapshot of the progress so far
+				if record, _ := snapshot(); !reflect.DeepEqual(record, *last) {
+					u.write(record)
+				}
+				return
+			}
+		}
+	}()
+}
+
+func (u *progressUI) stopWatching() {
+	u.stopOnce.Do(func() { close(u.stop) })
+}
+
+func (u *progressUI) finish(status string) {
+	u.lock.Lock()
+	finished := u.finished
+	u.finished = true
+	types := u.types
+	u.lock.Unlock()
+
+	if finished {
+		return
+	}
+	record := Record{Type: ResultRecord, Status: status, Done: true}
+	if status == SuccessStatus && types != nil {
+		var total int64
+		for _, n := range types {
+			total += n
+		}
+		record.Packages = count(total)
+		record.PackageTypes = types
+	}
+	u.write(record)
+}
+
+func (u *progressUI) write(record Record) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	record.Elapsed = time.Since(u.start).Seconds()
+	b, err := u.encode(record)
+	if err == nil {
+		_, err = u.out.Write(b)
+	}
+	if err != nil {
+		log.Warnf("unable to write %s progress: %+v", record.Type, err)
+	}
+}
+
+func count(n int64) *int64 {
+	return &n
+}
+
+// size is nil when the size is unknown.
+func size(n int64) *int64 {
+	if n <= 0 {
+		return nil
+	}
+	return &n
+}
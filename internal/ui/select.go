@@ -15,14 +15,23 @@ import (
 // is intended to be used and the UIs that follow are meant to be attempted only in a fallback posture when there
 // are environmental problems (e.g. cannot write to the terminal). A writer is provided to capture the output of
 // the final SBOM report.
-func Select(verbose, quiet bool) (uis []UI) {
+func Select(progress string, verbose, quiet bool) (uis []UI) {
 	isStdoutATty := term.IsTerminal(int(os.Stdout.Fd()))
 	isStderrATty := term.IsTerminal(int(os.Stderr.Fd()))
 	notATerminal := !isStderrATty && !isStdoutATty
 
 	switch {
-	case runtime.GOOS == "windows" || verbose || quiet || notATerminal || !isStderrATty:
+	case quiet:
 		uis = append(uis, NewLoggerUI())
+	case progress == JSONProgress:
+		uis = append(uis, NewJSONUI(os.Stderr))
+	case progress == PlainProgress:
+		uis = append(uis, NewPlainUI(os.Stderr))
+	case verbose:
+		uis = append(uis, NewLoggerUI())
+	case runtime.GOOS == "windows" || notATerminal || !isStderrATty:
+		// there is no terminal to show a terminal UI on, but the progress should still show up in logs
+		uis = append(uis, NewPlainUI(os.Stderr))
 	default:
 		uis = append(uis, NewEphemeralTerminalUI())
 	}
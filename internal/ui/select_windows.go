@@ -3,11 +3,21 @@
 
 package ui
 
+import "os"
+
 // Select is responsible for determining the specific UI function given select user option, the current platform
 // config values, and environment status (such as a TTY being present). The first UI in the returned slice of UIs
 // is intended to be used and the UIs that follow are meant to be attempted only in a fallback posture when there
 // are environmental problems (e.g. cannot write to the terminal). A writer is provided to capture the output of
 // the final SBOM report.
-func Select(verbose, quiet bool) (uis []UI) {
-	return append(uis, NewLoggerUI())
+func Select(progress string, verbose, quiet bool) (uis []UI) {
+	switch {
+	case quiet:
+		return append(uis, NewLoggerUI())
+	case progress == JSONProgress:
+		return append(uis, NewJSONUI(os.Stderr))
+	case verbose && progress != PlainProgress:
+		return append(uis, NewLoggerUI())
+	}
+	return append(uis, NewPlainUI(os.Stderr))
 }
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// packageTypes returns the number of packages in the SBOM by package type.
+func packageTypes(s sbom.SBOM) map[string]int64 {
+	types := make(map[string]int64)
+	if s.Artifacts.PackageCatalog == nil {
+		return types
+	}
+	for p := range s.Artifacts.PackageCatalog.Enumerate() {
+		types[string(p.Type)]++
+	}
+	return types
+}
+
+// summarize describes the number of packages by type, e.g. "412 packages (deb 180, npm 232)".
+func summarize(types map[string]int64) string {
+	var total int64
+	names := make([]string, 0, len(types))
+	for name, n := range types {
+		total += n
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	noun := "packages"
+	if total == 1 {
+		noun = "package"
+	}
+	if len(names) == 0 {
+		return fmt.Sprintf("%d %s", total, noun)
+	}
+
+	counts := make([]string, 0, len(names))
+	for _, name := range names {
+		counts = append(counts, fmt.Sprintf("%s %d", name, types[name]))
+	}
+	return fmt.Sprintf("%d %s (%s)", total, noun, strings.Join(counts, ", "))
+}
@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+func Test_summarize(t *testing.T) {
+	packages := []pkg.Package{
+		{Name: "curl", Version: "7.74.0", Type: pkg.DebPkg},
+		{Name: "zlib1g", Version: "1.2.11", Type: pkg.DebPkg},
+		{Name: "lodash", Version: "4.17.20", Type: pkg.NpmPkg},
+	}
+	for i := range packages {
+		packages[i].SetID()
+	}
+	s := sbom.SBOM{Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(packages...)}}
+
+	assert.Equal(t, map[string]int64{"deb": 2, "npm": 1}, packageTypes(s))
+	assert.Equal(t, "3 packages (deb 2, npm 1)", summarize(packageTypes(s)))
+	assert.Equal(t, "1 package (npm 1)", summarize(map[string]int64{"npm": 1}))
+	assert.Equal(t, "0 packages", summarize(packageTypes(sbom.SBOM{})))
+}
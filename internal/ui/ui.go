@@ -4,6 +4,15 @@ import (
 	"github.com/wagoodman/go-partybus"
 )
 
+// Values for --progress.
+const (
+	AutoProgress  = "auto"
+	PlainProgress = "plain"
+	JSONProgress  = "json"
+)
+
+var ProgressOptions = []string{AutoProgress, PlainProgress, JSONProgress}
+
 type UI interface {
 	Setup(unsubscribe func() error) error
 	partybus.Handler
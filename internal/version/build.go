@@ -22,42 +22,44 @@ var (
 
 // Version defines the application version details (generally from build information)
 type Version struct {
-	Version        string `json:"version"`        // application semantic version
-	SyftVersion    string `json:"syftVersion"`    // the version of syft being used by the docker-sbom-cli-plugin
-	GitCommit      string `json:"gitCommit"`      // git SHA at build-time
-	GitDescription string `json:"gitDescription"` // output of 'git describe --dirty --always --tags'
-	GoVersion      string `json:"goVersion"`      // go runtime version at build-time
-	Compiler       string `json:"compiler"`       // compiler used at build-time
-	Platform       string `json:"platform"`       // GOOS and GOARCH at build-time
+	Version            string `json:"version" yaml:"version"`                       // application semantic version
+	SyftVersion        string `json:"syftVersion" yaml:"syftVersion"`               // the version of syft being used by the docker-sbom-cli-plugin
+	StereoscopeVersion string `json:"stereoscopeVersion" yaml:"stereoscopeVersion"` // the version of stereoscope (the image library of syft) being used
+	GitCommit          string `json:"gitCommit" yaml:"gitCommit"`                   // git SHA at build-time
+	GitDescription     string `json:"gitDescription" yaml:"gitDescription"`         // output of 'git describe --dirty --always --tags'
+	GoVersion          string `json:"goVersion" yaml:"goVersion"`                   // go runtime version at build-time
+	Compiler           string `json:"compiler" yaml:"compiler"`                     // compiler used at build-time
+	Platform           string `json:"platform" yaml:"platform"`                     // GOOS and GOARCH at build-time
 }
 
 // FromBuild provides all version details
 func FromBuild() Version {
 	return Version{
-		Version:        version,
-		SyftVersion:    syftVersion(),
-		GitCommit:      gitCommit,
-		GitDescription: gitDescription,
-		GoVersion:      runtime.Version(),
-		Compiler:       runtime.Compiler,
-		Platform:       fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		Version:            version,
+		SyftVersion:        dependencyVersion("github.com/anchore/syft"),
+		StereoscopeVersion: dependencyVersion("github.com/anchore/stereoscope"),
+		GitCommit:          gitCommit,
+		GitDescription:     gitDescription,
+		GoVersion:          runtime.Version(),
+		Compiler:           runtime.Compiler,
+		Platform:           fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
 }
 
-func syftVersion() string {
+func dependencyVersion(path string) string {
 	buildInfo, ok := debug.ReadBuildInfo()
 	if !ok {
-		log.Warn("unable to find the buildinfo section of the binary (syft version is unknown)")
+		log.Warnf("unable to find the buildinfo section of the binary (%s version is unknown)", path)
 		return valueNotProvided
 	}
 
 	for _, d := range buildInfo.Deps {
-		if d.Path == "github.com/anchore/syft" {
+		if d.Path == path {
 			return d.Version
 		}
 	}
 
-	log.Warn("unable to find 'github.com/anchore/syft' from the buildinfo section of the binary")
+	log.Warnf("unable to find '%s' from the buildinfo section of the binary", path)
 
 	return valueNotProvided
 }
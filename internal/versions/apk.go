@@ -0,0 +1,110 @@
+package versions
+
+import (
+	"strings"
+)
+
+// apk suffixes in sort order: pre-release suffixes sort before a version without a suffix, all others sort after.
+var apkSuffixes = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"":      0,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+type apkVersion struct {
+	numbers  []string
+	letter   string
+	suffixes []apkSuffix
+	revision string
+}
+
+type apkSuffix struct {
+	rank   int
+	number string
+}
+
+// compareApk compares two Alpine package versions (e.g. "1.2.3a_rc1-r2") following the apk-tools ordering rules.
+func compareApk(a, b string) int {
+	av := parseApk(strings.TrimSpace(a))
+	bv := parseApk(strings.TrimSpace(b))
+
+	for i := 0; i < len(av.numbers) || i < len(bv.numbers); i++ {
+		switch {
+		case i >= len(av.numbers):
+			return -1
+		case i >= len(bv.numbers):
+			return 1
+		}
+		if c := compareNumeric(av.numbers[i], bv.numbers[i]); c != 0 {
+			return c
+		}
+	}
+
+	if c := strings.Compare(av.letter, bv.letter); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(av.suffixes) || i < len(bv.suffixes); i++ {
+		as, bs := apkSuffix{}, apkSuffix{}
+		if i < len(av.suffixes) {
+			as = av.suffixes[i]
+		}
+		if i < len(bv.suffixes) {
+			bs = bv.suffixes[i]
+		}
+		if as.rank != bs.rank {
+			return as.rank - bs.rank
+		}
+		if c := compareNumeric(as.number, bs.number); c != 0 {
+			return c
+		}
+	}
+
+	return compareNumeric(av.revision, bv.revision)
+}
+
+func parseApk(v string) apkVersion {
+	var result apkVersion
+
+	if idx := strings.LastIndex(v, "-r"); idx >= 0 {
+		if rev, rest := takeWhile(v[idx+2:], isDigit); rest == "" && rev != "" {
+			result.revision = rev
+			v = v[:idx]
+		}
+	}
+
+	var suffixes []string
+	if idx := strings.Index(v, "_"); idx >= 0 {
+		suffixes = strings.Split(v[idx+1:], "_")
+		v = v[:idx]
+	}
+
+	for _, field := range strings.Split(v, ".") {
+		digits, rest := takeWhile(field, isDigit)
+		result.numbers = append(result.numbers, digits)
+		if rest != "" {
+			// a single trailing letter is allowed on the last number (e.g. "1.2.3a")
+			result.letter = rest
+			break
+		}
+	}
+
+	for _, s := range suffixes {
+		name, number := takeWhile(s, isAlpha)
+		rank, ok := apkSuffixes[name]
+		if !ok {
+			// unknown suffixes are ordered after all known suffixes
+			rank = len(apkSuffixes)
+		}
+		result.suffixes = append(result.suffixes, apkSuffix{rank: rank, number: number})
+	}
+
+	return result
+}
@@ -0,0 +1,100 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var constraintTermPattern = regexp.MustCompile(`^(<=|>=|==|!=|<|>|=)?\s*([0-9A-Za-z]\S*)$`)
+
+// Constraint is a set of version conditions. A version satisfies the constraint when it satisfies all conditions of
+// at least one group: terms within a group are separated by commas (AND) and groups are separated by "||" (OR),
+// for example ">=2.0.0, <2.17.0 || =1.2.3".
+type Constraint struct {
+	raw    string
+	groups [][]constraintTerm
+}
+
+type constraintTerm struct {
+	operator string
+	version  string
+}
+
+// ParseConstraint parses the given constraint expression. An empty expression matches every version.
+func ParseConstraint(expression string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(expression)}
+	if c.raw == "" {
+		return c, nil
+	}
+
+	for _, group := range strings.Split(c.raw, "||") {
+		var terms []constraintTerm
+		for _, term := range strings.Split(group, ",") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+			match := constraintTermPattern.FindStringSubmatch(term)
+			if match == nil {
+				return Constraint{}, fmt.Errorf("invalid version constraint term %q in %q", term, expression)
+			}
+			operator := match[1]
+			if operator == "" || operator == "==" {
+				operator = "="
+			}
+			terms = append(terms, constraintTerm{operator: operator, version: match[2]})
+		}
+		if len(terms) == 0 {
+			return Constraint{}, fmt.Errorf("invalid version constraint %q: empty condition", expression)
+		}
+		c.groups = append(c.groups, terms)
+	}
+
+	return c, nil
+}
+
+// Satisfied indicates if the given version (compared using the given format) meets the constraint.
+func (c Constraint) Satisfied(f Format, version string) bool {
+	if len(c.groups) == 0 {
+		return true
+	}
+
+	for _, group := range c.groups {
+		if c.groupSatisfied(f, version, group) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) groupSatisfied(f Format, version string, terms []constraintTerm) bool {
+	for _, term := range terms {
+		if !term.satisfied(Compare(f, version, term.version)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t constraintTerm) satisfied(comparison int) bool {
+	switch t.operator {
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	case "!=":
+		return comparison != 0
+	default:
+		return comparison == 0
+	}
+}
+
+// String returns the original constraint expression.
+func (c Constraint) String() string {
+	return c.raw
+}
@@ -0,0 +1,84 @@
+package versions
+
+import "strings"
+
+// compareDpkg compares two Debian package versions following the algorithm in deb-version(7).
+func compareDpkg(a, b string) int {
+	aEpoch, aRest := splitEpoch(strings.TrimSpace(a))
+	bEpoch, bRest := splitEpoch(strings.TrimSpace(b))
+
+	if c := compareNumeric(aEpoch, bEpoch); c != 0 {
+		return c
+	}
+
+	aUpstream, aRevision := splitDpkgRevision(aRest)
+	bUpstream, bRevision := splitDpkgRevision(bRest)
+
+	if c := compareDpkgPart(aUpstream, bUpstream); c != 0 {
+		return c
+	}
+	return compareDpkgPart(aRevision, bRevision)
+}
+
+func splitDpkgRevision(v string) (string, string) {
+	if idx := strings.LastIndex(v, "-"); idx >= 0 {
+		return v[:idx], v[idx+1:]
+	}
+	return v, ""
+}
+
+// dpkgOrder returns the sort weight of a character in the non-digit portion of a version: the tilde sorts before
+// everything (even the end of the string), letters sort before non-letters.
+func dpkgOrder(c byte) int {
+	switch {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func compareDpkgPart(a, b string) int {
+	for len(a) > 0 || len(b) > 0 {
+		// compare the non-digit prefix
+		for (len(a) > 0 && !isDigit(a[0])) || (len(b) > 0 && !isDigit(b[0])) {
+			var ac, bc int
+			if len(a) > 0 {
+				ac = dpkgOrder(a[0])
+			}
+			if len(b) > 0 {
+				bc = dpkgOrder(b[0])
+			}
+			if ac != bc {
+				return ac - bc
+			}
+			if len(a) > 0 {
+				a = a[1:]
+			}
+			if len(b) > 0 {
+				b = b[1:]
+			}
+		}
+
+		// compare the digit prefix
+		var aDigits, bDigits string
+		aDigits, a = takeWhile(a, isDigit)
+		bDigits, b = takeWhile(b, isDigit)
+		if c := compareNumeric(aDigits, bDigits); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func takeWhile(s string, fn func(byte) bool) (string, string) {
+	i := 0
+	for i < len(s) && fn(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
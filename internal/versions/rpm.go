@@ -0,0 +1,123 @@
+package versions
+
+import "strings"
+
+// compareRpm compares two RPM package versions ([epoch:]version[-release]) following the rpmvercmp algorithm. The
+// release is only considered when both versions specify one.
+func compareRpm(a, b string) int {
+	aEpoch, aRest := splitEpoch(strings.TrimSpace(a))
+	bEpoch, bRest := splitEpoch(strings.TrimSpace(b))
+
+	if c := compareNumeric(aEpoch, bEpoch); c != 0 {
+		return c
+	}
+
+	aVersion, aRelease := splitRpmRelease(aRest)
+	bVersion, bRelease := splitRpmRelease(bRest)
+
+	if c := rpmvercmp(aVersion, bVersion); c != 0 {
+		return c
+	}
+
+	if aRelease == "" || bRelease == "" {
+		return 0
+	}
+	return rpmvercmp(aRelease, bRelease)
+}
+
+func splitRpmRelease(v string) (string, string) {
+	if idx := strings.LastIndex(v, "-"); idx >= 0 {
+		return v[:idx], v[idx+1:]
+	}
+	return v, ""
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
+
+// nolint:funlen,gocognit
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	for len(a) > 0 || len(b) > 0 {
+		// skip separators (anything that is not alphanumeric, a tilde, or a caret)
+		for len(a) > 0 && !isAlnum(a[0]) && a[0] != '~' && a[0] != '^' {
+			a = a[1:]
+		}
+		for len(b) > 0 && !isAlnum(b[0]) && b[0] != '~' && b[0] != '^' {
+			b = b[1:]
+		}
+
+		// the tilde sorts before everything else (including the end of the version)
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// the caret sorts after the end of the version but before everything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			switch {
+			case len(a) == 0:
+				return -1
+			case len(b) == 0:
+				return 1
+			case !strings.HasPrefix(a, "^"):
+				return 1
+			case !strings.HasPrefix(b, "^"):
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if len(a) == 0 || len(b) == 0 {
+			break
+		}
+
+		var aSegment, bSegment string
+		isNumeric := isDigit(a[0])
+		if isNumeric {
+			aSegment, a = takeWhile(a, isDigit)
+			bSegment, b = takeWhile(b, isDigit)
+		} else {
+			aSegment, a = takeWhile(a, isAlpha)
+			bSegment, b = takeWhile(b, isAlpha)
+		}
+
+		// segments of different types: numeric segments are always newer than alpha segments
+		if bSegment == "" {
+			if isNumeric {
+				return 1
+			}
+			return -1
+		}
+
+		var c int
+		if isNumeric {
+			c = compareNumeric(aSegment, bSegment)
+		} else {
+			c = strings.Compare(aSegment, bSegment)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return -1
+	default:
+		return 1
+	}
+}
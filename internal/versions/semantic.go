@@ -0,0 +1,132 @@
+package versions
+
+import (
+	"strings"
+)
+
+// qualifier ranks relative to a release (rank 0): pre-release qualifiers sort before the release, post-release
+// qualifiers sort after. This covers the common semver, PEP 440, Maven, and RubyGems conventions.
+var semanticQualifiers = map[string]int{
+	"dev":       -6,
+	"alpha":     -5,
+	"a":         -5,
+	"beta":      -4,
+	"b":         -4,
+	"milestone": -3,
+	"m":         -3,
+	"rc":        -2,
+	"cr":        -2,
+	"c":         -2,
+	"pre":       -2,
+	"preview":   -2,
+	"snapshot":  -1,
+	"ga":        0,
+	"final":     0,
+	"release":   0,
+	"sp":        1,
+	"post":      1,
+	"p":         1,
+	"patch":     1,
+}
+
+// unknown qualifiers (e.g. "1.0.0-foo") are treated as pre-releases, as semver does for all pre-release identifiers
+const unknownQualifierRank = -1
+
+type semanticToken struct {
+	value   string
+	numeric bool
+}
+
+func (t semanticToken) rank() int {
+	if t.numeric {
+		return 0
+	}
+	if rank, ok := semanticQualifiers[t.value]; ok {
+		return rank
+	}
+	return unknownQualifierRank
+}
+
+// compareSemantic compares two versions by their numeric and alphabetic components (ignoring separators), where
+// missing numeric components are considered zero ("1.0" == "1.0.0") and qualifiers are ranked relative to a
+// release ("1.0.0-rc1" < "1.0.0" < "1.0.0-sp1").
+func compareSemantic(a, b string) int {
+	at := tokenizeSemantic(a)
+	bt := tokenizeSemantic(b)
+
+	for i := 0; i < len(at) || i < len(bt); i++ {
+		switch {
+		case i >= len(at):
+			return -compareSemanticToRelease(bt[i:])
+		case i >= len(bt):
+			return compareSemanticToRelease(at[i:])
+		}
+
+		x, y := at[i], bt[i]
+		switch {
+		case x.numeric && y.numeric:
+			if c := compareNumeric(x.value, y.value); c != 0 {
+				return c
+			}
+		case x.numeric:
+			// a further version component is always newer than a qualifier ("1.0.1" > "1.0-post1")
+			return 1
+		case y.numeric:
+			return -1
+		default:
+			if c := x.rank() - y.rank(); c != 0 {
+				return c
+			}
+			if x.rank() == unknownQualifierRank {
+				if c := strings.Compare(x.value, y.value); c != 0 {
+					return c
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// compareSemanticToRelease compares the remaining tokens of a longer version to the end of a shorter version.
+func compareSemanticToRelease(remaining []semanticToken) int {
+	for _, t := range remaining {
+		if t.numeric {
+			if trimLeadingZeros(t.value) != "" {
+				return 1
+			}
+			continue
+		}
+		if rank := t.rank(); rank != 0 {
+			return rank
+		}
+	}
+	return 0
+}
+
+func tokenizeSemantic(v string) (tokens []semanticToken) {
+	v = strings.ToLower(strings.TrimSpace(v))
+
+	// build metadata does not participate in ordering
+	if idx := strings.Index(v, "+"); idx >= 0 {
+		v = v[:idx]
+	}
+
+	if len(v) > 1 && v[0] == 'v' && isDigit(v[1]) {
+		v = v[1:]
+	}
+
+	for len(v) > 0 {
+		var value string
+		switch {
+		case isDigit(v[0]):
+			value, v = takeWhile(v, isDigit)
+			tokens = append(tokens, semanticToken{value: value, numeric: true})
+		case isAlpha(v[0]):
+			value, v = takeWhile(v, isAlpha)
+			tokens = append(tokens, semanticToken{value: value})
+		default:
+			v = v[1:]
+		}
+	}
+	return tokens
+}
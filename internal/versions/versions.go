@@ -0,0 +1,115 @@
+/*
+Package versions compares package versions using the ordering rules of the ecosystem the package belongs to (e.g. the
+Debian and RPM version ordering rules for distro packages, and a semantic version ordering for language packages).
+*/
+package versions
+
+import (
+	"github.com/anchore/syft/syft/pkg"
+)
+
+// Format is the version ordering scheme used for comparing two versions of a package.
+type Format string
+
+const (
+	// SemanticFormat is a lenient semantic version ordering that also handles the pre- and post-release qualifiers
+	// used by the language ecosystems (e.g. "1.0.0-rc1" < "1.0.0" < "1.0.0.post1").
+	SemanticFormat Format = "semantic"
+	// DpkgFormat is the Debian package version ordering ([epoch:]upstream[-revision]).
+	DpkgFormat Format = "dpkg"
+	// RpmFormat is the RPM package version ordering ([epoch:]version[-release]).
+	RpmFormat Format = "rpm"
+	// ApkFormat is the Alpine package version ordering (version[_suffix][-rREVISION]).
+	ApkFormat Format = "apk"
+)
+
+// AllFormats lists every supported version format.
+var AllFormats = []Format{SemanticFormat, DpkgFormat, RpmFormat, ApkFormat}
+
+// FormatForType returns the version format used by the given package type.
+func FormatForType(t pkg.Type) Format {
+	switch t {
+	case pkg.DebPkg:
+		return DpkgFormat
+	case pkg.RpmPkg:
+		return RpmFormat
+	case pkg.ApkPkg:
+		return ApkFormat
+	default:
+		return SemanticFormat
+	}
+}
+
+// Compare returns an integer comparing two versions using the given format. The result will be 0 if a == b, -1 if
+// a < b, and +1 if a > b.
+func Compare(f Format, a, b string) int {
+	var result int
+	switch f {
+	case DpkgFormat:
+		result = compareDpkg(a, b)
+	case RpmFormat:
+		result = compareRpm(a, b)
+	case ApkFormat:
+		result = compareApk(a, b)
+	default:
+		result = compareSemantic(a, b)
+	}
+	return sign(result)
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// compareNumeric compares two strings of digits by numeric value without integer overflow concerns.
+func compareNumeric(a, b string) int {
+	a = trimLeadingZeros(a)
+	b = trimLeadingZeros(b)
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func trimLeadingZeros(s string) string {
+	for len(s) > 0 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
+
+// splitEpoch splits an optional "epoch:" prefix from the given version (the epoch defaults to "0").
+func splitEpoch(v string) (string, string) {
+	for i := 0; i < len(v); i++ {
+		if v[i] == ':' {
+			if i == 0 {
+				return "0", v[1:]
+			}
+			return v[:i], v[i+1:]
+		}
+		if !isDigit(v[i]) {
+			break
+		}
+	}
+	return "0", v
+}
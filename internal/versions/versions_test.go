@@ -0,0 +1,98 @@
+package versions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		format Format
+		a      string
+		b      string
+		want   int
+	}{
+		// semantic
+		{format: SemanticFormat, a: "2.16.0", b: "2.17.0", want: -1},
+		{format: SemanticFormat, a: "2.17.0", b: "2.17.0", want: 0},
+		{format: SemanticFormat, a: "v2.17.0", b: "2.17", want: 0},
+		{format: SemanticFormat, a: "2.17.1", b: "2.17.0", want: 1},
+		{format: SemanticFormat, a: "2.10.0", b: "2.9.0", want: 1},
+		{format: SemanticFormat, a: "2.17.0-rc1", b: "2.17.0", want: -1},
+		{format: SemanticFormat, a: "2.17.0-rc1", b: "2.17.0-rc2", want: -1},
+		{format: SemanticFormat, a: "2.17.0-beta1", b: "2.17.0-rc1", want: -1},
+		{format: SemanticFormat, a: "1.0.dev1", b: "1.0a1", want: -1},
+		{format: SemanticFormat, a: "1.0.post1", b: "1.0", want: 1},
+		{format: SemanticFormat, a: "1.0.post1", b: "1.0.1", want: -1},
+		{format: SemanticFormat, a: "1.0.Final", b: "1.0", want: 0},
+		{format: SemanticFormat, a: "1.0.0+build.5", b: "1.0.0", want: 0},
+		{format: SemanticFormat, a: "2.0.0-SNAPSHOT", b: "2.0.0", want: -1},
+		// dpkg
+		{format: DpkgFormat, a: "1.2.3-1", b: "1.2.3-2", want: -1},
+		{format: DpkgFormat, a: "1:1.0-1", b: "2.0-1", want: 1},
+		{format: DpkgFormat, a: "1.0~rc1-1", b: "1.0-1", want: -1},
+		{format: DpkgFormat, a: "7.64.0-4+deb10u2", b: "7.64.0-4+deb10u1", want: 1},
+		{format: DpkgFormat, a: "2.28-10", b: "2.28-10+deb10u1", want: -1},
+		{format: DpkgFormat, a: "1.0a", b: "1.0", want: 1},
+		{format: DpkgFormat, a: "0.5.8-2.4", b: "0.5.8-2.4", want: 0},
+		// rpm
+		{format: RpmFormat, a: "1.0-1.el8", b: "1.0-2.el8", want: -1},
+		{format: RpmFormat, a: "1:1.0-1", b: "2.0-1", want: 1},
+		{format: RpmFormat, a: "1.0~rc1", b: "1.0", want: -1},
+		{format: RpmFormat, a: "1.0^git1", b: "1.0", want: 1},
+		{format: RpmFormat, a: "1.0a", b: "1.0.1", want: -1},
+		{format: RpmFormat, a: "7.61.1", b: "7.61.1-22.el8", want: 0},
+		// apk
+		{format: ApkFormat, a: "1.2.3-r0", b: "1.2.3-r1", want: -1},
+		{format: ApkFormat, a: "1.2.3_rc1-r0", b: "1.2.3-r0", want: -1},
+		{format: ApkFormat, a: "1.2.3_p1-r0", b: "1.2.3-r5", want: 1},
+		{format: ApkFormat, a: "1.2.3a-r0", b: "1.2.3-r0", want: 1},
+		{format: ApkFormat, a: "1.10.0-r0", b: "1.9.0-r0", want: 1},
+		{format: ApkFormat, a: "7.79.1-r0", b: "7.79.1-r0", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s: %s vs %s", tt.format, tt.a, tt.b), func(t *testing.T) {
+			assert.Equal(t, tt.want, Compare(tt.format, tt.a, tt.b))
+			assert.Equal(t, -tt.want, Compare(tt.format, tt.b, tt.a), "comparison should be antisymmetric")
+		})
+	}
+}
+
+func TestConstraint_Satisfied(t *testing.T) {
+	tests := []struct {
+		constraint string
+		format     Format
+		version    string
+		want       bool
+	}{
+		{constraint: "", format: SemanticFormat, version: "1.0.0", want: true},
+		{constraint: "<2.17.0", format: SemanticFormat, version: "2.14.1", want: true},
+		{constraint: "<2.17.0", format: SemanticFormat, version: "2.17.0", want: false},
+		{constraint: "< 2.17.0", format: SemanticFormat, version: "2.17.0-rc1", want: true},
+		{constraint: ">=2.0.0, <2.17.0", format: SemanticFormat, version: "1.2.17", want: false},
+		{constraint: ">=2.0.0, <2.17.0 || =1.2.17", format: SemanticFormat, version: "1.2.17", want: true},
+		{constraint: "2.17.1", format: SemanticFormat, version: "2.17.1", want: true},
+		{constraint: "!=2.17.1", format: SemanticFormat, version: "2.17.1", want: false},
+		{constraint: "<7.64.0-4+deb10u2", format: DpkgFormat, version: "7.64.0-4+deb10u1", want: true},
+		{constraint: ">=1:0", format: DpkgFormat, version: "2.0-1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %q", tt.version, tt.constraint), func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Satisfied(tt.format, tt.version))
+		})
+	}
+}
+
+func TestParseConstraint_invalid(t *testing.T) {
+	for _, expression := range []string{"<", ">= 1.0 ||", "~> 1.0 2.0"} {
+		t.Run(expression, func(t *testing.T) {
+			_, err := ParseConstraint(expression)
+			assert.Error(t, err)
+		})
+	}
+}
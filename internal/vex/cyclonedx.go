@@ -0,0 +1,109 @@
+package vex
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// cycloneDXStatuses maps CycloneDX impact analysis states to statuses.
+var cycloneDXStatuses = map[cyclonedx.ImpactAnalysisState]Status{
+	cyclonedx.IASNotAffected:          NotAffected,
+	cyclonedx.IASFalsePositive:        NotAffected,
+	cyclonedx.IASResolved:             Fixed,
+	cyclonedx.IASResolvedWithPedigree: Fixed,
+	cyclonedx.IASExploitable:          Affected,
+	cyclonedx.IASInTriage:             UnderInvestigation,
+}
+
+func decodeCycloneDX(by []byte, isXML bool) ([]Statement, error) {
+	fileFormat := cyclonedx.BOMFileFormatJSON
+	if isXML {
+		fileFormat = cyclonedx.BOMFileFormatXML
+	}
+
+	bom := cyclonedx.BOM{}
+	if err := cyclonedx.NewBOMDecoder(bytes.NewReader(by), fileFormat).Decode(&bom); err != nil {
+		return nil, err
+	}
+	if bom.Vulnerabilities == nil {
+		return nil, nil
+	}
+
+	// affected components are referenced by bom-ref, which are resolved to package URLs where possible
+	purls := make(map[string]string)
+	var index func(components *[]cyclonedx.Component)
+	index = func(components *[]cyclonedx.Component) {
+		if components == nil {
+			return
+		}
+		for _, c := range *components {
+			if c.BOMRef != "" && c.PackageURL != "" {
+				purls[c.BOMRef] = c.PackageURL
+			}
+			index(c.Components)
+		}
+	}
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		index(&[]cyclonedx.Component{*bom.Metadata.Component})
+	}
+	index(bom.Components)
+
+	var statements []Statement
+	for _, v := range *bom.Vulnerabilities {
+		if v.Analysis == nil || v.Analysis.State == "" {
+			// vulnerabilities without an analysis are findings rather than triage decisions
+			continue
+		}
+		status, ok := cycloneDXStatuses[v.Analysis.State]
+		if !ok {
+			return nil, fmt.Errorf("vulnerability %q: bad analysis state %q", v.ID, v.Analysis.State)
+		}
+
+		s := Statement{
+			Vulnerability:   v.ID,
+			Status:          status,
+			Justification:   string(v.Analysis.Justification),
+			ImpactStatement: v.Analysis.Detail,
+		}
+		if v.Analysis.State == cyclonedx.IASFalsePositive && s.Justification == "" {
+			s.Justification = string(cyclonedx.IASFalsePositive)
+		}
+		if v.Analysis.Response != nil {
+			var responses []string
+			for _, r := range *v.Analysis.Response {
+				responses = append(responses, string(r))
+			}
+			s.ActionStatement = strings.Join(responses, ", ")
+		}
+		if v.References != nil {
+			for _, r := range *v.References {
+				s.Aliases = append(s.Aliases, r.ID)
+			}
+		}
+		if v.Affects != nil {
+			for _, a := range *v.Affects {
+				s.Products = append(s.Products, cycloneDXProduct(a.Ref, purls))
+			}
+		}
+		statements = append(statements, s)
+	}
+	return statements, nil
+}
+
+// cycloneDXProduct returns the identifier of the component with the given reference, which may be a BOM-Link
+// (e.g. "urn:cdx:<serial>/<version>#<bom-ref>") to a component in another document.
+func cycloneDXProduct(ref string, purls map[string]string) string {
+	if strings.HasPrefix(ref, "urn:cdx:") {
+		if _, fragment, ok := strings.Cut(ref, "#"); ok {
+			ref = fragment
+		}
+	}
+	if purl, ok := purls[ref]; ok {
+		return purl
+	}
+	// syft uses the package URL as the bom-ref (with an additional package ID qualifier)
+	return ref
+}
@@ -0,0 +1,219 @@
+package vex
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/anchore/packageurl-go"
+	"github.com/anchore/syft/syft/source"
+)
+
+// OpenVEXContext is the OpenVEX specification version of written documents.
+const OpenVEXContext = "https://openvex.dev/ns/v0.2.0"
+
+// see https://github.com/openvex/spec/blob/main/OPENVEX-SPEC.md
+type openVEXDocument struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  string             `json:"timestamp"`
+	Version    int                `json:"version"`
+	Tooling    string             `json:"tooling,omitempty"`
+	Statements []openVEXStatement `json:"statements"`
+}
+
+type openVEXStatement struct {
+	Vulnerability   openVEXVulnerability `json:"vulnerability"`
+	Products        []openVEXProduct     `json:"products,omitempty"`
+	Subcomponents   []openVEXComponent   `json:"subcomponents,omitempty"` // prior to v0.2.0, subcomponents were listed alongside the products
+	Status          string               `json:"status"`
+	Justification   string               `json:"justification,omitempty"`
+	ImpactStatement string               `json:"impact_statement,omitempty"`
+	ActionStatement string               `json:"action_statement,omitempty"`
+}
+
+type openVEXVulnerability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// UnmarshalJSON reads a vulnerability as an object or (prior to v0.2.0) as the vulnerability name.
+func (v *openVEXVulnerability) UnmarshalJSON(by []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(by), []byte(`"`)) {
+		return json.Unmarshal(by, &v.Name)
+	}
+	type vulnerability openVEXVulnerability
+	return json.Unmarshal(by, (*vulnerability)(v))
+}
+
+type openVEXComponent struct {
+	ID          string            `json:"@id,omitempty"`
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+}
+
+// UnmarshalJSON reads a component as an object or (prior to v0.2.0) as the component identifier.
+func (c *openVEXComponent) UnmarshalJSON(by []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(by), []byte(`"`)) {
+		return json.Unmarshal(by, &c.ID)
+	}
+	type component openVEXComponent
+	return json.Unmarshal(by, (*component)(c))
+}
+
+func (c openVEXComponent) identifier() string {
+	if purl := c.Identifiers["purl"]; purl != "" {
+		return purl
+	}
+	return c.ID
+}
+
+type openVEXProduct struct {
+	openVEXComponent
+	Subcomponents []openVEXComponent `json:"subcomponents,omitempty"`
+}
+
+// UnmarshalJSON reads a product as an object or (prior to v0.2.0) as the product identifier.
+func (p *openVEXProduct) UnmarshalJSON(by []byte) error {
+	if err := p.openVEXComponent.UnmarshalJSON(by); err != nil {
+		return err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(by), []byte(`"`)) {
+		return nil
+	}
+	var subcomponents struct {
+		Subcomponents []openVEXComponent `json:"subcomponents"`
+	}
+	if err := json.Unmarshal(by, &subcomponents); err != nil {
+		return err
+	}
+	p.Subcomponents = subcomponents.Subcomponents
+	return nil
+}
+
+func decodeOpenVEX(by []byte) ([]Statement, error) {
+	var doc openVEXDocument
+	if err := json.Unmarshal(by, &doc); err != nil {
+		return nil, err
+	}
+
+	var statements []Statement
+	for i, s := range doc.Statements {
+		status, err := parseStatus(s.Status)
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i+1, err)
+		}
+		if s.Vulnerability.Name == "" {
+			return nil, fmt.Errorf("statement %d: a vulnerability is required", i+1)
+		}
+
+		statement := Statement{
+			Vulnerability:   s.Vulnerability.Name,
+			Aliases:         s.Vulnerability.Aliases,
+			Status:          status,
+			Justification:   s.Justification,
+			ImpactStatement: s.ImpactStatement,
+			ActionStatement: s.ActionStatement,
+		}
+		for _, c := range s.Subcomponents {
+			statement.Subcomponents = append(statement.Subcomponents, c.identifier())
+		}
+
+		if len(s.Products) == 0 {
+			statements = append(statements, statement)
+			continue
+		}
+
+		// products may each list their own subcomponents, so each product is considered as a separate statement
+		for _, p := range s.Products {
+			productStatement := statement
+			productStatement.Products = []string{p.identifier()}
+			for _, c := range p.Subcomponents {
+				productStatement.Subcomponents = append(productStatement.Subcomponents, c.identifier())
+			}
+			statements = append(statements, productStatement)
+		}
+	}
+	return statements, nil
+}
+
+// WriteOpenVEX writes an OpenVEX document with a statement for every finding, to be used as the starting point for
+// triage. Findings without a statement are "under_investigation", otherwise the existing decision is carried over.
+func WriteOpenVEX(w io.Writer, findings []Finding, src source.Metadata, tooling string, now time.Time) error {
+	product := ImageProduct(src)
+
+	statements := make([]openVEXStatement, 0, len(findings))
+	for _, f := range findings {
+		subcomponent := f.Package.PURL
+		if subcomponent == "" {
+			subcomponent = f.Package.Name
+		}
+
+		s := openVEXStatement{
+			Vulnerability: openVEXVulnerability{Name: f.Vulnerability.ID, Aliases: f.Vulnerability.Aliases},
+			Status:        string(UnderInvestigation),
+		}
+		if product != "" {
+			s.Products = []openVEXProduct{{
+				openVEXComponent: openVEXComponent{ID: product},
+				Subcomponents:    []openVEXComponent{{ID: subcomponent}},
+			}}
+		} else {
+			s.Products = []openVEXProduct{{openVEXComponent: openVEXComponent{ID: subcomponent}}}
+		}
+
+		if f.Statement != nil {
+			s.Status = string(f.Statement.Status)
+			s.Justification = f.Statement.Justification
+			s.ImpactStatement = f.Statement.ImpactStatement
+			s.ActionStatement = f.Statement.ActionStatement
+		}
+		statements = append(statements, s)
+	}
+
+	doc := openVEXDocument{
+		Context:    OpenVEXContext,
+		Author:     "Unknown Author",
+		Timestamp:  now.UTC().Format(time.RFC3339),
+		Version:    1,
+		Tooling:    tooling,
+		Statements: statements,
+	}
+
+	// the document ID is derived from the contents (as done by the OpenVEX tooling)
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	doc.ID = fmt.Sprintf("https://openvex.dev/docs/public/vex-%x", sha256.Sum256(content))
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
+
+// ImageProduct returns the identifier for the image the SBOM describes: an OCI package URL when the image digest is
+// known, otherwise the image reference. An empty string is returned for other sources.
+func ImageProduct(src source.Metadata) string {
+	if src.Scheme != source.ImageScheme {
+		return ""
+	}
+	img := src.ImageMetadata
+
+	for _, d := range img.RepoDigests {
+		repository, digest, ok := strings.Cut(d, "@")
+		if !ok {
+			continue
+		}
+		return packageurl.NewPackageURL(
+			packageurl.TypeOCI, "", imageName(repository), digest,
+			packageurl.Qualifiers{{Key: "repository_url", Value: repository}}, "",
+		).ToString()
+	}
+	return img.UserInput
+}
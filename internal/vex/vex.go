@@ -0,0 +1,246 @@
+/*
+Package vex reads VEX (Vulnerability Exploitability eXchange) documents, which record the outcome of triaging
+vulnerabilities for a product (e.g. that an image is not affected by a vulnerability in one of its packages), and
+applies the statements within them to vulnerability matches. Both OpenVEX and CycloneDX VEX documents are supported.
+*/
+package vex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/vulnerability"
+
+	"github.com/anchore/packageurl-go"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/source"
+)
+
+// Status is the impact of a vulnerability on a product (using the OpenVEX status vocabulary).
+type Status string
+
+const (
+	NotAffected        Status = "not_affected"
+	Affected           Status = "affected"
+	Fixed              Status = "fixed"
+	UnderInvestigation Status = "under_investigation"
+)
+
+// AllStatuses lists every status.
+var AllStatuses = []Status{NotAffected, Affected, Fixed, UnderInvestigation}
+
+func parseStatus(s string) (Status, error) {
+	for _, status := range AllStatuses {
+		if strings.EqualFold(s, string(status)) {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("bad status %q (options=%v)", s, AllStatuses)
+}
+
+// Statement is a single triage decision for a vulnerability.
+type Statement struct {
+	Vulnerability   string   // the vulnerability ID (e.g. "CVE-2021-23337" or "GHSA-35jh-r3h4-6jhm")
+	Aliases         []string // other IDs for the same vulnerability
+	Products        []string // image references or package URLs the statement applies to (every product when empty)
+	Subcomponents   []string // package URLs within the products that the statement is limited to (optional)
+	Status          Status
+	Justification   string // why the product is not affected (e.g. "vulnerable_code_not_in_execute_path")
+	ImpactStatement string // a description of why the product is (not) affected
+	ActionStatement string // the action to take for an affected product
+	Document        string // the VEX document the statement was read from
+}
+
+// Suppresses indicates if the statement resolves the vulnerability for the product (i.e. it is not affected or is fixed).
+func (s Statement) Suppresses() bool {
+	return s.Status == NotAffected || s.Status == Fixed
+}
+
+// Finding is a vulnerability match along with the statement that applies to it (if any).
+type Finding struct {
+	vulnerability.Match
+	Statement *Statement
+}
+
+// Suppressed indicates if the finding has been resolved by a VEX statement.
+func (f Finding) Suppressed() bool {
+	return f.Statement != nil && f.Statement.Suppresses()
+}
+
+// Read reads all statements from the given OpenVEX or CycloneDX VEX (JSON or XML) document.
+func Read(path string) ([]Statement, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read VEX document: %w", err)
+	}
+
+	statements, err := decode(by)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read VEX document %q: %w", path, err)
+	}
+	for i := range statements {
+		statements[i].Document = path
+	}
+	return statements, nil
+}
+
+func decode(by []byte) ([]Statement, error) {
+	trimmed := bytes.TrimSpace(by)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return decodeCycloneDX(trimmed, true)
+	}
+
+	var header struct {
+		Context   string `json:"@context"`
+		BOMFormat string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(trimmed, &header); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.Contains(header.Context, "openvex"):
+		return decodeOpenVEX(trimmed)
+	case header.BOMFormat == "CycloneDX":
+		return decodeCycloneDX(trimmed, false)
+	}
+	return nil, fmt.Errorf("not an OpenVEX or CycloneDX document")
+}
+
+// Apply returns a finding for every match, where the last statement that applies to a match takes precedence (so
+// later documents may amend the decisions of earlier documents).
+func Apply(statements []Statement, src source.Metadata, matches []vulnerability.Match) []Finding {
+	findings := make([]Finding, 0, len(matches))
+	for _, m := range matches {
+		f := Finding{Match: m}
+		for i := range statements {
+			if statements[i].applies(src, m) {
+				f.Statement = &statements[i]
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func (s Statement) applies(src source.Metadata, m vulnerability.Match) bool {
+	if !s.describes(m.Vulnerability) {
+		return false
+	}
+
+	productMatched := len(s.Products) == 0
+	for _, product := range s.Products {
+		if matchesPackage(product, m.Package) {
+			// the package itself is the product
+			return true
+		}
+		if matchesImage(product, src) {
+			productMatched = true
+		}
+	}
+	if !productMatched {
+		return false
+	}
+
+	if len(s.Subcomponents) == 0 {
+		return true
+	}
+	for _, subcomponent := range s.Subcomponents {
+		if matchesPackage(subcomponent, m.Package) {
+			return true
+		}
+	}
+	return false
+}
+
+// describes indicates if the statement is about the given vulnerability (considering the aliases of both).
+func (s Statement) describes(v vulnerability.Vulnerability) bool {
+	for _, a := range append([]string{s.Vulnerability}, s.Aliases...) {
+		for _, b := range append([]string{v.ID}, v.Aliases...) {
+			if a != "" && strings.EqualFold(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesPackage indicates if the identifier (a package URL or name) refers to the given package. Package URL
+// qualifiers are not considered, and a package URL without a version refers to every version of the package.
+func matchesPackage(id string, p pkg.Package) bool {
+	if !strings.HasPrefix(id, "pkg:") {
+		return id == p.Name
+	}
+
+	want, err := packageurl.FromString(id)
+	if err != nil || p.PURL == "" {
+		return false
+	}
+	got, err := packageurl.FromString(p.PURL)
+	if err != nil {
+		return false
+	}
+
+	return want.Type == got.Type &&
+		strings.EqualFold(want.Namespace, got.Namespace) &&
+		strings.EqualFold(want.Name, got.Name) &&
+		(want.Version == "" || want.Version == got.Version)
+}
+
+// matchesImage indicates if the identifier (an OCI package URL or image reference) refers to the given image.
+func matchesImage(id string, src source.Metadata) bool {
+	if src.Scheme != source.ImageScheme {
+		return false
+	}
+	img := src.ImageMetadata
+
+	digests := []string{img.ID, img.ManifestDigest}
+	for _, d := range img.RepoDigests {
+		if _, digest, ok := strings.Cut(d, "@"); ok {
+			digests = append(digests, digest)
+		}
+	}
+
+	if strings.HasPrefix(id, "pkg:oci/") {
+		purl, err := packageurl.FromString(id)
+		if err != nil {
+			return false
+		}
+		if !contains(imageNames(img), strings.ToLower(purl.Name)) {
+			return false
+		}
+		return purl.Version == "" || contains(digests, purl.Version)
+	}
+
+	references := append([]string{img.UserInput}, img.Tags...)
+	references = append(references, img.RepoDigests...)
+	return contains(references, id) || contains(digests, id)
+}
+
+// imageNames returns the names of the image repositories (without the registry, namespace, tag, or digest) the image is known by.
+func imageNames(img source.ImageMetadata) (names []string) {
+	for _, ref := range append(append([]string{img.UserInput}, img.Tags...), img.RepoDigests...) {
+		names = append(names, imageName(ref))
+	}
+	return names
+}
+
+func imageName(ref string) string {
+	ref = strings.SplitN(ref, "@", 2)[0]
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	return strings.ToLower(strings.SplitN(ref, ":", 2)[0])
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v != "" && v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package vex
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/sbom-cli-plugin/internal/vulnerability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/source"
+)
+
+const (
+	testOpenVEX = `{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "@id": "https://example.com/vex/1",
+  "author": "security@example.com",
+  "timestamp": "2023-01-01T00:00:00Z",
+  "version": 1,
+  "statements": [
+    {
+      "vulnerability": {"name": "CVE-2021-23337"},
+      "products": [
+        {
+          "@id": "pkg:oci/app@sha256:0123456789abcdef?repository_url=example.com/app",
+          "subcomponents": [{"@id": "pkg:npm/lodash"}]
+        }
+      ],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_in_execute_path",
+      "impact_statement": "the template function is never called"
+    },
+    {
+      "vulnerability": {"name": "CVE-2022-0001"},
+      "products": [{"@id": "pkg:npm/minimist@1.2.5"}],
+      "status": "fixed"
+    }
+  ]
+}`
+	// statements prior to OpenVEX v0.2.0 use plain identifiers
+	testLegacyOpenVEX = `{
+  "@context": "https://openvex.dev/ns",
+  "@id": "https://example.com/vex/2",
+  "author": "security@example.com",
+  "timestamp": "2023-01-01T00:00:00Z",
+  "version": 1,
+  "statements": [
+    {
+      "vulnerability": "CVE-2022-0002",
+      "products": ["example.com/app:1.0"],
+      "subcomponents": ["pkg:deb/debian/zlib1g"],
+      "status": "affected",
+      "action_statement": "upgrade zlib"
+    }
+  ]
+}`
+	testCycloneDXVEX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "version": 1,
+  "components": [{"bom-ref": "lodash", "type": "library", "name": "lodash", "purl": "pkg:npm/lodash@4.17.20"}],
+  "vulnerabilities": [
+    {
+      "id": "GHSA-35jh-r3h4-6jhm",
+      "references": [{"id": "CVE-2021-23337", "source": {"name": "NVD"}}],
+      "analysis": {"state": "false_positive", "detail": "not a real finding", "response": ["will_not_fix"]},
+      "affects": [{"ref": "urn:cdx:3e671687-395b-41f5-a30f-a58921a69b79/1#lodash"}]
+    },
+    {
+      "id": "CVE-2022-0003",
+      "affects": [{"ref": "lodash"}]
+    }
+  ]
+}`
+)
+
+func writeDocument(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vex.json")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     []Statement
+	}{
+		{
+			name:     "OpenVEX",
+			document: testOpenVEX,
+			want: []Statement{
+				{
+					Vulnerability:   "CVE-2021-23337",
+					Products:        []string{"pkg:oci/app@sha256:0123456789abcdef?repository_url=example.com/app"},
+					Subcomponents:   []string{"pkg:npm/lodash"},
+					Status:          NotAffected,
+					Justification:   "vulnerable_code_not_in_execute_path",
+					ImpactStatement: "the template function is never called",
+				},
+				{
+					Vulnerability: "CVE-2022-0001",
+					Products:      []string{"pkg:npm/minimist@1.2.5"},
+					Status:        Fixed,
+				},
+			},
+		},
+		{
+			name:     "legacy OpenVEX",
+			document: testLegacyOpenVEX,
+			want: []Statement{
+				{
+					Vulnerability:   "CVE-2022-0002",
+					Products:        []string{"example.com/app:1.0"},
+					Subcomponents:   []string{"pkg:deb/debian/zlib1g"},
+					Status:          Affected,
+					ActionStatement: "upgrade zlib",
+				},
+			},
+		},
+		{
+			name:     "CycloneDX",
+			document: testCycloneDXVEX,
+			want: []Statement{
+				{
+					Vulnerability:   "GHSA-35jh-r3h4-6jhm",
+					Aliases:         []string{"CVE-2021-23337"},
+					Products:        []string{"pkg:npm/lodash@4.17.20"},
+					Status:          NotAffected,
+					Justification:   "false_positive",
+					ImpactStatement: "not a real finding",
+					ActionStatement: "will_not_fix",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeDocument(t, tt.document)
+			for i := range tt.want {
+				tt.want[i].Document = path
+			}
+
+			got, err := Read(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRead_invalid(t *testing.T) {
+	for name, contents := range map[string]string{
+		"not VEX":    `{"name": "something else"}`,
+		"bad status": `{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [{"vulnerability": {"name": "CVE-1"}, "status": "ignored"}]}`,
+		"bad json":   `{`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(writeDocument(t, contents))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func testImage() source.Metadata {
+	return source.Metadata{
+		Scheme: source.ImageScheme,
+		ImageMetadata: source.ImageMetadata{
+			UserInput:   "example.com/app:1.0",
+			Tags:        []string{"example.com/app:1.0"},
+			RepoDigests: []string{"example.com/app@sha256:0123456789abcdef"},
+		},
+	}
+}
+
+func TestApply(t *testing.T) {
+	lodash := pkg.Package{Name: "lodash", Version: "4.17.20", Type: pkg.NpmPkg, PURL: "pkg:npm/lodash@4.17.20"}
+	minimist := pkg.Package{Name: "minimist", Version: "1.2.5", Type: pkg.NpmPkg, PURL: "pkg:npm/minimist@1.2.5"}
+	zlib := pkg.Package{Name: "zlib1g", Version: "1:1.2.11.dfsg-2", Type: pkg.DebPkg, PURL: "pkg:deb/debian/zlib1g@1:1.2.11.dfsg-2?arch=amd64"}
+
+	matches := []vulnerability.Match{
+		// matched by alias, for a subcomponent of the image
+		{Vulnerability: vulnerability.Vulnerability{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}}, Package: lodash},
+		// the package is the product
+		{Vulnerability: vulnerability.Vulnerability{ID: "CVE-2022-0001"}, Package: minimist},
+		// a later statement takes precedence
+		{Vulnerability: vulnerability.Vulnerability{ID: "CVE-2022-0002"}, Package: zlib},
+		// the statement is for another package
+		{Vulnerability: vulnerability.Vulnerability{ID: "CVE-2022-0001"}, Package: lodash},
+	}
+
+	var statements []Statement
+	for _, document := range []string{testOpenVEX, testLegacyOpenVEX} {
+		s, err := Read(writeDocument(t, document))
+		require.NoError(t, err)
+		statements = append(statements, s...)
+	}
+	statements = append(statements, Statement{Vulnerability: "CVE-2022-0002", Products: []string{"pkg:oci/app"}, Status: NotAffected})
+
+	findings := Apply(statements, testImage(), matches)
+	require.Len(t, findings, 4)
+
+	var got []string
+	for _, f := range findings {
+		status := "none"
+		if f.Statement != nil {
+			status = string(f.Statement.Status)
+		}
+		got = append(got, status)
+	}
+	assert.Equal(t, []string{"not_affected", "fixed", "not_affected", "none"}, got)
+
+	assert.True(t, findings[0].Suppressed())
+	assert.False(t, findings[3].Suppressed())
+
+	// statements for another image do not apply
+	other := testImage()
+	other.ImageMetadata = source.ImageMetadata{UserInput: "example.com/other:1.0"}
+	assert.Nil(t, Apply(statements[:1], other, matches[:1])[0].Statement)
+}
+
+func TestWriteOpenVEX(t *testing.T) {
+	lodash := pkg.Package{Name: "lodash", Version: "4.17.20", Type: pkg.NpmPkg, PURL: "pkg:npm/lodash@4.17.20"}
+	findings := []Finding{
+		{Match: vulnerability.Match{Vulnerability: vulnerability.Vulnerability{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}}, Package: lodash}},
+		{
+			Match:     vulnerability.Match{Vulnerability: vulnerability.Vulnerability{ID: "CVE-2022-0001"}, Package: lodash},
+			Statement: &Statement{Status: NotAffected, Justification: "component_not_present"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOpenVEX(&buf, findings, testImage(), "docker-sbom 1.0.0", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Contains(t, buf.String(), `"@id": "https://openvex.dev/docs/public/vex-`)
+	assert.Contains(t, buf.String(), `"timestamp": "2023-01-01T00:00:00Z"`)
+
+	// the document should be readable as the input for the next run
+	statements, err := decode(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []Statement{
+		{
+			Vulnerability: "GHSA-35jh-r3h4-6jhm",
+			Aliases:       []string{"CVE-2021-23337"},
+			Products:      []string{"pkg:oci/app@sha256:0123456789abcdef?repository_url=example.com/app"},
+			Subcomponents: []string{"pkg:npm/lodash@4.17.20"},
+			Status:        UnderInvestigation,
+		},
+		{
+			Vulnerability: "CVE-2022-0001",
+			Products:      []string{"pkg:oci/app@sha256:0123456789abcdef?repository_url=example.com/app"},
+			Subcomponents: []string{"pkg:npm/lodash@4.17.20"},
+			Status:        NotAffected,
+			Justification: "component_not_present",
+		},
+	}, statements)
+
+	findings = Apply(statements, testImage(), []vulnerability.Match{findings[1].Match})
+	require.NotNil(t, findings[0].Statement)
+	assert.True(t, findings[0].Suppressed())
+}
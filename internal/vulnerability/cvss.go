@@ -0,0 +1,88 @@
+package vulnerability
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// the CVSS v3 base metric weights (see https://www.first.org/cvss/v3.1/specification-document#7-4-Metric-Values)
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"S":  {"U": 0, "C": 0},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore calculates the base score of a CVSS v3.0 or v3.1 vector (e.g. "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H").
+func cvss3BaseScore(vector string) (float64, error) {
+	fields := strings.Split(vector, "/")
+	if len(fields) == 0 || (fields[0] != "CVSS:3.0" && fields[0] != "CVSS:3.1") {
+		return 0, fmt.Errorf("not a CVSS v3 vector: %q", vector)
+	}
+
+	values := make(map[string]string)
+	for _, field := range fields[1:] {
+		metric, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return 0, fmt.Errorf("bad CVSS v3 metric %q", field)
+		}
+		// temporal and environmental metrics do not contribute to the base score
+		if _, ok := cvss3Weights[metric]; ok {
+			values[metric] = value
+		}
+	}
+
+	weights := make(map[string]float64)
+	for metric, options := range cvss3Weights {
+		weight, ok := options[values[metric]]
+		if !ok {
+			return 0, fmt.Errorf("bad or missing CVSS v3 base metric %q in %q", metric, vector)
+		}
+		weights[metric] = weight
+	}
+
+	changed := values["S"] == "C"
+	if changed {
+		// privileges are more significant when the scope is changed
+		switch values["PR"] {
+		case "L":
+			weights["PR"] = 0.68
+		case "H":
+			weights["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-weights["C"])*(1-weights["I"])*(1-weights["A"])
+
+	var impact float64
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * weights["AV"] * weights["AC"] * weights["PR"] * weights["UI"]
+
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp returns the smallest number with one decimal place that is equal to or higher than the input, avoiding
+// floating point inaccuracies as described in the CVSS v3.1 specification (appendix A).
+func roundUp(f float64) float64 {
+	i := int64(math.Round(f * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000.0
+	}
+	return float64(i/10000+1) / 10.0
+}
@@ -0,0 +1,166 @@
+package vulnerability
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+
+	"github.com/anchore/packageurl-go"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+)
+
+// osvKey identifies a package within the OSV data (an ecosystem and the package name within that ecosystem).
+type osvKey struct {
+	ecosystem string
+	name      string
+}
+
+func newOSVKey(ecosystem, name string) osvKey {
+	ecosystem = strings.ToLower(ecosystem)
+	if ecosystem == "pypi" {
+		name = normalizePythonName(name)
+	}
+	return osvKey{ecosystem: ecosystem, name: name}
+}
+
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonName normalizes a python package name as described by PEP 503.
+func normalizePythonName(name string) string {
+	return pythonNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// candidate is a package as it may appear within the OSV data, along with how to compare its version with the ranges of a record.
+type candidate struct {
+	key     osvKey
+	version string
+	format  versions.Format
+}
+
+// candidates returns the OSV packages that the given package may be recorded as, where distro packages are recorded
+// per release of the distro (e.g. "Debian:11") but also may be recorded for the distro as a whole (e.g. "Debian").
+func candidates(p pkg.Package, distro *linux.Release) []candidate {
+	format := versions.FormatForType(p.Type)
+	version := p.Version
+
+	var keys []osvKey
+	switch p.Type {
+	case pkg.DebPkg:
+		if distro == nil {
+			return nil
+		}
+		var name string
+		name, version = debSource(p)
+		switch distro.ID {
+		case "debian":
+			keys = append(keys, newOSVKey("Debian:"+distro.VersionID, name), newOSVKey("Debian", name))
+		case "ubuntu":
+			keys = append(keys, newOSVKey("Ubuntu:"+distro.VersionID, name), newOSVKey("Ubuntu:"+distro.VersionID+":LTS", name), newOSVKey("Ubuntu", name))
+		}
+	case pkg.ApkPkg:
+		if distro == nil || distro.ID != "alpine" {
+			return nil
+		}
+		keys = append(keys, newOSVKey("Alpine:v"+majorMinor(distro.VersionID), apkOrigin(p)), newOSVKey("Alpine", apkOrigin(p)))
+	case pkg.RpmPkg:
+		if distro == nil {
+			return nil
+		}
+		var ecosystem string
+		switch distro.ID {
+		case "rocky":
+			ecosystem = "Rocky Linux"
+		case "almalinux":
+			ecosystem = "AlmaLinux"
+		default:
+			return nil
+		}
+		keys = append(keys, newOSVKey(ecosystem+":"+majorVersion(distro.VersionID), p.Name), newOSVKey(ecosystem, p.Name))
+	case pkg.JavaPkg, pkg.JenkinsPluginPkg:
+		purl, err := packageurl.FromString(p.PURL)
+		if err != nil || purl.Namespace == "" {
+			return nil
+		}
+		keys = append(keys, newOSVKey("Maven", purl.Namespace+":"+purl.Name))
+	case pkg.GoModulePkg:
+		// Go versions are recorded without the "v" prefix
+		version = strings.TrimPrefix(version, "v")
+		keys = append(keys, newOSVKey("Go", p.Name))
+	default:
+		ecosystem, ok := languageEcosystems[p.Type]
+		if !ok {
+			return nil
+		}
+		keys = append(keys, newOSVKey(ecosystem, p.Name))
+	}
+
+	result := make([]candidate, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, candidate{key: k, version: version, format: format})
+	}
+	return result
+}
+
+// languageEcosystems maps the language package types to OSV ecosystems where the package name is used as-is.
+var languageEcosystems = map[pkg.Type]string{
+	pkg.NpmPkg:         "npm",
+	pkg.PythonPkg:      "PyPI",
+	pkg.GemPkg:         "RubyGems",
+	pkg.RustPkg:        "crates.io",
+	pkg.PhpComposerPkg: "Packagist",
+	pkg.DotnetPkg:      "NuGet",
+	pkg.DartPubPkg:     "Pub",
+}
+
+// debSource returns the source package name and version of a Debian package (vulnerabilities are recorded against
+// source packages).
+func debSource(p pkg.Package) (string, string) {
+	var source string
+	switch m := p.Metadata.(type) {
+	case pkg.DpkgMetadata:
+		source = m.Source
+	case *pkg.DpkgMetadata:
+		source = m.Source
+	}
+
+	// the source includes the source version when it differs from the binary version (e.g. "glibc (2.31-13)")
+	fields := strings.SplitN(strings.TrimSpace(source), " ", 2)
+	if fields[0] == "" {
+		return p.Name, p.Version
+	}
+	if len(fields) == 2 {
+		if v := strings.Trim(strings.TrimSpace(fields[1]), "()"); v != "" {
+			return fields[0], v
+		}
+	}
+	return fields[0], p.Version
+}
+
+// apkOrigin returns the origin (source) package name of an Alpine package (vulnerabilities are recorded against origin packages).
+func apkOrigin(p pkg.Package) string {
+	switch m := p.Metadata.(type) {
+	case pkg.ApkMetadata:
+		if m.OriginPackage != "" {
+			return m.OriginPackage
+		}
+	case *pkg.ApkMetadata:
+		if m.OriginPackage != "" {
+			return m.OriginPackage
+		}
+	}
+	return p.Name
+}
+
+func majorMinor(version string) string {
+	fields := strings.SplitN(version, ".", 3)
+	if len(fields) < 2 {
+		return version
+	}
+	return fields[0] + "." + fields[1]
+}
+
+func majorVersion(version string) string {
+	return strings.SplitN(version, ".", 2)[0]
+}
@@ -0,0 +1,128 @@
+package vulnerability
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/log"
+)
+
+// osvRecord is a vulnerability record in the OSV schema (see https://ossf.github.io/osv-schema/). Only the fields
+// needed for matching and reporting are read.
+type osvRecord struct {
+	ID               string          `json:"id"`
+	Aliases          []string        `json:"aliases"`
+	Summary          string          `json:"summary"`
+	Details          string          `json:"details"`
+	Withdrawn        string          `json:"withdrawn"`
+	Severity         []osvSeverity   `json:"severity"`
+	Affected         []osvAffected   `json:"affected"`
+	References       []osvReference  `json:"references"`
+	DatabaseSpecific json.RawMessage `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"` // e.g. "CVSS_V3" or "CVSS_V2"
+	Score string `json:"score"`
+}
+
+type osvAffected struct {
+	Package           osvPackage      `json:"package"`
+	Severity          []osvSeverity   `json:"severity"`
+	Ranges            []osvRange      `json:"ranges"`
+	Versions          []string        `json:"versions"`
+	EcosystemSpecific json.RawMessage `json:"ecosystem_specific"`
+	DatabaseSpecific  json.RawMessage `json:"database_specific"`
+}
+
+type osvPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	PURL      string `json:"purl"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"` // "SEMVER", "ECOSYSTEM", or "GIT"
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+type osvReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// readRecords reads every OSV record within the given directory, which may contain individual JSON records and zip
+// archives of records (as published in the OSV data dumps), invoking the given function for each record.
+func readRecords(dir string, fn func(osvRecord)) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			decodeRecord(path, f, fn)
+		case ".zip":
+			if err := readArchive(path, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func readArchive(path string, fn func(osvRecord)) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("unable to open vulnerability archive %q: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(f.Name), ".json") {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("unable to read %q from vulnerability archive %q: %w", f.Name, path, err)
+		}
+		decodeRecord(path+":"+f.Name, r, fn)
+		_ = r.Close()
+	}
+	return nil
+}
+
+func decodeRecord(name string, r io.Reader, fn func(osvRecord)) {
+	var record osvRecord
+	if err := json.NewDecoder(r).Decode(&record); err != nil || record.ID == "" {
+		// dumps may contain other JSON files (e.g. metadata) alongside the records
+		log.Debugf("skipping %q: not an OSV record (%v)", name, err)
+		return
+	}
+	if record.Withdrawn != "" {
+		return
+	}
+	fn(record)
+}
@@ -0,0 +1,75 @@
+package vulnerability
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the qualitative severity of a vulnerability.
+type Severity string
+
+const (
+	UnknownSeverity  Severity = "unknown"
+	LowSeverity      Severity = "low"
+	MediumSeverity   Severity = "medium"
+	HighSeverity     Severity = "high"
+	CriticalSeverity Severity = "critical"
+)
+
+// AllSeverities lists every severity from the least to the most severe.
+var AllSeverities = []Severity{UnknownSeverity, LowSeverity, MediumSeverity, HighSeverity, CriticalSeverity}
+
+// ParseSeverity returns the severity with the given name (case-insensitive).
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range AllSeverities {
+		if strings.EqualFold(s, string(severity)) {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("bad severity %q (options=%v)", s, AllSeverities)
+}
+
+// AtLeast indicates if the severity is the same as or more severe than the given severity.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() >= other.rank()
+}
+
+func (s Severity) rank() int {
+	for i, severity := range AllSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return 0
+}
+
+// severityForScore returns the severity for a CVSS score (see https://www.first.org/cvss/v3.1/specification-document#Qualitative-Severity-Rating-Scale),
+// where a score of 0 ("none") is considered low.
+func severityForScore(score float64) Severity {
+	switch {
+	case score >= 9.0:
+		return CriticalSeverity
+	case score >= 7.0:
+		return HighSeverity
+	case score >= 4.0:
+		return MediumSeverity
+	default:
+		return LowSeverity
+	}
+}
+
+// severityForLabel returns the severity for a label used by a vulnerability data source, such as the GitHub advisory
+// database ("MODERATE") or distro security trackers ("important", "negligible").
+func severityForLabel(label string) Severity {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "critical":
+		return CriticalSeverity
+	case "high", "important":
+		return HighSeverity
+	case "medium", "moderate":
+		return MediumSeverity
+	case "low", "negligible", "minor":
+		return LowSeverity
+	}
+	return UnknownSeverity
+}
@@ -0,0 +1,301 @@
+/*
+Package vulnerability matches the packages within an SBOM against a local copy of the OSV vulnerability data (see
+https://osv.dev), such that images can be checked for known vulnerabilities without access to external services.
+*/
+package vulnerability
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docker/sbom-cli-plugin/internal/table"
+	"github.com/docker/sbom-cli-plugin/internal/versions"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// SourceName is the name of the vulnerability data source.
+const SourceName = "OSV"
+
+// Vulnerability is a known vulnerability as described by an OSV record.
+type Vulnerability struct {
+	ID         string
+	Aliases    []string // other identifiers for the same vulnerability (e.g. CVE IDs)
+	Summary    string
+	Severity   Severity
+	Score      float64 // the CVSS v3 base score (0 when no CVSS v3 vector is available)
+	Vector     string  // the CVSS vector the score was calculated from
+	References []string
+}
+
+// URL returns the location of the vulnerability within the OSV database.
+func (v Vulnerability) URL() string {
+	return "https://osv.dev/vulnerability/" + v.ID
+}
+
+// Match is a package that is affected by a vulnerability.
+type Match struct {
+	Vulnerability Vulnerability
+	Package       pkg.Package
+	FixedIn       []string // the versions that the vulnerability is fixed in (if known)
+}
+
+// Database is a directory of OSV records, either as individual JSON files or as the zip archives published in the OSV
+// data dumps (e.g. "Debian/all.zip").
+type Database struct {
+	dir string
+}
+
+// Open returns the database within the given directory.
+func Open(dir string) (*Database, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open vulnerability database: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("unable to open vulnerability database: %q is not a directory", dir)
+	}
+	return &Database{dir: dir}, nil
+}
+
+// Match returns all vulnerabilities within the database that affect packages in the given SBOM. Packages are matched
+// by OSV ecosystem, package name, and version (using the version ordering of the ecosystem).
+func (d Database) Match(s sbom.SBOM) ([]Match, error) {
+	if s.Artifacts.PackageCatalog == nil {
+		return nil, nil
+	}
+
+	type target struct {
+		p pkg.Package
+		candidate
+	}
+
+	targets := make(map[osvKey][]target)
+	for _, p := range s.Artifacts.PackageCatalog.Sorted() {
+		for _, c := range candidates(p, s.Artifacts.LinuxDistribution) {
+			targets[c.key] = append(targets[c.key], target{p: p, candidate: c})
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	var matches []Match
+	// records may list the same package more than once (e.g. with separate ranges), but each record should only be reported once per package
+	seen := make(map[string]struct{})
+	err := readRecords(d.dir, func(record osvRecord) {
+		for _, affected := range record.Affected {
+			for _, t := range targets[newOSVKey(affected.Package.Ecosystem, affected.Package.Name)] {
+				matchKey := record.ID + "/" + string(t.p.ID())
+				if _, ok := seen[matchKey]; ok {
+					continue
+				}
+
+				fixedIn, ok := affects(affected, t.candidate)
+				if !ok {
+					continue
+				}
+				seen[matchKey] = struct{}{}
+				matches = append(matches, Match{
+					Vulnerability: newVulnerability(record, affected),
+					Package:       t.p,
+					FixedIn:       fixedIn,
+				})
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vulnerability database: %w", err)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.Package.Name != b.Package.Name:
+			return a.Package.Name < b.Package.Name
+		case a.Package.Version != b.Package.Version:
+			return versions.Compare(versions.FormatForType(a.Package.Type), a.Package.Version, b.Package.Version) < 0
+		case a.Vulnerability.Severity != b.Vulnerability.Severity:
+			return a.Vulnerability.Severity.rank() > b.Vulnerability.Severity.rank()
+		}
+		return a.Vulnerability.ID < b.Vulnerability.ID
+	})
+
+	return matches, nil
+}
+
+// affects indicates if the package version is affected according to the ranges and versions of the record, returning
+// the versions that the vulnerability is fixed in.
+func affects(affected osvAffected, c candidate) ([]string, bool) {
+	for _, v := range affected.Versions {
+		if v == c.version {
+			return fixedVersions(affected), true
+		}
+	}
+
+	for _, r := range affected.Ranges {
+		var format versions.Format
+		switch r.Type {
+		case "SEMVER":
+			format = versions.SemanticFormat
+		case "ECOSYSTEM":
+			format = c.format
+		default:
+			// git ranges are commit hashes, which cannot be compared with package versions
+			continue
+		}
+		if inRange(r.Events, format, c.version) {
+			return fixedVersions(affected), true
+		}
+	}
+	return nil, false
+}
+
+// inRange evaluates the events of an OSV range against a version, as described by https://ossf.github.io/osv-schema/#evaluation.
+func inRange(events []osvEvent, format versions.Format, version string) bool {
+	compare := func(v string) int {
+		if v == "0" {
+			// "0" is before every version
+			return 1
+		}
+		return versions.Compare(format, version, v)
+	}
+
+	affected := false
+	for _, e := range sortEvents(events, format) {
+		switch {
+		case e.Introduced != "":
+			if compare(e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if compare(e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if compare(e.LastAffected) > 0 {
+				affected = false
+			}
+		case e.Limit != "":
+			if compare(e.Limit) >= 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func sortEvents(events []osvEvent, format versions.Format) []osvEvent {
+	value := func(e osvEvent) string {
+		switch {
+		case e.Introduced != "":
+			return e.Introduced
+		case e.Fixed != "":
+			return e.Fixed
+		case e.LastAffected != "":
+			return e.LastAffected
+		}
+		return e.Limit
+	}
+
+	sorted := append([]osvEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := value(sorted[i]), value(sorted[j])
+		switch {
+		case a == b:
+			return false
+		case a == "0":
+			return true
+		case b == "0":
+			return false
+		}
+		return versions.Compare(format, a, b) < 0
+	})
+	return sorted
+}
+
+func fixedVersions(affected osvAffected) (result []string) {
+	for _, r := range affected.Ranges {
+		for _, e := range r.Events {
+			if e.Fixed != "" && r.Type != "GIT" {
+				result = append(result, e.Fixed)
+			}
+		}
+	}
+	return result
+}
+
+func newVulnerability(record osvRecord, affected osvAffected) Vulnerability {
+	v := Vulnerability{
+		ID:       record.ID,
+		Aliases:  record.Aliases,
+		Summary:  record.Summary,
+		Severity: UnknownSeverity,
+	}
+	if v.Summary == "" {
+		// not every source provides a summary, so fall back to the first line of the details
+		v.Summary = strings.TrimSpace(strings.SplitN(record.Details, "\n", 2)[0])
+	}
+
+	for _, r := range record.References {
+		v.References = append(v.References, r.URL)
+	}
+
+	// a severity specific to the affected package takes precedence over the severity of the vulnerability
+	for _, severities := range [][]osvSeverity{affected.Severity, record.Severity} {
+		for _, s := range severities {
+			if s.Type != "CVSS_V3" {
+				continue
+			}
+			score, err := cvss3BaseScore(s.Score)
+			if err != nil {
+				continue
+			}
+			v.Score = score
+			v.Vector = s.Score
+			v.Severity = severityForScore(score)
+			return v
+		}
+	}
+
+	// otherwise use the severity label of the source database (if any)
+	for _, raw := range []json.RawMessage{affected.EcosystemSpecific, affected.DatabaseSpecific, record.DatabaseSpecific} {
+		var specific struct {
+			Severity string `json:"severity"`
+		}
+		if len(raw) == 0 || json.Unmarshal(raw, &specific) != nil {
+			continue
+		}
+		if severity := severityForLabel(specific.Severity); severity != UnknownSeverity {
+			v.Severity = severity
+			return v
+		}
+	}
+
+	for _, s := range append(affected.Severity, record.Severity...) {
+		// e.g. the "Ubuntu" severity type, which is a label rather than a CVSS vector
+		if severity := severityForLabel(s.Score); severity != UnknownSeverity {
+			v.Severity = severity
+			return v
+		}
+	}
+	return v
+}
+
+// WriteMatches writes a human-readable report of the given matches.
+func WriteMatches(w io.Writer, title string, matches []Match) error {
+	rows := make([][]string, 0, len(matches))
+	for _, m := range matches {
+		rows = append(rows, []string{m.Package.Name, m.Package.Version, strings.Join(m.FixedIn, ", "), string(m.Package.Type), m.Vulnerability.ID, string(m.Vulnerability.Severity)})
+	}
+
+	if _, err := fmt.Fprintf(w, "%s (%d):\n", title, len(matches)); err != nil {
+		return err
+	}
+	return table.Write(w, []string{"Name", "Installed", "Fixed-In", "Type", "Vulnerability", "Severity"}, rows)
+}
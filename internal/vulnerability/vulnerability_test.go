@@ -0,0 +1,176 @@
+package vulnerability
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+const (
+	// a GitHub advisory with a CVSS vector
+	lodashRecord = `{
+  "id": "GHSA-35jh-r3h4-6jhm",
+  "aliases": ["CVE-2021-23337"],
+  "summary": "Command Injection in lodash",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}],
+  "affected": [{
+    "package": {"ecosystem": "npm", "name": "lodash"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+  }],
+  "references": [{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}]
+}`
+	// a distro advisory recorded against the source package, without a severity
+	opensslRecord = `{
+  "id": "DSA-5103-1",
+  "details": "Tavis Ormandy discovered that the BN_mod_sqrt() function could loop forever.\nMore details.",
+  "affected": [{
+    "package": {"ecosystem": "Debian:11", "name": "openssl"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.1.1n-0+deb11u1"}]}]
+  }]
+}`
+	// a python advisory with a severity label and a last affected version
+	requestsRecord = `{
+  "id": "PYSEC-0000-1",
+  "affected": [{
+    "package": {"ecosystem": "PyPI", "name": "Requests"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.0.0"}, {"last_affected": "2.19.1"}]}]
+  }],
+  "database_specific": {"severity": "MODERATE"}
+}`
+	withdrawnRecord = `{
+  "id": "GHSA-withdrawn",
+  "withdrawn": "2022-01-01T00:00:00Z",
+  "affected": [{"package": {"ecosystem": "npm", "name": "lodash"}, "versions": ["4.17.20"]}]
+}`
+)
+
+func writeDatabase(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "npm"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "npm", "GHSA-35jh-r3h4-6jhm.json"), []byte(lodashRecord), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "npm", "GHSA-withdrawn.json"), []byte(withdrawnRecord), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "PyPI.json"), []byte(requestsRecord), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(`{"generated": "2022-06-01"}`), 0600))
+
+	// the debian records are within an archive, as with the OSV data dumps
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	f, err := archive.Create("DSA-5103-1.json")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(opensslRecord))
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Debian"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Debian", "all.zip"), buf.Bytes(), 0600))
+
+	return dir
+}
+
+func newSBOM(distro *linux.Release, pkgs ...pkg.Package) sbom.SBOM {
+	for i := range pkgs {
+		pkgs[i].SetID()
+	}
+	return sbom.SBOM{Artifacts: sbom.Artifacts{PackageCatalog: pkg.NewCatalog(pkgs...), LinuxDistribution: distro}}
+}
+
+func TestDatabase_Match(t *testing.T) {
+	db, err := Open(writeDatabase(t))
+	require.NoError(t, err)
+
+	s := newSBOM(&linux.Release{ID: "debian", VersionID: "11"},
+		pkg.Package{Name: "lodash", Version: "4.17.20", Type: pkg.NpmPkg},
+		pkg.Package{Name: "lodash", Version: "4.17.21", Type: pkg.NpmPkg},
+		pkg.Package{Name: "libssl1.1", Version: "1.1.1k-1+deb11u2", Type: pkg.DebPkg, Metadata: pkg.DpkgMetadata{Source: "openssl"}},
+		pkg.Package{Name: "openssl", Version: "1.1.1n-0+deb11u1", Type: pkg.DebPkg},
+		pkg.Package{Name: "requests", Version: "2.19.1", Type: pkg.PythonPkg},
+		pkg.Package{Name: "requests", Version: "2.20.0", Type: pkg.PythonPkg},
+	)
+
+	matches, err := db.Match(s)
+	require.NoError(t, err)
+
+	var got []string
+	for _, m := range matches {
+		got = append(got, m.Package.Name+" "+m.Package.Version+" "+m.Vulnerability.ID+" "+string(m.Vulnerability.Severity))
+	}
+	assert.Equal(t, []string{
+		"libssl1.1 1.1.1k-1+deb11u2 DSA-5103-1 unknown",
+		"lodash 4.17.20 GHSA-35jh-r3h4-6jhm high",
+		"requests 2.19.1 PYSEC-0000-1 medium",
+	}, got)
+
+	lodash := matches[1]
+	assert.Equal(t, []string{"4.17.21"}, lodash.FixedIn)
+	assert.Equal(t, 7.2, lodash.Vulnerability.Score)
+	assert.Equal(t, []string{"CVE-2021-23337"}, lodash.Vulnerability.Aliases)
+	assert.Equal(t, "https://osv.dev/vulnerability/GHSA-35jh-r3h4-6jhm", lodash.Vulnerability.URL())
+
+	assert.Equal(t, "Tavis Ormandy discovered that the BN_mod_sqrt() function could loop forever.", matches[0].Vulnerability.Summary)
+}
+
+func TestDatabase_Match_otherDistroRelease(t *testing.T) {
+	db, err := Open(writeDatabase(t))
+	require.NoError(t, err)
+
+	matches, err := db.Match(newSBOM(&linux.Release{ID: "debian", VersionID: "10"},
+		pkg.Package{Name: "openssl", Version: "1.1.1d-0+deb10u7", Type: pkg.DebPkg},
+	))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestOpen_invalid(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "file.json")
+	require.NoError(t, os.WriteFile(path, []byte(lodashRecord), 0600))
+	_, err = Open(path)
+	assert.Error(t, err)
+}
+
+func Test_inRange(t *testing.T) {
+	events := []osvEvent{{Introduced: "1.0.0"}, {Fixed: "1.2.0"}, {Introduced: "2.0.0"}, {LastAffected: "2.1.0"}}
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "0.9.0", want: false},
+		{version: "1.0.0", want: true},
+		{version: "1.1.9", want: true},
+		{version: "1.2.0", want: false},
+		{version: "2.0.0", want: true},
+		{version: "2.1.0", want: true},
+		{version: "2.1.1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, inRange(events, "semantic", tt.version))
+		})
+	}
+}
+
+func TestSeverity_AtLeast(t *testing.T) {
+	assert.True(t, CriticalSeverity.AtLeast(HighSeverity))
+	assert.True(t, HighSeverity.AtLeast(HighSeverity))
+	assert.False(t, MediumSeverity.AtLeast(HighSeverity))
+	assert.False(t, UnknownSeverity.AtLeast(LowSeverity))
+
+	s, err := ParseSeverity("HIGH")
+	require.NoError(t, err)
+	assert.Equal(t, HighSeverity, s)
+
+	_, err = ParseSeverity("severe")
+	assert.Error(t, err)
+}
@@ -41,6 +41,18 @@ func TestSBOMCmdFlags(t *testing.T) {
 				assertSuccessfulReturnCode,
 			},
 		},
+		{
+			name: "use-version-option-json",
+			args: []string{"sbom", "version", "--format", "json"},
+			assertions: []traitAssertion{
+				assertInOutput(`"syftVersion": "v0.46.3"`),
+				assertInOutput(`"stereoscopeVersion": "v`),
+				assertInOutput(`"dockerClientAPIVersion": "`),
+				assertInOutput(`"outputFormats": [`),
+				assertNotInOutput("not provided"),
+				assertSuccessfulReturnCode,
+			},
+		},
 		{
 			name: "use-short-version-option",
 			args: []string{"sbom", "--version"},
@@ -57,10 +69,32 @@ func TestSBOMCmdFlags(t *testing.T) {
 			assertions: []traitAssertion{
 				assertJsonReport,
 				assertJsonDescriptor(internal.SyftName, "v0.46.3"),
+				assertInOutput(`"provenance": {`),
+				assertInOutput(`"imageID": "sha256:`),
 				assertNotInOutput("not provided"),
 				assertSuccessfulReturnCode,
 			},
 		},
+		{
+			name: "base-image-detection",
+			args: []string{"sbom", "--base", "auto", coverageImage},
+			assertions: []traitAssertion{
+				assertTableReport,
+				assertInOutput("ORIGIN"),
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "cyclonedx-json-provenance-and-image-config",
+			args: []string{"sbom", "--format", "cyclonedx-json", coverageImage},
+			assertions: []traitAssertion{
+				assertInOutput(`"name": "docker-sbom"`),
+				assertInOutput(`"name": "docker-engine"`),
+				assertInOutput(`"name": "docker:sbom:provenance:image-id"`),
+				assertInOutput(`"name": "docker:image:env"`),
+				assertSuccessfulReturnCode,
+			},
+		},
 		{
 			name: "table-format-flag",
 			args: []string{"sbom", "--format", "table", coverageImage},
@@ -125,6 +159,120 @@ func TestSBOMCmdFlags(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "plain-progress-flag",
+			args: []string{"sbom", "--format", "json", "--progress", "plain", coverageImage},
+			assertions: []traitAssertion{
+				assertJsonReport,
+				assertInOutput("cataloging packages:"),
+				assertInOutput("] done"),
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "json-progress-flag",
+			args: []string{"sbom", "--format", "json", "--progress", "json", coverageImage},
+			assertions: []traitAssertion{
+				assertJsonReport,
+				assertInOutput(`"type":"catalog-packages"`),
+				assertInOutput(`"type":"result"`),
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "interactive-requires-terminal",
+			args: []string{"sbom", "--interactive", coverageImage},
+			assertions: []traitAssertion{
+				assertInOutput("--interactive requires a terminal on stdin and stdout"),
+				assertReturnCode(2),
+			},
+		},
+		{
+			name: "invalid-reference",
+			args: []string{"sbom", "Not/A/Reference:latest"},
+			assertions: []traitAssertion{
+				assertInOutput("unable to parse image reference"),
+				assertReturnCode(6),
+			},
+		},
+		{
+			name: "json-error-format",
+			args: []string{"sbom", "--error-format", "json", "Not/A/Reference:latest"},
+			assertions: []traitAssertion{
+				assertInOutput(`"code":"invalid-reference"`),
+				assertInOutput(`"image":"Not/A/Reference:latest"`),
+				assertInOutput(`"stage":"validate"`),
+				assertReturnCode(6),
+			},
+		},
+		{
+			name: "bad-library-log-level",
+			args: []string{"sbom", "--log-library-level", "docker=debug", "alpine:latest"},
+			assertions: []traitAssertion{
+				assertInOutput("bad library log level"),
+				assertReturnCode(2),
+			},
+		},
+		{
+			name: "all-local-rejects-single-image-options",
+			args: []string{"sbom", "--all-local", "--package-policy", "policy.yaml", "--fail-on-severity", "high"},
+			assertions: []traitAssertion{
+				assertInOutput("--package-policy, --fail-on-severity cannot be used with --all-local"),
+				assertReturnCode(2),
+			},
+		},
+		{
+			name: "dockerfile-stages",
+			args: []string{"sbom", "dockerfile", "--build-arg", "ALPINE_VERSION=3.16", "test-fixtures/dockerfile/Dockerfile"},
+			assertions: []traitAssertion{
+				assertInOutput("# stage 0 (base): alpine:3.16"),
+				assertInOutput("# stage 1 (app): base (skipped: based on stage 0)"),
+				assertInOutput("# stage 2: scratch (skipped: no base image)"),
+				assertInOutput("musl"),
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "dockerfile-stages-json",
+			args: []string{"sbom", "dockerfile", "--format", "syft-json", "test-fixtures/dockerfile/Dockerfile"},
+			assertions: []traitAssertion{
+				// the stages are described on stderr, leaving a single document on stdout
+				assertInOutput("# stage 0 (base): alpine:3.16"),
+				assertJsonReport,
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "dockerfile-missing",
+			args: []string{"sbom", "dockerfile", "test-fixtures/dockerfile/Missing.Dockerfile"},
+			assertions: []traitAssertion{
+				assertInOutput("unable to open Dockerfile"),
+				assertReturnCode(2),
+			},
+		},
+		{
+			name: "merge-undecodable-input",
+			args: []string{"sbom", "merge", "test-fixtures/dockerfile/Dockerfile", "test-fixtures/dockerfile/Dockerfile"},
+			assertions: []traitAssertion{
+				assertInOutput("unable to decode SBOM"),
+				assertReturnCode(2),
+			},
+		},
+		{
+			name: "merge-missing-input",
+			args: []string{"sbom", "merge", "test-fixtures/missing.spdx.json", "test-fixtures/missing.cdx.json"},
+			assertions: []traitAssertion{
+				assertInOutput("unable to read SBOM"),
+				assertReturnCode(2),
+			},
+		},
+		{
+			name: "query-bad-version-constraint",
+			args: []string{"sbom", "query", "--package", "musl", "--version", "~> 1.0 2.0", "test-fixtures/dockerfile"},
+			assertions: []traitAssertion{
+				assertReturnCode(2),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -141,3 +289,101 @@ func TestSBOMCmdFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestSBOMCmdAttest(t *testing.T) {
+	coverageImage := getFixtureImage(t, "image-pkg-coverage")
+
+	cleanup := setupPKI(t, "test")
+	defer cleanup()
+
+	attestation := filepath.Join(t.TempDir(), "attestation.json")
+
+	tests := []struct {
+		name       string
+		args       []string
+		assertions []traitAssertion
+	}{
+		{
+			name: "attest-spdx-json",
+			args: []string{"sbom", "--format", "spdx-json", "--attest", "--key", "cosign.key", coverageImage},
+			assertions: []traitAssertion{
+				assertJsonReport,
+				assertInOutput(`"payloadType":"application/vnd.in-toto+json"`),
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "attest-to-file",
+			args: []string{"sbom", "--format", "spdx-json", "--attest", "--key", "cosign.key", "-o", attestation, coverageImage},
+			assertions: []traitAssertion{
+				assertFileExists(attestation),
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "verify-attestation",
+			args: []string{"sbom", "verify", "--key", "cosign.pub", attestation, coverageImage},
+			assertions: []traitAssertion{
+				assertJsonReport,
+				assertInOutput(`"spdxVersion"`),
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "verify-attestation-as-table",
+			args: []string{"sbom", "verify", "--key", "cosign.pub", "--format", "table", attestation},
+			assertions: []traitAssertion{
+				assertTableReport,
+				assertSuccessfulReturnCode,
+			},
+		},
+		{
+			name: "verify-attestation-subject-mismatch",
+			args: []string{"sbom", "verify", "--key", "cosign.pub", attestation, getFixtureImage(t, "image-hidden-packages")},
+			assertions: []traitAssertion{
+				assertInOutput("the attestation subject does not match the image"),
+				assertReturnCode(11),
+			},
+		},
+		{
+			name: "verify-bad-format",
+			args: []string{"sbom", "verify", "--key", "cosign.pub", "--format", "tabel", attestation, coverageImage},
+			assertions: []traitAssertion{
+				assertInOutput("bad output format: 'tabel'"),
+				// the format is checked before the attestation is verified
+				assertNotInOutput("Verified attestation"),
+				assertReturnCode(2),
+			},
+		},
+		{
+			name: "attest-requires-json-format",
+			args: []string{"sbom", "--format", "table", "--attest", "--key", "cosign.key", coverageImage},
+			assertions: []traitAssertion{
+				assertInOutput("cannot be attested"),
+				assertFailingReturnCode,
+			},
+		},
+		{
+			name: "key-requires-attest",
+			args: []string{"sbom", "--key", "cosign.key", coverageImage},
+			assertions: []traitAssertion{
+				assertInOutput("can only be used to sign attestations"),
+				assertFailingReturnCode,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, stdout, stderr := runSyft(t, nil, tt.args...)
+			for _, traitFn := range tt.assertions {
+				traitFn(t, stdout, stderr, cmd.ProcessState.ExitCode())
+			}
+			if t.Failed() {
+				t.Log("STDOUT:\n", stdout)
+				t.Log("STDERR:\n", stderr)
+				t.Log("COMMAND:", strings.Join(cmd.Args, " "))
+			}
+		})
+	}
+}
@@ -148,6 +148,15 @@ func assertFailingReturnCode(tb testing.TB, _, _ string, rc int) {
 	}
 }
 
+func assertReturnCode(expected int) traitAssertion {
+	return func(tb testing.TB, _, _ string, rc int) {
+		tb.Helper()
+		if rc != expected {
+			tb.Errorf("expected rc=%d but got rc=%d", expected, rc)
+		}
+	}
+}
+
 func assertSuccessfulReturnCode(tb testing.TB, _, _ string, rc int) {
 	tb.Helper()
 	if rc != 0 {